@@ -4,6 +4,7 @@ import (
 	"errors"
 	"log"
 
+	"github.com/tokentransfer/chain/account"
 	"github.com/tokentransfer/chain/core"
 	"github.com/tokentransfer/chain/core/pb"
 
@@ -20,6 +21,20 @@ type Block struct {
 	StateHash       libcore.Hash
 	Timestamp       int64
 
+	// LogsBloom is the bloom filter of the topics of the logs of the
+	// transactions, see CreateLogsBloom, it is empty without logs.
+	LogsBloom libcore.Bytes
+
+	// BaseFee is the fee per gas of the block and GasUsed the gas of its
+	// transactions, see FeeMarket.
+	BaseFee int64
+	GasUsed int64
+
+	// PublicKey and Signature are of the validator which proposed the
+	// block, the signature covers Raw(true), see consensus.PoA.
+	PublicKey libcore.PublicKey
+	Signature libcore.Signature
+
 	Transactions []libblock.TransactionWithData
 	States       []libblock.State
 }
@@ -51,6 +66,11 @@ func (b *Block) UnmarshalBinary(data []byte) error {
 	b.TransactionHash = libcore.Hash(block.TransactionHash)
 	b.StateHash = libcore.Hash(block.StateHash)
 	b.Timestamp = block.Timestamp
+	b.LogsBloom = libcore.Bytes(block.LogsBloom)
+	b.BaseFee = block.BaseFee
+	b.GasUsed = block.GasUsed
+	b.PublicKey = libcore.PublicKey(block.PublicKey)
+	b.Signature = libcore.Signature(block.Signature)
 
 	l := len(block.Transactions)
 	transactions := make([]libblock.TransactionWithData, l)
@@ -94,6 +114,11 @@ func (b *Block) MarshalBinary() ([]byte, error) {
 		TransactionHash: []byte(b.TransactionHash),
 		StateHash:       []byte(b.StateHash),
 		Timestamp:       b.Timestamp,
+		LogsBloom:       []byte(b.LogsBloom),
+		BaseFee:         b.BaseFee,
+		GasUsed:         b.GasUsed,
+		PublicKey:       []byte(b.PublicKey),
+		Signature:       []byte(b.Signature),
 	}
 
 	l := len(b.Transactions)
@@ -130,6 +155,13 @@ func (b *Block) Raw(ignoreSigningFields bool) ([]byte, error) {
 		TransactionHash: []byte(b.TransactionHash),
 		StateHash:       []byte(b.StateHash),
 		Timestamp:       b.Timestamp,
+		LogsBloom:       []byte(b.LogsBloom),
+		BaseFee:         b.BaseFee,
+		GasUsed:         b.GasUsed,
+		PublicKey:       []byte(b.PublicKey),
+	}
+	if !ignoreSigningFields {
+		block.Signature = []byte(b.Signature)
 	}
 
 	l := len(b.Transactions)
@@ -159,6 +191,40 @@ func (b *Block) Raw(ignoreSigningFields bool) ([]byte, error) {
 	return core.Marshal(block)
 }
 
+// GetAccount returns the address of the proposer, nil when the block is not
+// signed.
+func (b *Block) GetAccount() libcore.Address {
+	if len(b.PublicKey) == 0 {
+		return nil
+	}
+	p := account.NewPublicKey()
+	err := p.UnmarshalBinary([]byte(b.PublicKey))
+	if err != nil {
+		return nil
+	}
+	a, err := p.GenerateAddress()
+	if err != nil {
+		return nil
+	}
+	return a
+}
+
+func (b *Block) GetPublicKey() libcore.PublicKey {
+	return b.PublicKey
+}
+
+func (b *Block) SetPublicKey(p libcore.PublicKey) {
+	b.PublicKey = p
+}
+
+func (b *Block) GetSignature() libcore.Signature {
+	return b.Signature
+}
+
+func (b *Block) SetSignature(s libcore.Signature) {
+	b.Signature = s
+}
+
 func (b *Block) GetParentHash() libcore.Hash {
 	return libcore.Hash(b.ParentHash)
 }
@@ -180,6 +246,16 @@ func (b *Block) GetTransactions() []libblock.TransactionWithData {
 	return ret
 }
 
+// MayHaveTopic returns false when no log of the block has topic.
+func (b *Block) MayHaveTopic(topic string) bool {
+	if len(b.LogsBloom) != BloomSize {
+		return false
+	}
+	var bloom Bloom
+	copy(bloom[:], b.LogsBloom)
+	return bloom.Test(topic)
+}
+
 func (b *Block) GetStates() []libblock.State {
 	l := len(b.States)
 	ret := make([]libblock.State, l)
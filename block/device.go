@@ -0,0 +1,772 @@
+package block
+
+import (
+	"errors"
+	"log"
+
+	"github.com/tokentransfer/chain/core"
+	"github.com/tokentransfer/chain/core/pb"
+
+	libblock "github.com/tokentransfer/interfaces/block"
+	libcore "github.com/tokentransfer/interfaces/core"
+)
+
+// The device transactions change a device created by NewDevice, only its
+// owner can send them and each produces a new version of its DeviceState,
+// a retired device can not be changed anymore. Apply fails with a
+// *ResultError, on success it appends the event of the transaction to the
+// logs of the receipt.
+
+func nextDeviceState(s *DeviceState, account libcore.Address, symbol string, blockIndex uint64) (*DeviceState, error) {
+	if s == nil || s.Symbol != symbol {
+		return nil, &ResultError{Result: ResultUnknownDevice, Message: symbol}
+	}
+	if s.Retired {
+		return nil, &ResultError{Result: ResultDeviceRetired, Message: symbol}
+	}
+	owner, err := s.Account.GetAddress()
+	if err != nil {
+		return nil, err
+	}
+	sender, err := account.GetAddress()
+	if err != nil {
+		return nil, err
+	}
+	if owner != sender {
+		return nil, &ResultError{Result: ResultDeviceNotOwned, Message: symbol}
+	}
+
+	next := &DeviceState{
+		State: State{
+			BlockIndex: blockIndex,
+			StateType:  libblock.StateType(core.CORE_DEVICE_STATE),
+		},
+		Account:     s.Account,
+		Sequence:    s.Sequence + 1,
+		Symbol:      s.Symbol,
+		Description: s.Description,
+		Tags:        s.Tags,
+	}
+	return next, nil
+}
+
+//region UpdateDevice
+
+type UpdateDevice struct {
+	Transaction
+
+	Symbol      string
+	Description string
+	DeviceTags  []string
+}
+
+func (tx *UpdateDevice) UnmarshalBinary(data []byte) error {
+	var err error
+
+	meta, msg, err := core.Unmarshal(data)
+	if err != nil {
+		return err
+	}
+	if meta != core.CORE_UPDATEDEVICE {
+		return errors.New("error transaction update device data")
+	}
+	t := msg.(*pb.UpdateDevice)
+
+	tx.TransactionType = libblock.TransactionType(t.TransactionType)
+
+	tx.Account, err = byteToAddress(t.Account)
+	if err != nil {
+		return err
+	}
+
+	tx.Sequence = t.Sequence
+	tx.Amount = t.Amount
+	tx.Gas = t.Gas
+	tx.Type = t.Type
+	tx.ChainID = t.ChainID
+	tx.ValidAfter = t.ValidAfter
+	tx.ValidUntil = t.ValidUntil
+	tx.Symbol = t.Symbol
+	tx.Description = t.Description
+	tx.DeviceTags = t.DeviceTags
+
+	tx.Destination, err = byteToAddress(t.Destination)
+	if err != nil {
+		return err
+	}
+
+	tx.Payload = t.Payload
+	tx.PublicKey = libcore.PublicKey(t.PublicKey)
+	tx.Signature = libcore.Signature(t.Signature)
+
+	return nil
+}
+
+func (tx *UpdateDevice) MarshalBinary() ([]byte, error) {
+	fromData, err := addressToByte(tx.Account)
+	if err != nil {
+		return nil, err
+	}
+	toData, err := addressToByte(tx.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &pb.UpdateDevice{
+		TransactionType: uint32(tx.TransactionType),
+
+		Account:     fromData,
+		Sequence:    tx.Sequence,
+		Amount:      tx.Amount,
+		Gas:         tx.Gas,
+		Type:        tx.Type,
+		ChainID:     tx.ChainID,
+		ValidAfter:  tx.ValidAfter,
+		ValidUntil:  tx.ValidUntil,
+		Symbol:      tx.Symbol,
+		Description: tx.Description,
+		DeviceTags:  tx.DeviceTags,
+		Destination: toData,
+		Payload:     tx.Payload,
+		PublicKey:   []byte(tx.PublicKey),
+		Signature:   []byte(tx.Signature),
+	}
+	return core.Marshal(t)
+}
+
+func (tx *UpdateDevice) Raw(ignoreSigningFields bool) ([]byte, error) {
+	fromData, err := addressToByte(tx.Account)
+	if err != nil {
+		return nil, err
+	}
+	toData, err := addressToByte(tx.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	if ignoreSigningFields {
+		t := &pb.UpdateDevice{
+			TransactionType: uint32(tx.TransactionType),
+
+			Account:     fromData,
+			Sequence:    tx.Sequence,
+			Amount:      tx.Amount,
+			Gas:         tx.Gas,
+			Type:        tx.Type,
+			ChainID:     tx.ChainID,
+			ValidAfter:  tx.ValidAfter,
+			ValidUntil:  tx.ValidUntil,
+			Symbol:      tx.Symbol,
+			Description: tx.Description,
+			DeviceTags:  tx.DeviceTags,
+			Destination: toData,
+			Payload:     tx.Payload,
+			PublicKey:   []byte(tx.PublicKey),
+		}
+		return core.Marshal(t)
+	}
+	return tx.MarshalBinary()
+}
+
+// Apply returns the version of the device state s after tx.
+func (tx *UpdateDevice) Apply(s *DeviceState, blockIndex uint64, r *Receipt) (*DeviceState, error) {
+	next, err := nextDeviceState(s, tx.Account, tx.Symbol, blockIndex)
+	if err != nil {
+		return nil, err
+	}
+	next.Description = tx.Description
+	next.Tags = tx.DeviceTags
+	l, err := newLog(EventDeviceUpdated, nil, []string{tx.Symbol}, tx.Account)
+	if err != nil {
+		return nil, err
+	}
+	r.Logs = append(r.Logs, l)
+	return next, nil
+}
+
+//endregion
+
+//region TransferDevice
+
+type TransferDevice struct {
+	Transaction
+
+	Symbol string
+}
+
+func (tx *TransferDevice) UnmarshalBinary(data []byte) error {
+	var err error
+
+	meta, msg, err := core.Unmarshal(data)
+	if err != nil {
+		return err
+	}
+	if meta != core.CORE_TRANSFERDEVICE {
+		return errors.New("error transaction transfer device data")
+	}
+	t := msg.(*pb.TransferDevice)
+
+	tx.TransactionType = libblock.TransactionType(t.TransactionType)
+
+	tx.Account, err = byteToAddress(t.Account)
+	if err != nil {
+		return err
+	}
+
+	tx.Sequence = t.Sequence
+	tx.Amount = t.Amount
+	tx.Gas = t.Gas
+	tx.Type = t.Type
+	tx.ChainID = t.ChainID
+	tx.ValidAfter = t.ValidAfter
+	tx.ValidUntil = t.ValidUntil
+	tx.Symbol = t.Symbol
+
+	tx.Destination, err = byteToAddress(t.Destination)
+	if err != nil {
+		return err
+	}
+
+	tx.Payload = t.Payload
+	tx.PublicKey = libcore.PublicKey(t.PublicKey)
+	tx.Signature = libcore.Signature(t.Signature)
+
+	return nil
+}
+
+func (tx *TransferDevice) MarshalBinary() ([]byte, error) {
+	fromData, err := addressToByte(tx.Account)
+	if err != nil {
+		return nil, err
+	}
+	toData, err := addressToByte(tx.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &pb.TransferDevice{
+		TransactionType: uint32(tx.TransactionType),
+
+		Account:     fromData,
+		Sequence:    tx.Sequence,
+		Amount:      tx.Amount,
+		Gas:         tx.Gas,
+		Type:        tx.Type,
+		ChainID:     tx.ChainID,
+		ValidAfter:  tx.ValidAfter,
+		ValidUntil:  tx.ValidUntil,
+		Symbol:      tx.Symbol,
+		Destination: toData,
+		Payload:     tx.Payload,
+		PublicKey:   []byte(tx.PublicKey),
+		Signature:   []byte(tx.Signature),
+	}
+	return core.Marshal(t)
+}
+
+func (tx *TransferDevice) Raw(ignoreSigningFields bool) ([]byte, error) {
+	fromData, err := addressToByte(tx.Account)
+	if err != nil {
+		return nil, err
+	}
+	toData, err := addressToByte(tx.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	if ignoreSigningFields {
+		t := &pb.TransferDevice{
+			TransactionType: uint32(tx.TransactionType),
+
+			Account:     fromData,
+			Sequence:    tx.Sequence,
+			Amount:      tx.Amount,
+			Gas:         tx.Gas,
+			Type:        tx.Type,
+			ChainID:     tx.ChainID,
+			ValidAfter:  tx.ValidAfter,
+			ValidUntil:  tx.ValidUntil,
+			Symbol:      tx.Symbol,
+			Destination: toData,
+			Payload:     tx.Payload,
+			PublicKey:   []byte(tx.PublicKey),
+		}
+		return core.Marshal(t)
+	}
+	return tx.MarshalBinary()
+}
+
+// Apply returns the version of the device state s after tx, the device is
+// owned by the Destination of tx.
+func (tx *TransferDevice) Apply(s *DeviceState, blockIndex uint64, r *Receipt) (*DeviceState, error) {
+	next, err := nextDeviceState(s, tx.Account, tx.Symbol, blockIndex)
+	if err != nil {
+		return nil, err
+	}
+	if tx.Destination == nil {
+		return nil, errors.New("error device destination")
+	}
+	next.Account = tx.Destination
+	l, err := newLog(EventDeviceTransferred, nil, []string{tx.Symbol}, tx.Account, tx.Destination)
+	if err != nil {
+		return nil, err
+	}
+	r.Logs = append(r.Logs, l)
+	return next, nil
+}
+
+//endregion
+
+//region RetireDevice
+
+type RetireDevice struct {
+	Transaction
+
+	Symbol string
+}
+
+func (tx *RetireDevice) UnmarshalBinary(data []byte) error {
+	var err error
+
+	meta, msg, err := core.Unmarshal(data)
+	if err != nil {
+		return err
+	}
+	if meta != core.CORE_RETIREDEVICE {
+		return errors.New("error transaction retire device data")
+	}
+	t := msg.(*pb.RetireDevice)
+
+	tx.TransactionType = libblock.TransactionType(t.TransactionType)
+
+	tx.Account, err = byteToAddress(t.Account)
+	if err != nil {
+		return err
+	}
+
+	tx.Sequence = t.Sequence
+	tx.Amount = t.Amount
+	tx.Gas = t.Gas
+	tx.Type = t.Type
+	tx.ChainID = t.ChainID
+	tx.ValidAfter = t.ValidAfter
+	tx.ValidUntil = t.ValidUntil
+	tx.Symbol = t.Symbol
+
+	tx.Destination, err = byteToAddress(t.Destination)
+	if err != nil {
+		return err
+	}
+
+	tx.Payload = t.Payload
+	tx.PublicKey = libcore.PublicKey(t.PublicKey)
+	tx.Signature = libcore.Signature(t.Signature)
+
+	return nil
+}
+
+func (tx *RetireDevice) MarshalBinary() ([]byte, error) {
+	fromData, err := addressToByte(tx.Account)
+	if err != nil {
+		return nil, err
+	}
+	toData, err := addressToByte(tx.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &pb.RetireDevice{
+		TransactionType: uint32(tx.TransactionType),
+
+		Account:     fromData,
+		Sequence:    tx.Sequence,
+		Amount:      tx.Amount,
+		Gas:         tx.Gas,
+		Type:        tx.Type,
+		ChainID:     tx.ChainID,
+		ValidAfter:  tx.ValidAfter,
+		ValidUntil:  tx.ValidUntil,
+		Symbol:      tx.Symbol,
+		Destination: toData,
+		Payload:     tx.Payload,
+		PublicKey:   []byte(tx.PublicKey),
+		Signature:   []byte(tx.Signature),
+	}
+	return core.Marshal(t)
+}
+
+func (tx *RetireDevice) Raw(ignoreSigningFields bool) ([]byte, error) {
+	fromData, err := addressToByte(tx.Account)
+	if err != nil {
+		return nil, err
+	}
+	toData, err := addressToByte(tx.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	if ignoreSigningFields {
+		t := &pb.RetireDevice{
+			TransactionType: uint32(tx.TransactionType),
+
+			Account:     fromData,
+			Sequence:    tx.Sequence,
+			Amount:      tx.Amount,
+			Gas:         tx.Gas,
+			Type:        tx.Type,
+			ChainID:     tx.ChainID,
+			ValidAfter:  tx.ValidAfter,
+			ValidUntil:  tx.ValidUntil,
+			Symbol:      tx.Symbol,
+			Destination: toData,
+			Payload:     tx.Payload,
+			PublicKey:   []byte(tx.PublicKey),
+		}
+		return core.Marshal(t)
+	}
+	return tx.MarshalBinary()
+}
+
+// Apply returns the version of the device state s after tx.
+func (tx *RetireDevice) Apply(s *DeviceState, blockIndex uint64, r *Receipt) (*DeviceState, error) {
+	next, err := nextDeviceState(s, tx.Account, tx.Symbol, blockIndex)
+	if err != nil {
+		return nil, err
+	}
+	next.Retired = true
+	l, err := newLog(EventDeviceRetired, nil, []string{tx.Symbol}, tx.Account)
+	if err != nil {
+		return nil, err
+	}
+	r.Logs = append(r.Logs, l)
+	return next, nil
+}
+
+//endregion
+
+//region UpdateDeviceWithData
+
+type UpdateDeviceWithData struct {
+	TransactionWithData
+
+	Transaction libblock.Transaction
+	Receipt     libblock.Receipt
+}
+
+func (txWithData *UpdateDeviceWithData) GetHash() libcore.Hash {
+	return txWithData.Hash
+}
+
+func (txWithData *UpdateDeviceWithData) SetHash(h libcore.Hash) {
+	txWithData.Hash = h
+}
+
+func (txWithData *UpdateDeviceWithData) GetTransaction() libblock.Transaction {
+	return txWithData.Transaction
+}
+
+func (txWithData *UpdateDeviceWithData) GetReceipt() libblock.Receipt {
+	return txWithData.Receipt
+}
+
+func (txWithData *UpdateDeviceWithData) UnmarshalBinary(data []byte) error {
+	meta, msg, err := core.Unmarshal(data)
+	if err != nil {
+		return err
+	}
+	if meta != core.CORE_UPDATEDEVICE_WITH_DATA {
+		return errors.New("error update device with data")
+	}
+
+	td := msg.(*pb.UpdateDeviceWithData)
+
+	txData, err := core.Marshal(td.Transaction)
+	if err != nil {
+		return err
+	}
+	tx := &UpdateDevice{}
+	err = tx.UnmarshalBinary(txData)
+	if err != nil {
+		return err
+	}
+	txWithData.Transaction = tx
+
+	receiptData, err := core.Marshal(td.Receipt)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	receipt := &Receipt{}
+	err = receipt.UnmarshalBinary(receiptData)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	txWithData.Receipt = receipt
+	return nil
+}
+
+func (txWithData *UpdateDeviceWithData) MarshalBinary() ([]byte, error) {
+	return txWithData.marshal(txWithData.Receipt.MarshalBinary, txWithData.Transaction.MarshalBinary)
+}
+
+func (txWithData *UpdateDeviceWithData) Raw(ignoreSigningFields bool) ([]byte, error) {
+	receiptRaw := func() ([]byte, error) {
+		return txWithData.Receipt.Raw(ignoreSigningFields)
+	}
+	txRaw := func() ([]byte, error) {
+		return txWithData.Transaction.Raw(ignoreSigningFields)
+	}
+	return txWithData.marshal(receiptRaw, txRaw)
+}
+
+func (txWithData *UpdateDeviceWithData) marshal(receiptData func() ([]byte, error), txData func() ([]byte, error)) ([]byte, error) {
+	data, err := receiptData()
+	if err != nil {
+		return nil, err
+	}
+	_, msg, err := core.Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	receipt := msg.(*pb.Receipt)
+
+	data, err = txData()
+	if err != nil {
+		return nil, err
+	}
+	_, msg, err = core.Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	tx, ok := msg.(*pb.UpdateDevice)
+	if !ok {
+		return nil, errors.New("error update device data")
+	}
+
+	return core.Marshal(&pb.UpdateDeviceWithData{
+		Transaction: tx,
+		Receipt:     receipt,
+	})
+}
+
+//endregion
+
+//region TransferDeviceWithData
+
+type TransferDeviceWithData struct {
+	TransactionWithData
+
+	Transaction libblock.Transaction
+	Receipt     libblock.Receipt
+}
+
+func (txWithData *TransferDeviceWithData) GetHash() libcore.Hash {
+	return txWithData.Hash
+}
+
+func (txWithData *TransferDeviceWithData) SetHash(h libcore.Hash) {
+	txWithData.Hash = h
+}
+
+func (txWithData *TransferDeviceWithData) GetTransaction() libblock.Transaction {
+	return txWithData.Transaction
+}
+
+func (txWithData *TransferDeviceWithData) GetReceipt() libblock.Receipt {
+	return txWithData.Receipt
+}
+
+func (txWithData *TransferDeviceWithData) UnmarshalBinary(data []byte) error {
+	meta, msg, err := core.Unmarshal(data)
+	if err != nil {
+		return err
+	}
+	if meta != core.CORE_TRANSFERDEVICE_WITH_DATA {
+		return errors.New("error transfer device with data")
+	}
+
+	td := msg.(*pb.TransferDeviceWithData)
+
+	txData, err := core.Marshal(td.Transaction)
+	if err != nil {
+		return err
+	}
+	tx := &TransferDevice{}
+	err = tx.UnmarshalBinary(txData)
+	if err != nil {
+		return err
+	}
+	txWithData.Transaction = tx
+
+	receiptData, err := core.Marshal(td.Receipt)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	receipt := &Receipt{}
+	err = receipt.UnmarshalBinary(receiptData)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	txWithData.Receipt = receipt
+	return nil
+}
+
+func (txWithData *TransferDeviceWithData) MarshalBinary() ([]byte, error) {
+	return txWithData.marshal(txWithData.Receipt.MarshalBinary, txWithData.Transaction.MarshalBinary)
+}
+
+func (txWithData *TransferDeviceWithData) Raw(ignoreSigningFields bool) ([]byte, error) {
+	receiptRaw := func() ([]byte, error) {
+		return txWithData.Receipt.Raw(ignoreSigningFields)
+	}
+	txRaw := func() ([]byte, error) {
+		return txWithData.Transaction.Raw(ignoreSigningFields)
+	}
+	return txWithData.marshal(receiptRaw, txRaw)
+}
+
+func (txWithData *TransferDeviceWithData) marshal(receiptData func() ([]byte, error), txData func() ([]byte, error)) ([]byte, error) {
+	data, err := receiptData()
+	if err != nil {
+		return nil, err
+	}
+	_, msg, err := core.Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	receipt := msg.(*pb.Receipt)
+
+	data, err = txData()
+	if err != nil {
+		return nil, err
+	}
+	_, msg, err = core.Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	tx, ok := msg.(*pb.TransferDevice)
+	if !ok {
+		return nil, errors.New("error transfer device data")
+	}
+
+	return core.Marshal(&pb.TransferDeviceWithData{
+		Transaction: tx,
+		Receipt:     receipt,
+	})
+}
+
+//endregion
+
+//region RetireDeviceWithData
+
+type RetireDeviceWithData struct {
+	TransactionWithData
+
+	Transaction libblock.Transaction
+	Receipt     libblock.Receipt
+}
+
+func (txWithData *RetireDeviceWithData) GetHash() libcore.Hash {
+	return txWithData.Hash
+}
+
+func (txWithData *RetireDeviceWithData) SetHash(h libcore.Hash) {
+	txWithData.Hash = h
+}
+
+func (txWithData *RetireDeviceWithData) GetTransaction() libblock.Transaction {
+	return txWithData.Transaction
+}
+
+func (txWithData *RetireDeviceWithData) GetReceipt() libblock.Receipt {
+	return txWithData.Receipt
+}
+
+func (txWithData *RetireDeviceWithData) UnmarshalBinary(data []byte) error {
+	meta, msg, err := core.Unmarshal(data)
+	if err != nil {
+		return err
+	}
+	if meta != core.CORE_RETIREDEVICE_WITH_DATA {
+		return errors.New("error retire device with data")
+	}
+
+	td := msg.(*pb.RetireDeviceWithData)
+
+	txData, err := core.Marshal(td.Transaction)
+	if err != nil {
+		return err
+	}
+	tx := &RetireDevice{}
+	err = tx.UnmarshalBinary(txData)
+	if err != nil {
+		return err
+	}
+	txWithData.Transaction = tx
+
+	receiptData, err := core.Marshal(td.Receipt)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	receipt := &Receipt{}
+	err = receipt.UnmarshalBinary(receiptData)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	txWithData.Receipt = receipt
+	return nil
+}
+
+func (txWithData *RetireDeviceWithData) MarshalBinary() ([]byte, error) {
+	return txWithData.marshal(txWithData.Receipt.MarshalBinary, txWithData.Transaction.MarshalBinary)
+}
+
+func (txWithData *RetireDeviceWithData) Raw(ignoreSigningFields bool) ([]byte, error) {
+	receiptRaw := func() ([]byte, error) {
+		return txWithData.Receipt.Raw(ignoreSigningFields)
+	}
+	txRaw := func() ([]byte, error) {
+		return txWithData.Transaction.Raw(ignoreSigningFields)
+	}
+	return txWithData.marshal(receiptRaw, txRaw)
+}
+
+func (txWithData *RetireDeviceWithData) marshal(receiptData func() ([]byte, error), txData func() ([]byte, error)) ([]byte, error) {
+	data, err := receiptData()
+	if err != nil {
+		return nil, err
+	}
+	_, msg, err := core.Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	receipt := msg.(*pb.Receipt)
+
+	data, err = txData()
+	if err != nil {
+		return nil, err
+	}
+	_, msg, err = core.Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	tx, ok := msg.(*pb.RetireDevice)
+	if !ok {
+		return nil, errors.New("error retire device data")
+	}
+
+	return core.Marshal(&pb.RetireDeviceWithData{
+		Transaction: tx,
+		Receipt:     receipt,
+	})
+}
+
+//endregion
@@ -0,0 +1,118 @@
+package block
+
+import (
+	"testing"
+
+	"github.com/tokentransfer/chain/account"
+
+	. "github.com/tokentransfer/check"
+	libcore "github.com/tokentransfer/interfaces/core"
+)
+
+type DeviceSuite struct{}
+
+func Test_Device(t *testing.T) {
+	s := Suite(&DeviceSuite{})
+	TestingRun(t, s)
+}
+
+func newTestAddress(c *C, s string) libcore.Address {
+	a := account.NewAddress()
+	err := a.UnmarshalText([]byte(s))
+	c.Assert(err, IsNil)
+	return a
+}
+
+func (suite *DeviceSuite) TestLifecycle(c *C) {
+	owner := newTestAddress(c, "jngGY9W1F2Ky8wCzeHTahbtxjadU9wNFRz")
+	other := newTestAddress(c, "0x42f32B004Da1093d51AE40a58F38E33BA4f46397")
+
+	s := &DeviceState{
+		State: State{
+			BlockIndex: uint64(1),
+		},
+		Account:     owner,
+		Sequence:    uint64(1),
+		Symbol:      "sensor",
+		Description: "a sensor",
+		Tags:        []string{"temperature"},
+	}
+
+	update := &UpdateDevice{
+		Transaction: Transaction{Account: owner, Destination: owner},
+		Symbol:      "sensor",
+		Description: "an updated sensor",
+		DeviceTags:  []string{"humidity"},
+	}
+	r := &Receipt{}
+	s2, err := update.Apply(s, uint64(2), r)
+	c.Assert(err, IsNil)
+	address, err := owner.GetAddress()
+	c.Assert(err, IsNil)
+	c.Assert(r.Logs, DeepEquals, []*Log{{Topics: []string{EventDeviceUpdated, "sensor", address}}})
+	c.Assert(s2.Sequence, Equals, uint64(2))
+	c.Assert(s2.BlockIndex, Equals, uint64(2))
+	c.Assert(s2.Description, Equals, "an updated sensor")
+	c.Assert(s2.Tags, DeepEquals, []string{"humidity"})
+
+	transfer := &TransferDevice{
+		Transaction: Transaction{Account: other, Destination: owner},
+		Symbol:      "sensor",
+	}
+	_, err = transfer.Apply(s2, uint64(3), r)
+	c.Assert(err, NotNil)
+	c.Assert(len(r.Logs), Equals, 1)
+	c.Assert(err.(*ResultError).Result, Equals, ResultDeviceNotOwned)
+
+	transfer = &TransferDevice{
+		Transaction: Transaction{Account: owner, Destination: other},
+		Symbol:      "sensor",
+	}
+	s3, err := transfer.Apply(s2, uint64(3), r)
+	c.Assert(err, IsNil)
+	c.Assert(s3.Account, Equals, other)
+
+	retire := &RetireDevice{
+		Transaction: Transaction{Account: other, Destination: other},
+		Symbol:      "sensor",
+	}
+	s4, err := retire.Apply(s3, uint64(4), r)
+	c.Assert(err, IsNil)
+	c.Assert(s4.Retired, Equals, true)
+	c.Assert(len(r.Logs), Equals, 3)
+	c.Assert(r.Logs[1].Topics[0], Equals, EventDeviceTransferred)
+	c.Assert(r.Logs[2].Topics[0], Equals, EventDeviceRetired)
+
+	data, err := s4.MarshalBinary()
+	c.Assert(err, IsNil)
+	state, err := ReadState(data)
+	c.Assert(err, IsNil)
+	c.Assert(state.(*DeviceState).Retired, Equals, true)
+
+	_, err = update.Apply(s4, uint64(5), r)
+	c.Assert(err, NotNil)
+	c.Assert(err.(*ResultError).Result, Equals, ResultDeviceRetired)
+}
+
+func (suite *DeviceSuite) TestWithData(c *C) {
+	owner := newTestAddress(c, "jngGY9W1F2Ky8wCzeHTahbtxjadU9wNFRz")
+
+	txWithData := &UpdateDeviceWithData{
+		Transaction: &UpdateDevice{
+			Transaction: Transaction{Account: owner, Destination: owner, Sequence: uint64(3)},
+			Symbol:      "sensor",
+			DeviceTags:  []string{"temperature"},
+		},
+		Receipt: &Receipt{},
+	}
+	data, err := txWithData.MarshalBinary()
+	c.Assert(err, IsNil)
+
+	tx, err := ReadTxWithData(data)
+	c.Assert(err, IsNil)
+	update, ok := tx.GetTransaction().(*UpdateDevice)
+	c.Assert(ok, Equals, true)
+	c.Assert(update.Symbol, Equals, "sensor")
+	c.Assert(update.Sequence, Equals, uint64(3))
+	c.Assert(update.DeviceTags, DeepEquals, []string{"temperature"})
+}
@@ -0,0 +1,367 @@
+package block
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tokentransfer/chain/core"
+
+	libblock "github.com/tokentransfer/interfaces/block"
+	libcore "github.com/tokentransfer/interfaces/core"
+)
+
+// Executor builds the blocks of a chain: it applies the transactions to the
+// latest states in order and records their receipts with their logs. Each
+// transaction must be valid in the block and have the next sequence of its
+// sender. A transaction which fails before its fee is charged, with a bad
+// sequence, out of its validity window, out of gas or unable to pay its fee,
+// is left out of the block. One which fails after is kept with the result of
+// its *ResultError, it only increases the sequence of its sender and pays
+// its fee. The validator transactions are checked against Validators, the
+// validators of the block, and only change the sequence of their sender, the
+// validators are changed by the consensus engine.
+//
+// With a GasSchedule, the gas of each transaction is checked against its
+// limit and counted in the GasUsed of the block. With a FeeMarket too, the
+// block has the base fee following its parent, the transactions over its
+// gas limit are left out, and the fee of each transaction is moved from its
+// sender to the collector of the FeeMarket, or to Producer when it has none,
+// the fee is burnt without both.
+type Executor struct {
+	// GetState returns the latest state of key, nil when there is none.
+	GetState func(key string) (libblock.State, error)
+
+	// Validators are the validators of the block, the validator transactions
+	// fail with ResultNotValidator without them.
+	Validators []libcore.PublicKey
+
+	GasSchedule *GasSchedule
+	FeeMarket   *FeeMarket
+	Producer    libcore.Address
+}
+
+// blockStates are the states built for a block, over the latest ones.
+type blockStates struct {
+	e         *Executor
+	index     uint64
+	timestamp int64
+	baseFee   int64
+	states    map[string]libblock.State
+	keys      []string
+}
+
+func (bs *blockStates) get(key string) (libblock.State, error) {
+	s, ok := bs.states[key]
+	if ok {
+		return s, nil
+	}
+	return bs.e.GetState(key)
+}
+
+// put records the states of a transaction, the last version of each key is
+// kept in the block.
+func (bs *blockStates) put(states []libblock.State) {
+	for _, s := range states {
+		key := s.GetStateKey()
+		_, ok := bs.states[key]
+		if !ok {
+			bs.keys = append(bs.keys, key)
+		}
+		bs.states[key] = s
+	}
+}
+
+// account returns a new version of the account state of a of key key, with
+// no amount when a has no state.
+func (bs *blockStates) account(a libcore.Address, key string) (*AccountState, error) {
+	s, err := bs.get(key)
+	if err != nil {
+		return nil, err
+	}
+	next := &AccountState{
+		State: State{
+			StateType: libblock.StateType(core.CORE_ACCOUNT_STATE),
+		},
+		Account: a,
+	}
+	if s != nil {
+		as, ok := s.(*AccountState)
+		if !ok {
+			return nil, fmt.Errorf("error account state %s", key)
+		}
+		*next = *as
+	}
+	next.BlockIndex = bs.index
+	return next, nil
+}
+
+func (bs *blockStates) device(symbol string) (*DeviceState, error) {
+	s, err := bs.get(symbol)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, nil
+	}
+	ds, ok := s.(*DeviceState)
+	if !ok {
+		return nil, fmt.Errorf("error device state %s", symbol)
+	}
+	return ds, nil
+}
+
+func (bs *blockStates) applyDevice(symbol string, apply func(s *DeviceState, blockIndex uint64, r *Receipt) (*DeviceState, error), r *Receipt) (libblock.State, error) {
+	s, err := bs.device(symbol)
+	if err != nil {
+		return nil, err
+	}
+	return apply(s, bs.index, r)
+}
+
+// txStates are the states changed by a transaction, an account has one
+// version per transaction.
+type txStates struct {
+	bs       *blockStates
+	accounts map[string]*AccountState
+	states   []libblock.State
+}
+
+func (ts *txStates) account(a libcore.Address) (*AccountState, error) {
+	if a == nil {
+		return nil, errors.New("error account")
+	}
+	key, err := a.GetAddress()
+	if err != nil {
+		return nil, err
+	}
+	s, ok := ts.accounts[key]
+	if ok {
+		return s, nil
+	}
+	s, err = ts.bs.account(a, key)
+	if err != nil {
+		return nil, err
+	}
+	ts.accounts[key] = s
+	ts.states = append(ts.states, s)
+	return s, nil
+}
+
+// snapshot returns copies of the account states of the transaction.
+func (ts *txStates) snapshot() []libblock.State {
+	l := len(ts.states)
+	states := make([]libblock.State, l)
+	for i := 0; i < l; i++ {
+		as := *ts.states[i].(*AccountState)
+		states[i] = &as
+	}
+	return states
+}
+
+// apply returns the states after tx, r gets its logs. The sequence of tx is
+// checked and its fee charged first, when it fails after them the states
+// with the new sequence and the fee are returned with the *ResultError.
+func (bs *blockStates) apply(tx libblock.Transaction, r *Receipt) ([]libblock.State, error) {
+	ts := &txStates{
+		bs:       bs,
+		accounts: map[string]*AccountState{},
+	}
+	sender, err := ts.account(tx.GetAccount())
+	if err != nil {
+		return nil, err
+	}
+	if tx.GetSequence() != sender.Sequence+1 {
+		return nil, &ResultError{Result: ResultBadSequence, Message: fmt.Sprintf("sequence %d, expected %d", tx.GetSequence(), sender.Sequence+1)}
+	}
+	sender.Sequence++
+	v, ok := tx.(interface {
+		CheckValidity(blockIndex uint64, timestamp int64) libblock.TransactionResult
+	})
+	if ok {
+		result := v.CheckValidity(bs.index, bs.timestamp)
+		if result != ResultSuccess {
+			return nil, &ResultError{Result: result}
+		}
+	}
+	err = bs.chargeFee(ts, tx, sender)
+	if err != nil {
+		return nil, err
+	}
+	charged := ts.snapshot()
+	states, err := bs.execute(ts, tx, sender, r)
+	if err != nil {
+		_, ok := err.(*ResultError)
+		if ok {
+			return charged, err
+		}
+		return nil, err
+	}
+	return states, nil
+}
+
+// execute returns the states after tx once its sender is charged.
+func (bs *blockStates) execute(ts *txStates, tx libblock.Transaction, sender *AccountState, r *Receipt) ([]libblock.State, error) {
+	var s libblock.State
+	var err error
+	switch t := tx.(type) {
+	case *Payment:
+		if t.Destination == nil {
+			return nil, &ResultError{Result: ResultMalformed, Message: "no destination"}
+		}
+		to, err := ts.account(t.Destination)
+		if err != nil {
+			return nil, err
+		}
+		err = t.Apply(sender, to, r)
+		if err != nil {
+			return nil, err
+		}
+		return ts.states, nil
+	case *NewDevice:
+		s, err = bs.applyDevice(t.Symbol, t.Apply, r)
+	case *UpdateDevice:
+		s, err = bs.applyDevice(t.Symbol, t.Apply, r)
+	case *TransferDevice:
+		s, err = bs.applyDevice(t.Symbol, t.Apply, r)
+	case *RetireDevice:
+		s, err = bs.applyDevice(t.Symbol, t.Apply, r)
+	case *AddValidator:
+		_, err = t.Apply(bs.e.Validators)
+		if err != nil {
+			return nil, err
+		}
+		return ts.states, nil
+	case *RemoveValidator:
+		_, err = t.Apply(bs.e.Validators)
+		if err != nil {
+			return nil, err
+		}
+		return ts.states, nil
+	default:
+		return nil, fmt.Errorf("error transaction type %d", tx.GetTransactionType())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(ts.states, s), nil
+}
+
+// chargeFee checks the gas of tx and moves its fee at the base fee of the
+// block from sender to the collector of the fees.
+func (bs *blockStates) chargeFee(ts *txStates, tx libblock.Transaction, sender *AccountState) error {
+	e := bs.e
+	if e.GasSchedule == nil {
+		return nil
+	}
+	gas, err := e.GasSchedule.CheckGas(tx)
+	if err != nil {
+		return err
+	}
+	if e.FeeMarket == nil {
+		return nil
+	}
+	var to *AccountState
+	collector := e.FeeMarket.GetCollector(e.Producer)
+	if collector != nil {
+		to, err = ts.account(collector)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = ChargeFee(sender, to, gas, bs.baseFee)
+	return err
+}
+
+// withData returns tx with its receipt r.
+func withData(tx libblock.Transaction, r *Receipt) libblock.TransactionWithData {
+	switch tx.(type) {
+	case *Payment:
+		return &PaymentWithData{Transaction: tx, Receipt: r}
+	case *NewDevice:
+		return &NewDeviceWithData{Transaction: tx, Receipt: r}
+	case *UpdateDevice:
+		return &UpdateDeviceWithData{Transaction: tx, Receipt: r}
+	case *TransferDevice:
+		return &TransferDeviceWithData{Transaction: tx, Receipt: r}
+	case *RetireDevice:
+		return &RetireDeviceWithData{Transaction: tx, Receipt: r}
+	case *AddValidator:
+		return &AddValidatorWithData{Transaction: tx, Receipt: r}
+	case *RemoveValidator:
+		return &RemoveValidatorWithData{Transaction: tx, Receipt: r}
+	}
+	return &TransactionWithData{Transaction: tx, Receipt: r}
+}
+
+// NewBlock returns the block after the block parent of hash parentHash with
+// the transactions which can be included, its states, its LogsBloom, its
+// BaseFee and its GasUsed. The StateHash of the block is the root of the
+// states after it, it is not set.
+func (e *Executor) NewBlock(parent *Block, parentHash libcore.Hash, timestamp int64, transactions []libblock.Transaction) (*Block, error) {
+	index := parent.GetIndex() + 1
+	bs := &blockStates{
+		e:         e,
+		index:     index,
+		timestamp: timestamp,
+		states:    map[string]libblock.State{},
+	}
+	if e.FeeMarket != nil {
+		bs.baseFee = e.FeeMarket.NextBaseFee(parent)
+	}
+	gasLimit := int64(0)
+	if e.FeeMarket != nil {
+		gasLimit = e.FeeMarket.GetGasLimit()
+	}
+	gasUsed := int64(0)
+	l := len(transactions)
+	list := make([]libblock.TransactionWithData, 0, l)
+	for i := 0; i < l; i++ {
+		gas := int64(0)
+		if e.GasSchedule != nil {
+			var err error
+			gas, err = e.GasSchedule.GetGas(transactions[i])
+			if err != nil {
+				return nil, err
+			}
+			if gasLimit > 0 && gasUsed+gas > gasLimit {
+				continue
+			}
+		}
+		r := &Receipt{
+			TransactionIndex:  uint32(len(list)),
+			TransactionResult: ResultSuccess,
+		}
+		states, err := bs.apply(transactions[i], r)
+		if err != nil {
+			re, ok := err.(*ResultError)
+			if !ok {
+				return nil, err
+			}
+			if states == nil {
+				continue
+			}
+			r.TransactionResult = re.Result
+			r.Message = re.Message
+			r.Logs = nil
+		}
+		r.States = states
+		bs.put(states)
+		gasUsed += gas
+		list = append(list, withData(transactions[i], r))
+	}
+
+	states := make([]libblock.State, len(bs.keys))
+	for i := 0; i < len(bs.keys); i++ {
+		states[i] = bs.states[bs.keys[i]]
+	}
+	return &Block{
+		BlockIndex:   index,
+		ParentHash:   parentHash,
+		Timestamp:    timestamp,
+		LogsBloom:    CreateLogsBloom(list),
+		BaseFee:      bs.baseFee,
+		GasUsed:      gasUsed,
+		Transactions: list,
+		States:       states,
+	}, nil
+}
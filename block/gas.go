@@ -0,0 +1,200 @@
+package block
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/tokentransfer/chain/core"
+
+	libblock "github.com/tokentransfer/interfaces/block"
+	libcore "github.com/tokentransfer/interfaces/core"
+)
+
+// The gas of a transaction is the base cost of its type plus a cost per byte
+// of its Payload, of its tags and of its Description. Transaction.Gas is the
+// gas limit of the transaction, the sender pays the gas used times the base
+// fee of the block.
+
+// GasSchedule is the gas cost of the transactions, Base is keyed by the core
+// tag of the transaction types, DefaultBase is the cost of the other types.
+type GasSchedule struct {
+	Base        map[byte]int64
+	DefaultBase int64
+
+	PayloadByte     int64
+	TagByte         int64
+	DescriptionByte int64
+}
+
+func NewGasSchedule() *GasSchedule {
+	return &GasSchedule{
+		Base: map[byte]int64{
+			core.CORE_TRANSACTION:    1000,
+			core.CORE_PAYMENT:        1000,
+			core.CORE_NEWDEVICE:      5000,
+			core.CORE_UPDATEDEVICE:   2000,
+			core.CORE_TRANSFERDEVICE: 2000,
+			core.CORE_RETIREDEVICE:   1000,
+
+			core.CORE_ADDVALIDATOR:    2000,
+			core.CORE_REMOVEVALIDATOR: 2000,
+		},
+		DefaultBase: 1000,
+
+		PayloadByte:     10,
+		TagByte:         10,
+		DescriptionByte: 5,
+	}
+}
+
+func tagsSize(tags []string) int64 {
+	size := int64(0)
+	for i := 0; i < len(tags); i++ {
+		size += int64(len(tags[i]))
+	}
+	return size
+}
+
+// GetGas returns the gas used by tx.
+func (s *GasSchedule) GetGas(tx libblock.Transaction) (int64, error) {
+	data, err := tx.MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+	tag, ok := core.GetTag(data)
+	if !ok {
+		return 0, errors.New("error transaction data")
+	}
+	gas, ok := s.Base[tag]
+	if !ok {
+		gas = s.DefaultBase
+	}
+
+	var payload []byte
+	var tags, description int64
+	switch t := tx.(type) {
+	case *Transaction:
+		payload = t.Payload
+	case *Payment:
+		payload = t.Payload
+		tags = tagsSize(t.Tags)
+	case *NewDevice:
+		payload = t.Payload
+		tags = tagsSize(t.DeviceTags)
+		description = int64(len(t.Description))
+	case *UpdateDevice:
+		payload = t.Payload
+		tags = tagsSize(t.DeviceTags)
+		description = int64(len(t.Description))
+	case *TransferDevice:
+		payload = t.Payload
+	case *RetireDevice:
+		payload = t.Payload
+	case *AddValidator:
+		payload = t.Payload
+	case *RemoveValidator:
+		payload = t.Payload
+	}
+	gas += int64(len(payload))*s.PayloadByte + tags*s.TagByte + description*s.DescriptionByte
+	return gas, nil
+}
+
+// CheckGas returns the gas used by tx, a *ResultError ResultOutOfGas when it
+// is over the gas limit of tx.
+func (s *GasSchedule) CheckGas(tx libblock.Transaction) (int64, error) {
+	gas, err := s.GetGas(tx)
+	if err != nil {
+		return 0, err
+	}
+	if gas > tx.GetGas() {
+		return 0, &ResultError{Result: ResultOutOfGas, Message: fmt.Sprintf("gas %d, limit %d", gas, tx.GetGas())}
+	}
+	return gas, nil
+}
+
+// FeeMarket adjusts the base fee of each block to the gas used by its
+// parent, the base fee rises when the parent used more than TargetGas and
+// falls when it used less, by at most 1/Denominator per block. A block uses
+// at most GasLimit, twice TargetGas when it is 0.
+type FeeMarket struct {
+	TargetGas      int64
+	GasLimit       int64
+	InitialBaseFee int64
+	MinBaseFee     int64
+	Denominator    int64
+
+	// Collector receives the fees, the block producer when it is nil.
+	Collector libcore.Address
+}
+
+func NewFeeMarket() *FeeMarket {
+	return &FeeMarket{
+		TargetGas:      1000000,
+		GasLimit:       2000000,
+		InitialBaseFee: 1,
+		MinBaseFee:     1,
+		Denominator:    8,
+	}
+}
+
+// GetGasLimit returns the gas a block can use at most.
+func (m *FeeMarket) GetGasLimit() int64 {
+	if m.GasLimit > 0 {
+		return m.GasLimit
+	}
+	return 2 * m.TargetGas
+}
+
+// NextBaseFee returns the base fee of the child of the block parent.
+func (m *FeeMarket) NextBaseFee(parent *Block) int64 {
+	if parent == nil || parent.BaseFee == 0 {
+		return m.InitialBaseFee
+	}
+	baseFee := parent.BaseFee
+	if m.TargetGas > 0 && m.Denominator > 0 && parent.GasUsed != m.TargetGas {
+		delta := parent.GasUsed - m.TargetGas
+		if delta > m.TargetGas {
+			delta = m.TargetGas
+		}
+		change := baseFee * delta / m.TargetGas / m.Denominator
+		if change == 0 && delta > 0 {
+			change = 1
+		}
+		baseFee += change
+	}
+	if baseFee < m.MinBaseFee {
+		baseFee = m.MinBaseFee
+	}
+	return baseFee
+}
+
+// GetCollector returns the account receiving the fees of a block produced
+// by producer.
+func (m *FeeMarket) GetCollector(producer libcore.Address) libcore.Address {
+	if m.Collector != nil {
+		return m.Collector
+	}
+	return producer
+}
+
+// ChargeFee moves the fee of gas at baseFee from the account state from to
+// the account state to and returns it, the states are the versions built for
+// the block. It fails with a *ResultError ResultInsufficientBalance.
+func ChargeFee(from *AccountState, to *AccountState, gas int64, baseFee int64) (int64, error) {
+	if gas < 0 || baseFee < 0 {
+		return 0, fmt.Errorf("error fee %d*%d", gas, baseFee)
+	}
+	if baseFee > 0 && gas > math.MaxInt64/baseFee {
+		return 0, fmt.Errorf("error fee %d*%d", gas, baseFee)
+	}
+	fee := gas * baseFee
+	if from.Amount < fee {
+		return 0, &ResultError{Result: ResultInsufficientBalance, Message: fmt.Sprintf("balance %d, fee %d", from.Amount, fee)}
+	}
+	from.Amount -= fee
+	if to != nil {
+		to.Amount += fee
+	}
+	return fee, nil
+}
@@ -0,0 +1,72 @@
+package block
+
+import (
+	"testing"
+
+	. "github.com/tokentransfer/check"
+)
+
+type GasSuite struct{}
+
+func Test_Gas(t *testing.T) {
+	s := Suite(&GasSuite{})
+	TestingRun(t, s)
+}
+
+func (suite *GasSuite) TestSchedule(c *C) {
+	a := newTestAddress(c, "0x42f32B004Da1093d51AE40a58F38E33BA4f46397")
+	schedule := NewGasSchedule()
+
+	tx := &Transaction{Account: a, Destination: a, Payload: []byte{1, 2, 3}}
+	gas, err := schedule.GetGas(tx)
+	c.Assert(err, IsNil)
+	c.Assert(gas, Equals, int64(1000+3*10))
+
+	device := &NewDevice{
+		Transaction: Transaction{Account: a, Destination: a, Gas: 5000},
+		Description: "sensor",
+		DeviceTags:  []string{"a", "bc"},
+	}
+	gas, err = schedule.GetGas(device)
+	c.Assert(err, IsNil)
+	c.Assert(gas, Equals, int64(5000+3*10+6*5))
+
+	_, err = schedule.CheckGas(device)
+	c.Assert(err.(*ResultError).Result, Equals, ResultOutOfGas)
+	device.Gas = gas
+	used, err := schedule.CheckGas(device)
+	c.Assert(err, IsNil)
+	c.Assert(used, Equals, gas)
+}
+
+func (suite *GasSuite) TestFeeMarket(c *C) {
+	m := NewFeeMarket()
+	m.TargetGas = 1000
+	m.InitialBaseFee = 80
+
+	c.Assert(m.NextBaseFee(nil), Equals, int64(80))
+	c.Assert(m.NextBaseFee(&Block{BaseFee: 80, GasUsed: 1000}), Equals, int64(80))
+	c.Assert(m.NextBaseFee(&Block{BaseFee: 80, GasUsed: 2000}), Equals, int64(90))
+	c.Assert(m.NextBaseFee(&Block{BaseFee: 80, GasUsed: 5000}), Equals, int64(90))
+	c.Assert(m.NextBaseFee(&Block{BaseFee: 80, GasUsed: 0}), Equals, int64(70))
+	c.Assert(m.NextBaseFee(&Block{BaseFee: 1, GasUsed: 0}), Equals, int64(1))
+	c.Assert(m.NextBaseFee(&Block{BaseFee: 2, GasUsed: 1500}), Equals, int64(3))
+	c.Assert(m.GetGasLimit(), Equals, int64(2000000))
+	m.GasLimit = 0
+	c.Assert(m.GetGasLimit(), Equals, int64(2000))
+
+	producer := newTestAddress(c, "0x42f32B004Da1093d51AE40a58F38E33BA4f46397")
+	c.Assert(m.GetCollector(producer), Equals, producer)
+
+	from := &AccountState{Amount: 100}
+	to := &AccountState{Amount: 0}
+	fee, err := ChargeFee(from, to, 20, 4)
+	c.Assert(err, IsNil)
+	c.Assert(fee, Equals, int64(80))
+	c.Assert(from.Amount, Equals, int64(20))
+	c.Assert(to.Amount, Equals, int64(80))
+
+	_, err = ChargeFee(from, to, 20, 4)
+	c.Assert(err.(*ResultError).Result, Equals, ResultInsufficientBalance)
+	c.Assert(from.Amount, Equals, int64(20))
+}
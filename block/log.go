@@ -0,0 +1,154 @@
+package block
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+
+	"github.com/tokentransfer/chain/core/pb"
+
+	libblock "github.com/tokentransfer/interfaces/block"
+	libcore "github.com/tokentransfer/interfaces/core"
+)
+
+// The names of the events emitted by the executor, the name is the first
+// topic of a log.
+const (
+	EventTransfer          = "Transfer"
+	EventCurrencyIssued    = "CurrencyIssued"
+	EventDeviceRegistered  = "DeviceRegistered"
+	EventDeviceUpdated     = "DeviceUpdated"
+	EventDeviceTransferred = "DeviceTransferred"
+	EventDeviceRetired     = "DeviceRetired"
+)
+
+// Log is an event of a transaction recorded in its receipt.
+type Log struct {
+	Topics []string
+	Data   libcore.Bytes
+}
+
+// Matches returns true when the topics of the log start with topics, an
+// empty topic matches any topic.
+func (l *Log) Matches(topics []string) bool {
+	if len(topics) > len(l.Topics) {
+		return false
+	}
+	for i := 0; i < len(topics); i++ {
+		if len(topics[i]) > 0 && topics[i] != l.Topics[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// newLog returns a log of the event with the addresses of accounts as
+// topics after args.
+func newLog(event string, data []byte, args []string, accounts ...libcore.Address) (*Log, error) {
+	topics := make([]string, 0, 1+len(args)+len(accounts))
+	topics = append(topics, event)
+	topics = append(topics, args...)
+	for i := 0; i < len(accounts); i++ {
+		a, err := accounts[i].GetAddress()
+		if err != nil {
+			return nil, err
+		}
+		topics = append(topics, a)
+	}
+	return &Log{Topics: topics, Data: libcore.Bytes(data)}, nil
+}
+
+func toLogs(list []*pb.Log) []*Log {
+	if len(list) == 0 {
+		return nil
+	}
+	logs := make([]*Log, len(list))
+	for i := 0; i < len(list); i++ {
+		logs[i] = &Log{
+			Topics: list[i].Topics,
+			Data:   libcore.Bytes(list[i].Data),
+		}
+	}
+	return logs
+}
+
+func fromLogs(logs []*Log) []*pb.Log {
+	if len(logs) == 0 {
+		return nil
+	}
+	list := make([]*pb.Log, len(logs))
+	for i := 0; i < len(logs); i++ {
+		list[i] = &pb.Log{
+			Topics: logs[i].Topics,
+			Data:   []byte(logs[i].Data),
+		}
+	}
+	return list
+}
+
+// BloomSize is the size in bytes of the logs bloom filter of a block, each
+// topic sets 3 of its bits.
+const BloomSize = 256
+
+type Bloom [BloomSize]byte
+
+func bloomBits(topic string) [3]uint {
+	h := sha256.Sum256([]byte(topic))
+	var bits [3]uint
+	for i := 0; i < 3; i++ {
+		bits[i] = uint(binary.BigEndian.Uint16(h[2*i:])) % (BloomSize * 8)
+	}
+	return bits
+}
+
+func (b *Bloom) Add(topic string) {
+	for _, bit := range bloomBits(topic) {
+		b[bit/8] |= 1 << (bit % 8)
+	}
+}
+
+// Test returns false when topic is not in the filter.
+func (b *Bloom) Test(topic string) bool {
+	for _, bit := range bloomBits(topic) {
+		if b[bit/8]&(1<<(bit%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Bytes returns nil for an empty filter.
+func (b *Bloom) Bytes() libcore.Bytes {
+	for i := 0; i < BloomSize; i++ {
+		if b[i] != 0 {
+			return libcore.Bytes(b[:])
+		}
+	}
+	return nil
+}
+
+// GetLogs returns the logs of the receipt of a transaction.
+func GetLogs(txWithData libblock.TransactionWithData) []*Log {
+	receipt := txWithData.GetReceipt()
+	if receipt == nil {
+		return nil
+	}
+	r, ok := receipt.(interface{ GetLogs() []*Log })
+	if !ok {
+		return nil
+	}
+	return r.GetLogs()
+}
+
+// CreateLogsBloom returns the logs bloom filter of the transactions of a
+// block, nil when they have no logs.
+func CreateLogsBloom(transactions []libblock.TransactionWithData) libcore.Bytes {
+	var b Bloom
+	for _, txWithData := range transactions {
+		for _, l := range GetLogs(txWithData) {
+			for _, topic := range l.Topics {
+				b.Add(topic)
+			}
+		}
+	}
+	return b.Bytes()
+}
@@ -0,0 +1,65 @@
+package block
+
+import (
+	"testing"
+
+	. "github.com/tokentransfer/check"
+	libblock "github.com/tokentransfer/interfaces/block"
+)
+
+type LogSuite struct{}
+
+func Test_Log(t *testing.T) {
+	s := Suite(&LogSuite{})
+	TestingRun(t, s)
+}
+
+func (suite *LogSuite) TestMatches(c *C) {
+	l := &Log{Topics: []string{EventTransfer, "from", "to"}}
+	c.Assert(l.Matches(nil), Equals, true)
+	c.Assert(l.Matches([]string{EventTransfer}), Equals, true)
+	c.Assert(l.Matches([]string{"", "from"}), Equals, true)
+	c.Assert(l.Matches([]string{"", "to"}), Equals, false)
+	c.Assert(l.Matches([]string{EventTransfer, "from", "to", "more"}), Equals, false)
+}
+
+func (suite *LogSuite) TestBloom(c *C) {
+	r := &Receipt{
+		Logs: []*Log{
+			{Topics: []string{EventDeviceRegistered, "sensor"}, Data: []byte{1, 2}},
+		},
+	}
+	data, err := r.MarshalBinary()
+	c.Assert(err, IsNil)
+	newReceipt := &Receipt{}
+	err = newReceipt.UnmarshalBinary(data)
+	c.Assert(err, IsNil)
+	c.Assert(newReceipt.GetLogs(), DeepEquals, r.Logs)
+
+	a := newTestAddress(c, "0x42f32B004Da1093d51AE40a58F38E33BA4f46397")
+	tx := &Transaction{Account: a, Destination: a}
+	txs := []libblock.TransactionWithData{
+		&TransactionWithData{Transaction: tx, Receipt: newReceipt},
+		&TransactionWithData{Transaction: tx, Receipt: &Receipt{}},
+	}
+	b := &Block{
+		BlockIndex:   1,
+		LogsBloom:    CreateLogsBloom(txs),
+		Transactions: txs,
+	}
+	c.Assert(len(b.LogsBloom), Equals, BloomSize)
+	c.Assert(b.MayHaveTopic(EventDeviceRegistered), Equals, true)
+	c.Assert(b.MayHaveTopic("sensor"), Equals, true)
+	c.Assert(b.MayHaveTopic(EventTransfer), Equals, false)
+
+	data, err = b.MarshalBinary()
+	c.Assert(err, IsNil)
+	newBlock := &Block{}
+	err = newBlock.UnmarshalBinary(data)
+	c.Assert(err, IsNil)
+	c.Assert(newBlock.LogsBloom, DeepEquals, b.LogsBloom)
+	c.Assert(len(GetLogs(newBlock.Transactions[0])), Equals, 1)
+
+	c.Assert(CreateLogsBloom(txs[1:]), IsNil)
+	c.Assert((&Block{}).MayHaveTopic(EventTransfer), Equals, false)
+}
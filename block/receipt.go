@@ -15,7 +15,11 @@ type Receipt struct {
 	TransactionIndex  uint32
 	TransactionResult libblock.TransactionResult
 
+	// Message explains a failed transaction, it is empty on success.
+	Message string
+
 	States []libblock.State
+	Logs   []*Log
 }
 
 func (r *Receipt) GetHash() libcore.Hash {
@@ -38,6 +42,8 @@ func (r *Receipt) UnmarshalBinary(data []byte) error {
 	receipt := msg.(*pb.Receipt)
 	r.TransactionResult = libblock.TransactionResult(receipt.TransactionResult)
 	r.TransactionIndex = receipt.TransactionIndex
+	r.Message = receipt.Message
+	r.Logs = toLogs(receipt.Logs)
 
 	list := receipt.GetStates()
 	l := len(list)
@@ -69,7 +75,9 @@ func (r *Receipt) MarshalBinary() ([]byte, error) {
 	receipt := &pb.Receipt{
 		TransactionResult: uint32(r.TransactionResult),
 		TransactionIndex:  r.TransactionIndex,
+		Message:           r.Message,
 		States:            states,
+		Logs:              fromLogs(r.Logs),
 	}
 	return core.Marshal(receipt)
 }
@@ -87,7 +95,9 @@ func (r *Receipt) Raw(ignoreSigningFields bool) ([]byte, error) {
 	}
 	receipt := &pb.Receipt{
 		TransactionResult: uint32(r.TransactionResult),
+		Message:           r.Message,
 		States:            states,
+		Logs:              fromLogs(r.Logs),
 	}
 	return core.Marshal(receipt)
 }
@@ -104,6 +114,14 @@ func (r *Receipt) GetTransactionResult() libblock.TransactionResult {
 	return r.TransactionResult
 }
 
+func (r *Receipt) GetMessage() string {
+	return r.Message
+}
+
 func (r *Receipt) GetStates() []libblock.State {
 	return r.States
 }
+
+func (r *Receipt) GetLogs() []*Log {
+	return r.Logs
+}
@@ -3,7 +3,10 @@ package block
 import (
 	"testing"
 
+	"github.com/tokentransfer/chain/core"
+
 	. "github.com/tokentransfer/check"
+	libblock "github.com/tokentransfer/interfaces/block"
 )
 
 type ReceiptSuite struct{}
@@ -12,3 +15,32 @@ func Test_Receipt(t *testing.T) {
 	s := Suite(&ReceiptSuite{})
 	TestingRun(t, s)
 }
+
+func (suite *ReceiptSuite) TestResult(c *C) {
+	r := &Receipt{
+		TransactionResult: ResultInsufficientBalance,
+		Message:           "balance 10, amount 20",
+	}
+	data, err := r.MarshalBinary()
+	c.Assert(err, IsNil)
+	newReceipt := &Receipt{}
+	err = newReceipt.UnmarshalBinary(data)
+	c.Assert(err, IsNil)
+	c.Assert(newReceipt.GetMessage(), Equals, "balance 10, amount 20")
+
+	txWithData := &TransactionWithData{Receipt: newReceipt}
+	result, message := GetResult(txWithData)
+	c.Assert(result, Equals, ResultInsufficientBalance)
+	c.Assert(message, Equals, "balance 10, amount 20")
+	err = TransactionError(txWithData)
+	c.Assert(err, ErrorMatches, "insufficient_balance: balance 10, amount 20")
+	c.Assert(err.(*ResultError).Result, Equals, ResultInsufficientBalance)
+
+	txWithData.Receipt = &Receipt{}
+	c.Assert(TransactionError(txWithData), IsNil)
+	c.Assert(ResultName(libblock.TransactionResult(200)), Equals, "result_200")
+
+	legacy, err := (&Receipt{TransactionResult: ResultSuccess}).MarshalBinary()
+	c.Assert(err, IsNil)
+	c.Assert(legacy, DeepEquals, []byte{core.CORE_RECEIPT})
+}
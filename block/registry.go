@@ -0,0 +1,180 @@
+package block
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/tokentransfer/chain/core"
+	"github.com/tokentransfer/chain/core/pb"
+	"google.golang.org/protobuf/proto"
+
+	libblock "github.com/tokentransfer/interfaces/block"
+)
+
+// The states and the transactions are decoded by the UnmarshalBinary of the
+// object built for their tag. A package adding a state type registers it
+// with RegisterState and a transaction type with RegisterTransaction, which
+// register their messages with core.RegisterType.
+
+var (
+	stateReaders       = map[byte]func() libblock.State{}
+	transactionReaders = map[byte]func() libblock.TransactionWithData{}
+	rawReaders         = map[byte]func() libblock.Transaction{}
+	readersLocker      = &sync.RWMutex{}
+)
+
+// TransactionType binds the core types of a transaction and of the
+// transaction with its data to the constructors used by ReadTransaction and
+// ReadTxWithData.
+type TransactionType struct {
+	Transaction    core.Type
+	NewTransaction func() libblock.Transaction
+	WithData       core.Type
+	NewWithData    func() libblock.TransactionWithData
+}
+
+// RegisterState registers the tag, name and message of a state type with
+// core.RegisterType and the constructor used by ReadState.
+func RegisterState(t core.Type, newState func() libblock.State) error {
+	if t.New == nil || newState == nil {
+		return fmt.Errorf("state type %d has no constructor", t.Tag)
+	}
+	err := core.RegisterType(t)
+	if err != nil {
+		return err
+	}
+	readersLocker.Lock()
+	defer readersLocker.Unlock()
+
+	stateReaders[t.Tag] = newState
+	return nil
+}
+
+// RegisterTransaction registers the types of a transaction and of the
+// transaction with its data with core.RegisterTypes, and their
+// constructors.
+func RegisterTransaction(t TransactionType) error {
+	if t.Transaction.New == nil || t.NewTransaction == nil {
+		return fmt.Errorf("transaction type %d has no constructor", t.Transaction.Tag)
+	}
+	if t.WithData.New == nil || t.NewWithData == nil {
+		return fmt.Errorf("transaction type %d has no constructor", t.WithData.Tag)
+	}
+	err := core.RegisterTypes(t.Transaction, t.WithData)
+	if err != nil {
+		return err
+	}
+	readersLocker.Lock()
+	defer readersLocker.Unlock()
+
+	rawReaders[t.Transaction.Tag] = t.NewTransaction
+	transactionReaders[t.WithData.Tag] = t.NewWithData
+	return nil
+}
+
+func getStateReader(tag byte) (func() libblock.State, bool) {
+	readersLocker.RLock()
+	defer readersLocker.RUnlock()
+
+	newState, ok := stateReaders[tag]
+	return newState, ok
+}
+
+func getTransactionReader(tag byte) (func() libblock.TransactionWithData, bool) {
+	readersLocker.RLock()
+	defer readersLocker.RUnlock()
+
+	newTx, ok := transactionReaders[tag]
+	return newTx, ok
+}
+
+func getRawReader(tag byte) (func() libblock.Transaction, bool) {
+	readersLocker.RLock()
+	defer readersLocker.RUnlock()
+
+	newTx, ok := rawReaders[tag]
+	return newTx, ok
+}
+
+func init() {
+	states := []struct {
+		t        core.Type
+		newState func() libblock.State
+	}{
+		{
+			core.Type{Tag: core.CORE_ACCOUNT_STATE, Name: "account_state", New: func() proto.Message { return &pb.AccountState{} }},
+			func() libblock.State { return &AccountState{} },
+		},
+		{
+			core.Type{Tag: core.CORE_CURRENCY_STATE, Name: "currency_state", New: func() proto.Message { return &pb.CurrencyState{} }},
+			func() libblock.State { return &CurrencyState{} },
+		},
+		{
+			core.Type{Tag: core.CORE_DEVICE_STATE, Name: "device_state", New: func() proto.Message { return &pb.DeviceState{} }},
+			func() libblock.State { return &DeviceState{} },
+		},
+	}
+	for _, s := range states {
+		err := RegisterState(s.t, s.newState)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	transactions := []TransactionType{
+		{
+			Transaction:    core.Type{Tag: core.CORE_TRANSACTION, Name: "transaction", New: func() proto.Message { return &pb.Transaction{} }},
+			NewTransaction: func() libblock.Transaction { return &Transaction{} },
+			WithData:       core.Type{Tag: core.CORE_TRANSACTION_WITH_DATA, Name: "transaction_with_data", New: func() proto.Message { return &pb.TransactionWithData{} }},
+			NewWithData:    func() libblock.TransactionWithData { return &TransactionWithData{} },
+		},
+		{
+			Transaction:    core.Type{Tag: core.CORE_PAYMENT, Name: "payment", New: func() proto.Message { return &pb.Payment{} }},
+			NewTransaction: func() libblock.Transaction { return &Payment{} },
+			WithData:       core.Type{Tag: core.CORE_PAYMENT_WITH_DATA, Name: "payment_with_data", New: func() proto.Message { return &pb.PaymentWithData{} }},
+			NewWithData:    func() libblock.TransactionWithData { return &PaymentWithData{} },
+		},
+		{
+			Transaction:    core.Type{Tag: core.CORE_NEWDEVICE, Name: "new_device", New: func() proto.Message { return &pb.NewDevice{} }},
+			NewTransaction: func() libblock.Transaction { return &NewDevice{} },
+			WithData:       core.Type{Tag: core.CORE_NEWDEVICE_WITH_DATA, Name: "newdevice_with_data", New: func() proto.Message { return &pb.NewDeviceWithData{} }},
+			NewWithData:    func() libblock.TransactionWithData { return &NewDeviceWithData{} },
+		},
+		{
+			Transaction:    core.Type{Tag: core.CORE_UPDATEDEVICE, Name: "update_device", New: func() proto.Message { return &pb.UpdateDevice{} }},
+			NewTransaction: func() libblock.Transaction { return &UpdateDevice{} },
+			WithData:       core.Type{Tag: core.CORE_UPDATEDEVICE_WITH_DATA, Name: "updatedevice_with_data", New: func() proto.Message { return &pb.UpdateDeviceWithData{} }},
+			NewWithData:    func() libblock.TransactionWithData { return &UpdateDeviceWithData{} },
+		},
+		{
+			Transaction:    core.Type{Tag: core.CORE_TRANSFERDEVICE, Name: "transfer_device", New: func() proto.Message { return &pb.TransferDevice{} }},
+			NewTransaction: func() libblock.Transaction { return &TransferDevice{} },
+			WithData:       core.Type{Tag: core.CORE_TRANSFERDEVICE_WITH_DATA, Name: "transferdevice_with_data", New: func() proto.Message { return &pb.TransferDeviceWithData{} }},
+			NewWithData:    func() libblock.TransactionWithData { return &TransferDeviceWithData{} },
+		},
+		{
+			Transaction:    core.Type{Tag: core.CORE_RETIREDEVICE, Name: "retire_device", New: func() proto.Message { return &pb.RetireDevice{} }},
+			NewTransaction: func() libblock.Transaction { return &RetireDevice{} },
+			WithData:       core.Type{Tag: core.CORE_RETIREDEVICE_WITH_DATA, Name: "retiredevice_with_data", New: func() proto.Message { return &pb.RetireDeviceWithData{} }},
+			NewWithData:    func() libblock.TransactionWithData { return &RetireDeviceWithData{} },
+		},
+		{
+			Transaction:    core.Type{Tag: core.CORE_ADDVALIDATOR, Name: "add_validator", New: func() proto.Message { return &pb.AddValidator{} }},
+			NewTransaction: func() libblock.Transaction { return &AddValidator{} },
+			WithData:       core.Type{Tag: core.CORE_ADDVALIDATOR_WITH_DATA, Name: "addvalidator_with_data", New: func() proto.Message { return &pb.AddValidatorWithData{} }},
+			NewWithData:    func() libblock.TransactionWithData { return &AddValidatorWithData{} },
+		},
+		{
+			Transaction:    core.Type{Tag: core.CORE_REMOVEVALIDATOR, Name: "remove_validator", New: func() proto.Message { return &pb.RemoveValidator{} }},
+			NewTransaction: func() libblock.Transaction { return &RemoveValidator{} },
+			WithData:       core.Type{Tag: core.CORE_REMOVEVALIDATOR_WITH_DATA, Name: "removevalidator_with_data", New: func() proto.Message { return &pb.RemoveValidatorWithData{} }},
+			NewWithData:    func() libblock.TransactionWithData { return &RemoveValidatorWithData{} },
+		},
+	}
+	for _, t := range transactions {
+		err := RegisterTransaction(t)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
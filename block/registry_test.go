@@ -0,0 +1,346 @@
+package block
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tokentransfer/chain/core"
+	"github.com/tokentransfer/chain/core/pb"
+	"github.com/tokentransfer/chain/crypto"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	. "github.com/tokentransfer/check"
+	libblock "github.com/tokentransfer/interfaces/block"
+	libcore "github.com/tokentransfer/interfaces/core"
+	libcrypto "github.com/tokentransfer/interfaces/crypto"
+)
+
+type RegistrySuite struct{}
+
+func Test_Registry(t *testing.T) {
+	s := Suite(&RegistrySuite{})
+	TestingRun(t, s)
+}
+
+const testStateTag = byte(250)
+
+type testState struct {
+	State
+
+	Key string
+}
+
+func (s *testState) GetIndex() uint64 {
+	return 0
+}
+
+func (s *testState) GetStateKey() string {
+	return s.Key
+}
+
+func (s *testState) UnmarshalBinary(data []byte) error {
+	meta, msg, err := core.Unmarshal(data)
+	if err != nil {
+		return err
+	}
+	if meta != testStateTag {
+		return errors.New("error test state data")
+	}
+	s.Key = msg.(*wrapperspb.StringValue).Value
+	return nil
+}
+
+func (s *testState) MarshalBinary() ([]byte, error) {
+	return core.Marshal(wrapperspb.String(s.Key))
+}
+
+func (s *testState) Raw(ignoreSigningFields bool) ([]byte, error) {
+	return s.MarshalBinary()
+}
+
+func (suite *RegistrySuite) TestRegisterState(c *C) {
+	t := core.Type{
+		Tag:  testStateTag,
+		Name: "test_state",
+		New: func() proto.Message {
+			return &wrapperspb.StringValue{}
+		},
+	}
+	err := RegisterState(t, func() libblock.State {
+		return &testState{}
+	})
+	c.Assert(err, IsNil)
+	err = RegisterState(t, func() libblock.State {
+		return &testState{}
+	})
+	c.Assert(err, NotNil)
+
+	data, err := (&testState{Key: "key"}).MarshalBinary()
+	c.Assert(err, IsNil)
+	c.Assert(data[0], Equals, testStateTag)
+	c.Assert(core.GetInfo(data), Equals, "test_state")
+
+	s, err := ReadState(data)
+	c.Assert(err, IsNil)
+	c.Assert(s.GetStateKey(), Equals, "key")
+}
+
+const (
+	testTransactionTag = byte(251)
+	testWithDataTag    = byte(252)
+)
+
+type testTransaction struct {
+	Transaction
+
+	Value uint64
+}
+
+func (tx *testTransaction) UnmarshalBinary(data []byte) error {
+	meta, msg, err := core.Unmarshal(data)
+	if err != nil {
+		return err
+	}
+	if meta != testTransactionTag {
+		return errors.New("error test transaction data")
+	}
+	tx.Value = msg.(*wrapperspb.UInt64Value).Value
+	return nil
+}
+
+func (tx *testTransaction) MarshalBinary() ([]byte, error) {
+	return core.Marshal(wrapperspb.UInt64(tx.Value))
+}
+
+type testWithData struct {
+	TransactionWithData
+}
+
+func (txWithData *testWithData) UnmarshalBinary(data []byte) error {
+	meta, msg, err := core.Unmarshal(data)
+	if err != nil {
+		return err
+	}
+	if meta != testWithDataTag {
+		return errors.New("error test transaction data")
+	}
+	tx, err := ReadTransaction(msg.(*wrapperspb.BytesValue).Value)
+	if err != nil {
+		return err
+	}
+	txWithData.Transaction = tx
+	return nil
+}
+
+func (txWithData *testWithData) MarshalBinary() ([]byte, error) {
+	data, err := txWithData.Transaction.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return core.Marshal(wrapperspb.Bytes(data))
+}
+
+func (suite *RegistrySuite) TestRegisterTransaction(c *C) {
+	t := TransactionType{
+		Transaction: core.Type{
+			Tag:  testTransactionTag,
+			Name: "test_transaction",
+			New: func() proto.Message {
+				return &wrapperspb.UInt64Value{}
+			},
+		},
+		NewTransaction: func() libblock.Transaction {
+			return &testTransaction{}
+		},
+		WithData: core.Type{
+			Tag:  testWithDataTag,
+			Name: "test_with_data",
+			New: func() proto.Message {
+				return &wrapperspb.BytesValue{}
+			},
+		},
+		NewWithData: func() libblock.TransactionWithData {
+			return &testWithData{}
+		},
+	}
+	err := RegisterTransaction(t)
+	c.Assert(err, IsNil)
+	err = RegisterTransaction(t)
+	c.Assert(err, NotNil)
+
+	data, err := (&testWithData{TransactionWithData{Transaction: &testTransaction{Value: 7}}}).MarshalBinary()
+	c.Assert(err, IsNil)
+	c.Assert(core.GetInfo(data), Equals, "test_with_data")
+	txWithData, err := ReadTxWithData(data)
+	c.Assert(err, IsNil)
+	c.Assert(txWithData.GetTransaction().(*testTransaction).Value, Equals, uint64(7))
+
+	// none of the types is registered when one of them is taken
+	other := TransactionType{
+		Transaction: core.Type{
+			Tag:  253,
+			Name: "other_transaction",
+			New: func() proto.Message {
+				return &wrapperspb.Int64Value{}
+			},
+		},
+		NewTransaction: t.NewTransaction,
+		WithData:       t.WithData,
+		NewWithData:    t.NewWithData,
+	}
+	err = RegisterTransaction(other)
+	c.Assert(err, NotNil)
+	_, ok := core.GetType(253)
+	c.Assert(ok, Equals, false)
+	_, err = core.Marshal(wrapperspb.Int64(1))
+	c.Assert(err, NotNil)
+}
+
+func (suite *RegistrySuite) TestBuiltinTypes(c *C) {
+	err := core.RegisterType(core.Type{
+		Tag:  core.CORE_ACCOUNT_STATE,
+		Name: "account_state",
+	})
+	c.Assert(err, NotNil)
+
+	t, ok := core.GetType(core.CORE_PAYMENT_WITH_DATA)
+	c.Assert(ok, Equals, true)
+	c.Assert(t.Name, Equals, "payment_with_data")
+
+	_, err = ReadState([]byte{core.CORE_BLOCK})
+	c.Assert(err, NotNil)
+
+	for _, tag := range []byte{core.CORE_PAYMENT, core.CORE_ADDVALIDATOR} {
+		_, ok := getRawReader(tag)
+		c.Assert(ok, Equals, true)
+		_, ok = core.GetType(tag)
+		c.Assert(ok, Equals, true)
+	}
+	_, ok = getStateReader(core.CORE_DEVICE_STATE)
+	c.Assert(ok, Equals, true)
+}
+
+func (suite *RegistrySuite) TestVersion(c *C) {
+	_, ok := core.GetType(testStateTag)
+	if !ok {
+		suite.TestRegisterState(c)
+	}
+
+	data, err := core.MarshalVersion(wrapperspb.String("key"), 3)
+	c.Assert(err, IsNil)
+	c.Assert(data[0], Equals, core.CORE_VERSIONED)
+	c.Assert(core.GetVersion(data), Equals, byte(3))
+	c.Assert(core.GetInfo(data), Equals, "test_state")
+
+	s, err := ReadState(data)
+	c.Assert(err, IsNil)
+	c.Assert(s.GetStateKey(), Equals, "key")
+
+	legacy, err := (&testState{Key: "key"}).MarshalBinary()
+	c.Assert(err, IsNil)
+	c.Assert(legacy[0], Equals, testStateTag)
+	c.Assert(core.GetVersion(legacy), Equals, byte(0))
+
+	_, err = ReadState([]byte{core.CORE_VERSIONED, 0, testStateTag})
+	c.Assert(err, NotNil)
+	_, err = ReadState([]byte{core.CORE_VERSIONED, 1})
+	c.Assert(err, NotNil)
+}
+
+func (suite *RegistrySuite) TestBlockVersion(c *C) {
+	b := &Block{BlockIndex: 3, ParentHash: libcore.Hash([]byte{1, 2}), Timestamp: 180}
+	legacy, err := b.MarshalBinary()
+	c.Assert(err, IsNil)
+	c.Assert(legacy[0], Equals, core.CORE_BLOCK)
+	c.Assert(core.GetVersion(legacy), Equals, byte(0))
+
+	// a newer version with a field unknown to this one
+	msg := &pb.Block{BlockIndex: 3, ParentHash: []byte{1, 2}, Timestamp: 180}
+	data, err := core.MarshalVersion(msg, 2)
+	c.Assert(err, IsNil)
+	data = protowire.AppendTag(data, 999, protowire.VarintType)
+	data = protowire.AppendVarint(data, 7)
+	c.Assert(core.GetVersion(data), Equals, byte(2))
+
+	decoded := &Block{}
+	err = decoded.UnmarshalBinary(data)
+	c.Assert(err, IsNil)
+	c.Assert(decoded.BlockIndex, Equals, uint64(3))
+	c.Assert(decoded.Timestamp, Equals, int64(180))
+	c.Assert([]byte(decoded.ParentHash), DeepEquals, []byte{1, 2})
+
+	// it is encoded at version 0 without the unknown field
+	encoded, err := decoded.MarshalBinary()
+	c.Assert(err, IsNil)
+	c.Assert(encoded, DeepEquals, legacy)
+}
+
+const testVersionedTag = byte(249)
+
+func (suite *RegistrySuite) TestVersionedType(c *C) {
+	err := core.RegisterType(core.Type{
+		Tag:  testVersionedTag,
+		Name: "test_versioned",
+		New: func() proto.Message {
+			return &wrapperspb.Int32Value{}
+		},
+		Version: 2,
+	})
+	c.Assert(err, IsNil)
+
+	// new data is written at the version of the type
+	data, err := core.Marshal(wrapperspb.Int32(7))
+	c.Assert(err, IsNil)
+	c.Assert(data[0], Equals, core.CORE_VERSIONED)
+	c.Assert(core.GetVersion(data), Equals, byte(2))
+	c.Assert(core.GetInfo(data), Equals, "test_versioned")
+
+	// the data written before, without a version, is still read
+	legacy, err := core.MarshalVersion(wrapperspb.Int32(7), 0)
+	c.Assert(err, IsNil)
+	c.Assert(legacy[0], Equals, testVersionedTag)
+
+	for _, encoded := range [][]byte{data, legacy} {
+		meta, version, msg, err := core.UnmarshalVersion(encoded)
+		c.Assert(err, IsNil)
+		c.Assert(meta, Equals, testVersionedTag)
+		c.Assert(version, Equals, core.GetVersion(encoded))
+		c.Assert(msg.(*wrapperspb.Int32Value).Value, Equals, int32(7))
+
+		// it is encoded back to the same data at its version only
+		again, err := core.MarshalVersion(msg, version)
+		c.Assert(err, IsNil)
+		c.Assert(again, DeepEquals, encoded)
+		again, err = core.Marshal(msg)
+		c.Assert(err, IsNil)
+		c.Assert(again, DeepEquals, data)
+	}
+}
+
+func (suite *RegistrySuite) TestVersionHash(c *C) {
+	b := &Block{BlockIndex: 3, Timestamp: 180}
+	legacy, err := b.MarshalBinary()
+	c.Assert(err, IsNil)
+	data, err := core.MarshalVersion(&pb.Block{BlockIndex: 3, Timestamp: 180}, 2)
+	c.Assert(err, IsNil)
+
+	// the hash is the one of the object, whatever the data it was read from
+	cs := &crypto.CryptoService{}
+	h, _, err := cs.Raw(b, libcrypto.RawBinary)
+	c.Assert(err, IsNil)
+	decoded := &Block{}
+	err = decoded.UnmarshalBinary(data)
+	c.Assert(err, IsNil)
+	dh, raw, err := cs.Raw(decoded, libcrypto.RawBinary)
+	c.Assert(err, IsNil)
+	c.Assert(dh, DeepEquals, h)
+	c.Assert(raw, DeepEquals, legacy)
+	lh, err := cs.Hash(legacy)
+	c.Assert(err, IsNil)
+	c.Assert(dh, DeepEquals, lh)
+	vh, err := cs.Hash(data)
+	c.Assert(err, IsNil)
+	c.Assert(dh, Not(DeepEquals), vh)
+}
@@ -0,0 +1,102 @@
+package block
+
+import (
+	"fmt"
+
+	libblock "github.com/tokentransfer/interfaces/block"
+)
+
+// The results of the transactions recorded in their receipts, the values are
+// stored and must never change.
+const (
+	ResultSuccess             = libblock.TransactionResult(0)
+	ResultNotYetValid         = libblock.TransactionResult(1)
+	ResultExpired             = libblock.TransactionResult(2)
+	ResultInsufficientBalance = libblock.TransactionResult(3)
+	ResultBadSequence         = libblock.TransactionResult(4)
+	ResultBadSignature        = libblock.TransactionResult(5)
+	ResultWrongChain          = libblock.TransactionResult(6)
+	ResultUnknownCurrency     = libblock.TransactionResult(7)
+	ResultCurrencyExists      = libblock.TransactionResult(8)
+	ResultUnknownDevice       = libblock.TransactionResult(9)
+	ResultDeviceExists        = libblock.TransactionResult(10)
+	ResultDeviceNotOwned      = libblock.TransactionResult(11)
+	ResultDeviceRetired       = libblock.TransactionResult(12)
+	ResultOutOfGas            = libblock.TransactionResult(13)
+	ResultMalformed           = libblock.TransactionResult(14)
+	ResultNotValidator        = libblock.TransactionResult(15)
+	ResultValidatorExists     = libblock.TransactionResult(16)
+	ResultUnknownValidator    = libblock.TransactionResult(17)
+	ResultLastValidator       = libblock.TransactionResult(18)
+	ResultFailed              = libblock.TransactionResult(255)
+)
+
+var resultNames = map[libblock.TransactionResult]string{
+	ResultSuccess:             "success",
+	ResultNotYetValid:         "not_yet_valid",
+	ResultExpired:             "expired",
+	ResultInsufficientBalance: "insufficient_balance",
+	ResultBadSequence:         "bad_sequence",
+	ResultBadSignature:        "bad_signature",
+	ResultWrongChain:          "wrong_chain",
+	ResultUnknownCurrency:     "unknown_currency",
+	ResultCurrencyExists:      "currency_exists",
+	ResultUnknownDevice:       "unknown_device",
+	ResultDeviceExists:        "device_exists",
+	ResultDeviceNotOwned:      "device_not_owned",
+	ResultDeviceRetired:       "device_retired",
+	ResultOutOfGas:            "out_of_gas",
+	ResultMalformed:           "malformed",
+	ResultNotValidator:        "not_validator",
+	ResultValidatorExists:     "validator_exists",
+	ResultUnknownValidator:    "unknown_validator",
+	ResultLastValidator:       "last_validator",
+	ResultFailed:              "failed",
+}
+
+// ResultName returns the name of r, "result_N" for an unknown result.
+func ResultName(r libblock.TransactionResult) string {
+	name, ok := resultNames[r]
+	if !ok {
+		return fmt.Sprintf("result_%d", r)
+	}
+	return name
+}
+
+// ResultError is the error of a failed transaction.
+type ResultError struct {
+	Result  libblock.TransactionResult
+	Message string
+}
+
+func (e *ResultError) Error() string {
+	if len(e.Message) == 0 {
+		return ResultName(e.Result)
+	}
+	return fmt.Sprintf("%s: %s", ResultName(e.Result), e.Message)
+}
+
+// GetResult returns the result of a transaction and the message of its
+// receipt, ResultFailed when it has no receipt.
+func GetResult(txWithData libblock.TransactionWithData) (libblock.TransactionResult, string) {
+	receipt := txWithData.GetReceipt()
+	if receipt == nil {
+		return ResultFailed, "no receipt"
+	}
+	message := ""
+	m, ok := receipt.(interface{ GetMessage() string })
+	if ok {
+		message = m.GetMessage()
+	}
+	return receipt.GetTransactionResult(), message
+}
+
+// TransactionError returns nil when the transaction succeeded and a
+// *ResultError otherwise.
+func TransactionError(txWithData libblock.TransactionWithData) error {
+	result, message := GetResult(txWithData)
+	if result == ResultSuccess {
+		return nil
+	}
+	return &ResultError{Result: result, Message: message}
+}
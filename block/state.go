@@ -204,6 +204,7 @@ type DeviceState struct {
 	Symbol      string
 	Description string
 	Tags        []string
+	Retired     bool
 }
 
 func (s *DeviceState) GetIndex() uint64 {
@@ -237,6 +238,7 @@ func (s *DeviceState) UnmarshalBinary(data []byte) error {
 	s.Symbol = state.Symbol
 	s.Description = state.Description
 	s.Tags = state.Tags
+	s.Retired = state.Retired
 
 	return nil
 }
@@ -255,6 +257,7 @@ func (s *DeviceState) MarshalBinary() ([]byte, error) {
 		Symbol:      s.Symbol,
 		Description: s.Description,
 		Tags:        s.Tags,
+		Retired:     s.Retired,
 	})
 }
 
@@ -271,39 +274,25 @@ func (s *DeviceState) Raw(ignoreSigningFields bool) ([]byte, error) {
 		Symbol:      s.Symbol,
 		Description: s.Description,
 		Tags:        s.Tags,
+		Retired:     s.Retired,
 	})
 }
 
 func ReadState(data []byte) (libblock.State, error) {
-	if len(data) == 0 {
+	tag, ok := core.GetTag(data)
+	if !ok {
 		return nil, errors.New("error entry")
 	}
-	meta := data[0]
-	switch meta {
-	case core.CORE_ACCOUNT_STATE:
-		s := &AccountState{}
-		err := s.UnmarshalBinary(data)
-		if err != nil {
-			return nil, err
-		}
-		return s, nil
-	case core.CORE_CURRENCY_STATE:
-		s := &CurrencyState{}
-		err := s.UnmarshalBinary(data)
-		if err != nil {
-			return nil, err
-		}
-		return s, nil
-	case core.CORE_DEVICE_STATE:
-		s := &DeviceState{}
-		err := s.UnmarshalBinary(data)
-		if err != nil {
-			return nil, err
-		}
-		return s, nil
-	default:
+	newState, ok := getStateReader(tag)
+	if !ok {
 		return nil, errors.New("error data")
 	}
+	s := newState()
+	err := s.UnmarshalBinary(data)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
 }
 
 func CloneState(state libblock.State) (libblock.State, error) {
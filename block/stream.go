@@ -0,0 +1,112 @@
+package block
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/tokentransfer/chain/core"
+
+	libblock "github.com/tokentransfer/interfaces/block"
+)
+
+// A block stream is a header record holding the magic and the version of
+// the stream followed by one record per block, see core.WriteRecord. The
+// blocks are in their binary form, with their transactions and states.
+
+const BlockStreamVersion = uint32(1)
+
+var blockStreamMagic = []byte("TTBS")
+
+type BlockWriter struct {
+	w     io.Writer
+	Count uint64
+}
+
+// NewBlockWriter writes the header of a block stream to w.
+func NewBlockWriter(w io.Writer) (*BlockWriter, error) {
+	header := make([]byte, len(blockStreamMagic)+4)
+	copy(header, blockStreamMagic)
+	binary.LittleEndian.PutUint32(header[len(blockStreamMagic):], BlockStreamVersion)
+	err := core.WriteRecord(w, header)
+	if err != nil {
+		return nil, err
+	}
+	return &BlockWriter{w: w}, nil
+}
+
+func (bw *BlockWriter) Write(b libblock.Block) error {
+	data, err := b.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return bw.WriteData(data)
+}
+
+// WriteData writes a block already in binary form.
+func (bw *BlockWriter) WriteData(data []byte) error {
+	tag, ok := core.GetTag(data)
+	if !ok || tag != core.CORE_BLOCK {
+		return errors.New("error block data")
+	}
+	err := core.WriteRecord(bw.w, data)
+	if err != nil {
+		return err
+	}
+	bw.Count++
+	return nil
+}
+
+type BlockReader struct {
+	r       io.Reader
+	Version uint32
+	Count   uint64
+}
+
+// NewBlockReader reads and checks the header of a block stream from r.
+func NewBlockReader(r io.Reader) (*BlockReader, error) {
+	header, err := core.ReadRecord(r)
+	if err != nil {
+		return nil, err
+	}
+	l := len(blockStreamMagic)
+	if len(header) != l+4 || !bytes.Equal(header[:l], blockStreamMagic) {
+		return nil, errors.New("error block stream")
+	}
+	version := binary.LittleEndian.Uint32(header[l:])
+	if version == 0 || version > BlockStreamVersion {
+		return nil, fmt.Errorf("error block stream version %d", version)
+	}
+	return &BlockReader{r: r, Version: version}, nil
+}
+
+// Read returns the next block, io.EOF at the end of the stream.
+func (br *BlockReader) Read() (libblock.Block, error) {
+	data, err := br.ReadData()
+	if err != nil {
+		return nil, err
+	}
+	b := &Block{}
+	err = b.UnmarshalBinary(data)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// ReadData returns the next block in binary form, io.EOF at the end of the
+// stream.
+func (br *BlockReader) ReadData() ([]byte, error) {
+	data, err := core.ReadRecord(br.r)
+	if err != nil {
+		return nil, err
+	}
+	tag, ok := core.GetTag(data)
+	if !ok || tag != core.CORE_BLOCK {
+		return nil, fmt.Errorf("error block %d data", br.Count)
+	}
+	br.Count++
+	return data, nil
+}
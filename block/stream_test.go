@@ -0,0 +1,75 @@
+package block
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	. "github.com/tokentransfer/check"
+	libcore "github.com/tokentransfer/interfaces/core"
+)
+
+type StreamSuite struct{}
+
+func Test_Stream(t *testing.T) {
+	s := Suite(&StreamSuite{})
+	TestingRun(t, s)
+}
+
+func writeTestBlocks(c *C, n int) []byte {
+	var buf bytes.Buffer
+	bw, err := NewBlockWriter(&buf)
+	c.Assert(err, IsNil)
+	for i := 0; i < n; i++ {
+		err := bw.Write(&Block{
+			BlockIndex: uint64(i),
+			ParentHash: libcore.Hash([]byte{byte(i)}),
+			Timestamp:  int64(i),
+		})
+		c.Assert(err, IsNil)
+	}
+	c.Assert(bw.Count, Equals, uint64(n))
+	return buf.Bytes()
+}
+
+func (suite *StreamSuite) TestReadWrite(c *C) {
+	data := writeTestBlocks(c, 100)
+
+	br, err := NewBlockReader(iotest.HalfReader(bytes.NewReader(data)))
+	c.Assert(err, IsNil)
+	for i := 0; i < 100; i++ {
+		b, err := br.Read()
+		c.Assert(err, IsNil)
+		c.Assert(b.GetIndex(), Equals, uint64(i))
+	}
+	_, err = br.Read()
+	c.Assert(err, Equals, io.EOF)
+}
+
+func (suite *StreamSuite) TestCorrupted(c *C) {
+	data := writeTestBlocks(c, 2)
+
+	corrupted := append([]byte(nil), data...)
+	corrupted[len(corrupted)-6]++
+	br, err := NewBlockReader(bytes.NewReader(corrupted))
+	c.Assert(err, IsNil)
+	_, err = br.Read()
+	c.Assert(err, IsNil)
+	_, err = br.Read()
+	c.Assert(err, NotNil)
+
+	br, err = NewBlockReader(bytes.NewReader(data[:len(data)-2]))
+	c.Assert(err, IsNil)
+	_, err = br.Read()
+	c.Assert(err, IsNil)
+	_, err = br.Read()
+	c.Assert(err, Equals, io.ErrUnexpectedEOF)
+
+	_, err = NewBlockReader(bytes.NewReader([]byte{4, 0, 0, 0, 'n', 'o', 'n', 'e', 0, 0, 0, 0}))
+	c.Assert(err, NotNil)
+
+	huge := []byte{0xff, 0xff, 0xff, 0xff}
+	_, err = NewBlockReader(bytes.NewReader(huge))
+	c.Assert(err, NotNil)
+}
@@ -1,7 +1,9 @@
 package block
 
 import (
+	"encoding/binary"
 	"errors"
+	"fmt"
 	"log"
 
 	"github.com/tokentransfer/chain/account"
@@ -25,6 +27,15 @@ type Transaction struct {
 	Gas      int64
 	Type     string
 
+	// ChainID is the network the transaction is signed for, a transaction
+	// is only valid on the chain of the same id.
+	ChainID uint32
+
+	// ValidAfter and ValidUntil bound the blocks the transaction can be
+	// included in, see CheckValidity.
+	ValidAfter uint64
+	ValidUntil uint64
+
 	//Timestamp   int64
 	//Tags        []string
 	//Name        string
@@ -85,6 +96,9 @@ func (tx *Transaction) UnmarshalBinary(data []byte) error {
 	tx.Amount = t.Amount
 	tx.Gas = t.Gas
 	tx.Type = t.Type
+	tx.ChainID = t.ChainID
+	tx.ValidAfter = t.ValidAfter
+	tx.ValidUntil = t.ValidUntil
 
 	tx.Destination, err = byteToAddress(t.Destination)
 	if err != nil {
@@ -120,6 +134,9 @@ func (tx *Transaction) MarshalBinary() ([]byte, error) {
 		Amount:      tx.Amount,
 		Gas:         tx.Gas,
 		Type:        tx.Type,
+		ChainID:     tx.ChainID,
+		ValidAfter:  tx.ValidAfter,
+		ValidUntil:  tx.ValidUntil,
 		Destination: toData,
 		Payload:     tx.Payload,
 		PublicKey:   []byte(tx.PublicKey),
@@ -147,6 +164,9 @@ func (tx *Transaction) Raw(ignoreSigningFields bool) ([]byte, error) {
 			Amount:      tx.Amount,
 			Gas:         tx.Gas,
 			Type:        tx.Type,
+			ChainID:     tx.ChainID,
+			ValidAfter:  tx.ValidAfter,
+			ValidUntil:  tx.ValidUntil,
 			Destination: toData,
 			Payload:     tx.Payload,
 			PublicKey:   []byte(tx.PublicKey),
@@ -200,6 +220,38 @@ func (tx *Transaction) GetType() string {
 	return tx.Type
 }
 
+func (tx *Transaction) GetChainID() uint32 {
+	return tx.ChainID
+}
+
+// ValidityThreshold separates the two kinds of ValidAfter and ValidUntil
+// values, a value below it is a block index and a value from it a unix
+// timestamp in seconds. Zero leaves the bound open.
+const ValidityThreshold = uint64(500000000)
+
+func validityBound(v uint64, blockIndex uint64, timestamp int64) uint64 {
+	if v < ValidityThreshold {
+		return blockIndex
+	}
+	if timestamp < 0 {
+		return 0
+	}
+	return uint64(timestamp)
+}
+
+// CheckValidity returns ResultSuccess when the transaction can be included
+// in the block of index blockIndex and of time timestamp, ResultNotYetValid
+// before ValidAfter and ResultExpired after ValidUntil, both included.
+func (tx *Transaction) CheckValidity(blockIndex uint64, timestamp int64) libblock.TransactionResult {
+	if tx.ValidAfter > 0 && validityBound(tx.ValidAfter, blockIndex, timestamp) < tx.ValidAfter {
+		return ResultNotYetValid
+	}
+	if tx.ValidUntil > 0 && validityBound(tx.ValidUntil, blockIndex, timestamp) > tx.ValidUntil {
+		return ResultExpired
+	}
+	return ResultSuccess
+}
+
 //endregion
 
 //region Payment
@@ -237,6 +289,9 @@ func (tx *Payment) UnmarshalBinary(data []byte) error {
 	tx.Amount = t.Amount
 	tx.Gas = t.Gas
 	tx.Type = t.Type
+	tx.ChainID = t.ChainID
+	tx.ValidAfter = t.ValidAfter
+	tx.ValidUntil = t.ValidUntil
 	tx.Timestamp = t.Timestamp
 	tx.Tags = t.Tags
 	tx.Name = t.Name
@@ -278,6 +333,9 @@ func (tx *Payment) MarshalBinary() ([]byte, error) {
 		Value:       tx.Value,
 		Device:      tx.Device,
 		Type:        tx.Type,
+		ChainID:     tx.ChainID,
+		ValidAfter:  tx.ValidAfter,
+		ValidUntil:  tx.ValidUntil,
 		Destination: toData,
 		Payload:     tx.Payload,
 		PublicKey:   []byte(tx.PublicKey),
@@ -310,6 +368,9 @@ func (tx *Payment) Raw(ignoreSigningFields bool) ([]byte, error) {
 			Value:       tx.Value,
 			Device:      tx.Device,
 			Type:        tx.Type,
+			ChainID:     tx.ChainID,
+			ValidAfter:  tx.ValidAfter,
+			ValidUntil:  tx.ValidUntil,
 			Destination: toData,
 			Payload:     tx.Payload,
 			PublicKey:   []byte(tx.PublicKey),
@@ -319,6 +380,29 @@ func (tx *Payment) Raw(ignoreSigningFields bool) ([]byte, error) {
 	return tx.MarshalBinary()
 }
 
+// Apply moves the Amount of tx from the account state from to the account
+// state to, the states are the versions built for the block and may be the
+// same. It fails with a *ResultError ResultInsufficientBalance, on success
+// the Transfer event is appended to the logs of r.
+func (tx *Payment) Apply(from *AccountState, to *AccountState, r *Receipt) error {
+	if tx.Amount < 0 {
+		return &ResultError{Result: ResultMalformed, Message: fmt.Sprintf("amount %d", tx.Amount)}
+	}
+	if from.Amount < tx.Amount {
+		return &ResultError{Result: ResultInsufficientBalance, Message: fmt.Sprintf("balance %d, amount %d", from.Amount, tx.Amount)}
+	}
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, uint64(tx.Amount))
+	l, err := newLog(EventTransfer, data, nil, tx.Account, tx.Destination)
+	if err != nil {
+		return err
+	}
+	from.Amount -= tx.Amount
+	to.Amount += tx.Amount
+	r.Logs = append(r.Logs, l)
+	return nil
+}
+
 //endregion
 
 //region NewDevice
@@ -354,6 +438,9 @@ func (tx *NewDevice) UnmarshalBinary(data []byte) error {
 	tx.Amount = t.Amount
 	tx.Gas = t.Gas
 	tx.Type = t.Type
+	tx.ChainID = t.ChainID
+	tx.ValidAfter = t.ValidAfter
+	tx.ValidUntil = t.ValidUntil
 	tx.Symbol = t.Symbol
 	tx.Description = t.Description
 	tx.DeviceTags = t.DeviceTags
@@ -388,6 +475,9 @@ func (tx *NewDevice) MarshalBinary() ([]byte, error) {
 		Amount:      tx.Amount,
 		Gas:         tx.Gas,
 		Type:        tx.Type,
+		ChainID:     tx.ChainID,
+		ValidAfter:  tx.ValidAfter,
+		ValidUntil:  tx.ValidUntil,
 		Symbol:      tx.Symbol,
 		Description: tx.Description,
 		DeviceTags:  tx.DeviceTags,
@@ -418,6 +508,9 @@ func (tx *NewDevice) Raw(ignoreSigningFields bool) ([]byte, error) {
 			Amount:      tx.Amount,
 			Gas:         tx.Gas,
 			Type:        tx.Type,
+			ChainID:     tx.ChainID,
+			ValidAfter:  tx.ValidAfter,
+			ValidUntil:  tx.ValidUntil,
 			Symbol:      tx.Symbol,
 			Description: tx.Description,
 			DeviceTags:  tx.DeviceTags,
@@ -430,6 +523,35 @@ func (tx *NewDevice) Raw(ignoreSigningFields bool) ([]byte, error) {
 	return tx.MarshalBinary()
 }
 
+// Apply returns the first version of the device of tx owned by its sender,
+// s is the current state of the symbol, the device must not exist. It fails
+// with a *ResultError ResultDeviceExists, on success the DeviceRegistered
+// event is appended to the logs of r.
+func (tx *NewDevice) Apply(s *DeviceState, blockIndex uint64, r *Receipt) (*DeviceState, error) {
+	if len(tx.Symbol) == 0 {
+		return nil, &ResultError{Result: ResultMalformed, Message: "no symbol"}
+	}
+	if s != nil {
+		return nil, &ResultError{Result: ResultDeviceExists, Message: tx.Symbol}
+	}
+	l, err := newLog(EventDeviceRegistered, nil, []string{tx.Symbol}, tx.Account)
+	if err != nil {
+		return nil, err
+	}
+	r.Logs = append(r.Logs, l)
+	return &DeviceState{
+		State: State{
+			BlockIndex: blockIndex,
+			StateType:  libblock.StateType(core.CORE_DEVICE_STATE),
+		},
+		Account:     tx.Account,
+		Sequence:    1,
+		Symbol:      tx.Symbol,
+		Description: tx.Description,
+		Tags:        tx.DeviceTags,
+	}, nil
+}
+
 //endregion
 
 //region TransactionWithData
@@ -820,35 +942,38 @@ func (txWithData *NewDeviceWithData) Raw(ignoreSigningFields bool) ([]byte, erro
 
 //endregion
 
+// ReadTransaction decodes a transaction without its receipt.
+func ReadTransaction(data []byte) (libblock.Transaction, error) {
+	tag, ok := core.GetTag(data)
+	if !ok {
+		return nil, errors.New("error entry")
+	}
+	newTx, ok := getRawReader(tag)
+	if !ok {
+		return nil, errors.New("error read transaction")
+	}
+	tx := newTx()
+	err := tx.UnmarshalBinary(data)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
 func ReadTxWithData(data []byte) (libblock.TransactionWithData, error) {
-	if len(data) == 0 {
+	tag, ok := core.GetTag(data)
+	if !ok {
 		return nil, errors.New("error entry")
 	}
-	meta := data[0]
-	switch meta {
-	case core.CORE_TRANSACTION_WITH_DATA:
-		tx := &TransactionWithData{}
-		err := tx.UnmarshalBinary(data)
-		if err != nil {
-			return nil, err
-		}
-		return tx, nil
-	case core.CORE_PAYMENT_WITH_DATA:
-		tx := &PaymentWithData{}
-		err := tx.UnmarshalBinary(data)
-		if err != nil {
-			return nil, err
-		}
-		return tx, nil
-	case core.CORE_NEWDEVICE_WITH_DATA:
-		tx := &NewDeviceWithData{}
-		err := tx.UnmarshalBinary(data)
-		if err != nil {
-			return nil, err
-		}
-		return tx, nil
-	default:
+	newTx, ok := getTransactionReader(tag)
+	if !ok {
 		err := errors.New("error read txWithData")
 		return nil, err
 	}
+	tx := newTx()
+	err := tx.UnmarshalBinary(data)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
 }
@@ -1,9 +1,13 @@
 package block
 
 import (
+	"bytes"
 	"encoding/hex"
 	"testing"
 
+	"github.com/tokentransfer/chain/account"
+	"github.com/tokentransfer/chain/crypto"
+
 	. "github.com/tokentransfer/check"
 )
 
@@ -39,6 +43,47 @@ func (suite *TransactionSuite) TestTransactionBlob(c *C) {
 	// util.PrintJSON(">> tx", tx)
 	c.Assert(tx, NotNil)
 	c.Assert(tx.Sequence, Equals, uint64(1))
+	c.Assert(tx.ChainID, Equals, uint32(0))
+
+	legacy, err := tx.MarshalBinary()
+	c.Assert(err, IsNil)
+	c.Assert(bytes.Equal(legacy, data), Equals, true)
+}
+
+func (suite *TransactionSuite) TestChainID(c *C) {
+	blob := "65080112146DA68A0C5DAAE0715AE6B62F00F548A2C6981C2F1801280A321442F32B004DA1093D51AE40A58F38E33BA4F46397424104D33A199D322FAFD28867E3B9FB2F5AC081D56CFF1AE803635730E1D01B77D837D9EE578346DD88B68D21B9A61B8F1EFE9B2574F08B4A471F864FA7EA7A29185C4A413C76F5EBE03A949D0776192F50C2284A65ECED1EB2DCA1C37719A30C650598D57DA6E63598223A881545D612F9B20B1CAC507AA8EAC458A39A347F0CE8A9720B00"
+
+	data, err := hex.DecodeString(blob)
+	c.Assert(err, IsNil)
+	tx := &Transaction{}
+	err = tx.UnmarshalBinary(data)
+	c.Assert(err, IsNil)
+	unsigned, err := tx.Raw(true)
+	c.Assert(err, IsNil)
+
+	tx.ChainID = 2
+	signing, err := tx.Raw(true)
+	c.Assert(err, IsNil)
+	c.Assert(bytes.Equal(signing, unsigned), Equals, false)
+
+	data, err = tx.MarshalBinary()
+	c.Assert(err, IsNil)
+	p := &Payment{}
+	err = p.UnmarshalBinary(data)
+	c.Assert(err, NotNil)
+	newTx := &Transaction{}
+	err = newTx.UnmarshalBinary(data)
+	c.Assert(err, IsNil)
+	c.Assert(newTx.GetChainID(), Equals, uint32(2))
+
+	cs := &crypto.CryptoService{ChainID: 2}
+	c.Assert(cs.CheckChainID(newTx), IsNil)
+	ok, _ := cs.Verify(newTx)
+	c.Assert(ok, Equals, false)
+	cs.ChainID = 1
+	c.Assert(cs.CheckChainID(newTx), NotNil)
+	_, err = cs.Verify(newTx)
+	c.Assert(err, NotNil)
 }
 
 func (suite *TransactionSuite) TestTransaction(c *C) {
@@ -111,3 +156,42 @@ func (suite *TransactionSuite) TestTransaction(c *C) {
 	// util.PrintJSON("new tx", newTx)
 	// util.PrintJSON(fmt.Sprintf("%s:%d", h.String(), len(data)), libcore.Bytes(data))
 }
+
+func (suite *TransactionSuite) TestValidity(c *C) {
+	tx := &Transaction{ValidAfter: 10, ValidUntil: 20}
+	c.Assert(tx.CheckValidity(9, 0), Equals, ResultNotYetValid)
+	c.Assert(tx.CheckValidity(10, 0), Equals, ResultSuccess)
+	c.Assert(tx.CheckValidity(20, 0), Equals, ResultSuccess)
+	c.Assert(tx.CheckValidity(21, 0), Equals, ResultExpired)
+
+	tx = &Transaction{ValidUntil: 1700000000}
+	c.Assert(tx.CheckValidity(1000000000, 1700000000), Equals, ResultSuccess)
+	c.Assert(tx.CheckValidity(1, 1700000001), Equals, ResultExpired)
+	c.Assert((&Transaction{}).CheckValidity(1, 1), Equals, ResultSuccess)
+}
+
+func (suite *TransactionSuite) TestValidityFields(c *C) {
+	a := account.NewAddress()
+	err := a.UnmarshalText([]byte("0x42f32B004Da1093d51AE40a58F38E33BA4f46397"))
+	c.Assert(err, IsNil)
+
+	p := &Payment{
+		Transaction: Transaction{Account: a, Destination: a, ValidAfter: 5, ValidUntil: 1700000000},
+		Name:        "payment",
+	}
+	unsigned, err := p.Raw(true)
+	c.Assert(err, IsNil)
+	data, err := p.MarshalBinary()
+	c.Assert(err, IsNil)
+
+	newPayment := &Payment{}
+	err = newPayment.UnmarshalBinary(data)
+	c.Assert(err, IsNil)
+	c.Assert(newPayment.ValidAfter, Equals, uint64(5))
+	c.Assert(newPayment.ValidUntil, Equals, uint64(1700000000))
+
+	newPayment.ValidUntil++
+	signing, err := newPayment.Raw(true)
+	c.Assert(err, IsNil)
+	c.Assert(bytes.Equal(signing, unsigned), Equals, false)
+}
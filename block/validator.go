@@ -0,0 +1,516 @@
+package block
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"log"
+
+	"github.com/tokentransfer/chain/core"
+	"github.com/tokentransfer/chain/core/pb"
+
+	libblock "github.com/tokentransfer/interfaces/block"
+	libcore "github.com/tokentransfer/interfaces/core"
+)
+
+// The validator transactions change the list of the public keys allowed to
+// propose blocks, see consensus.PoA. Only a validator can send them, each one
+// is a vote and a change applies from the block after the one in which a
+// majority of the validators voted for it. Apply fails with a *ResultError.
+
+// IndexOfValidator returns the position of key in validators, -1 when it is
+// not a validator.
+func IndexOfValidator(validators []libcore.PublicKey, key libcore.PublicKey) int {
+	l := len(validators)
+	for i := 0; i < l; i++ {
+		if bytes.Equal(validators[i], key) {
+			return i
+		}
+	}
+	return -1
+}
+
+func checkValidatorSender(validators []libcore.PublicKey, tx *Transaction, key libcore.PublicKey) error {
+	if IndexOfValidator(validators, tx.PublicKey) < 0 {
+		return &ResultError{Result: ResultNotValidator, Message: hex.EncodeToString(tx.PublicKey)}
+	}
+	if len(key) == 0 {
+		return &ResultError{Result: ResultMalformed, Message: "no validator"}
+	}
+	return nil
+}
+
+//region AddValidator
+
+type AddValidator struct {
+	Transaction
+
+	Validator libcore.PublicKey
+}
+
+func (tx *AddValidator) UnmarshalBinary(data []byte) error {
+	var err error
+
+	meta, msg, err := core.Unmarshal(data)
+	if err != nil {
+		return err
+	}
+	if meta != core.CORE_ADDVALIDATOR {
+		return errors.New("error transaction add validator data")
+	}
+	t := msg.(*pb.AddValidator)
+
+	tx.TransactionType = libblock.TransactionType(t.TransactionType)
+
+	tx.Account, err = byteToAddress(t.Account)
+	if err != nil {
+		return err
+	}
+
+	tx.Sequence = t.Sequence
+	tx.Amount = t.Amount
+	tx.Gas = t.Gas
+	tx.Type = t.Type
+	tx.ChainID = t.ChainID
+	tx.ValidAfter = t.ValidAfter
+	tx.ValidUntil = t.ValidUntil
+	tx.Validator = libcore.PublicKey(t.Validator)
+
+	tx.Destination, err = byteToAddress(t.Destination)
+	if err != nil {
+		return err
+	}
+
+	tx.Payload = t.Payload
+	tx.PublicKey = libcore.PublicKey(t.PublicKey)
+	tx.Signature = libcore.Signature(t.Signature)
+
+	return nil
+}
+
+func (tx *AddValidator) MarshalBinary() ([]byte, error) {
+	fromData, err := addressToByte(tx.Account)
+	if err != nil {
+		return nil, err
+	}
+	toData, err := addressToByte(tx.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &pb.AddValidator{
+		TransactionType: uint32(tx.TransactionType),
+
+		Account:     fromData,
+		Sequence:    tx.Sequence,
+		Amount:      tx.Amount,
+		Gas:         tx.Gas,
+		Type:        tx.Type,
+		ChainID:     tx.ChainID,
+		ValidAfter:  tx.ValidAfter,
+		ValidUntil:  tx.ValidUntil,
+		Validator:   []byte(tx.Validator),
+		Destination: toData,
+		Payload:     tx.Payload,
+		PublicKey:   []byte(tx.PublicKey),
+		Signature:   []byte(tx.Signature),
+	}
+	return core.Marshal(t)
+}
+
+func (tx *AddValidator) Raw(ignoreSigningFields bool) ([]byte, error) {
+	fromData, err := addressToByte(tx.Account)
+	if err != nil {
+		return nil, err
+	}
+	toData, err := addressToByte(tx.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	if ignoreSigningFields {
+		t := &pb.AddValidator{
+			TransactionType: uint32(tx.TransactionType),
+
+			Account:     fromData,
+			Sequence:    tx.Sequence,
+			Amount:      tx.Amount,
+			Gas:         tx.Gas,
+			Type:        tx.Type,
+			ChainID:     tx.ChainID,
+			ValidAfter:  tx.ValidAfter,
+			ValidUntil:  tx.ValidUntil,
+			Validator:   []byte(tx.Validator),
+			Destination: toData,
+			Payload:     tx.Payload,
+			PublicKey:   []byte(tx.PublicKey),
+		}
+		return core.Marshal(t)
+	}
+	return tx.MarshalBinary()
+}
+
+// Apply returns the validators after tx, the new validator is appended.
+func (tx *AddValidator) Apply(validators []libcore.PublicKey) ([]libcore.PublicKey, error) {
+	err := checkValidatorSender(validators, &tx.Transaction, tx.Validator)
+	if err != nil {
+		return nil, err
+	}
+	if IndexOfValidator(validators, tx.Validator) >= 0 {
+		return nil, &ResultError{Result: ResultValidatorExists, Message: hex.EncodeToString(tx.Validator)}
+	}
+	next := make([]libcore.PublicKey, 0, len(validators)+1)
+	next = append(next, validators...)
+	return append(next, tx.Validator), nil
+}
+
+//endregion
+
+//region RemoveValidator
+
+type RemoveValidator struct {
+	Transaction
+
+	Validator libcore.PublicKey
+}
+
+func (tx *RemoveValidator) UnmarshalBinary(data []byte) error {
+	var err error
+
+	meta, msg, err := core.Unmarshal(data)
+	if err != nil {
+		return err
+	}
+	if meta != core.CORE_REMOVEVALIDATOR {
+		return errors.New("error transaction remove validator data")
+	}
+	t := msg.(*pb.RemoveValidator)
+
+	tx.TransactionType = libblock.TransactionType(t.TransactionType)
+
+	tx.Account, err = byteToAddress(t.Account)
+	if err != nil {
+		return err
+	}
+
+	tx.Sequence = t.Sequence
+	tx.Amount = t.Amount
+	tx.Gas = t.Gas
+	tx.Type = t.Type
+	tx.ChainID = t.ChainID
+	tx.ValidAfter = t.ValidAfter
+	tx.ValidUntil = t.ValidUntil
+	tx.Validator = libcore.PublicKey(t.Validator)
+
+	tx.Destination, err = byteToAddress(t.Destination)
+	if err != nil {
+		return err
+	}
+
+	tx.Payload = t.Payload
+	tx.PublicKey = libcore.PublicKey(t.PublicKey)
+	tx.Signature = libcore.Signature(t.Signature)
+
+	return nil
+}
+
+func (tx *RemoveValidator) MarshalBinary() ([]byte, error) {
+	fromData, err := addressToByte(tx.Account)
+	if err != nil {
+		return nil, err
+	}
+	toData, err := addressToByte(tx.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &pb.RemoveValidator{
+		TransactionType: uint32(tx.TransactionType),
+
+		Account:     fromData,
+		Sequence:    tx.Sequence,
+		Amount:      tx.Amount,
+		Gas:         tx.Gas,
+		Type:        tx.Type,
+		ChainID:     tx.ChainID,
+		ValidAfter:  tx.ValidAfter,
+		ValidUntil:  tx.ValidUntil,
+		Validator:   []byte(tx.Validator),
+		Destination: toData,
+		Payload:     tx.Payload,
+		PublicKey:   []byte(tx.PublicKey),
+		Signature:   []byte(tx.Signature),
+	}
+	return core.Marshal(t)
+}
+
+func (tx *RemoveValidator) Raw(ignoreSigningFields bool) ([]byte, error) {
+	fromData, err := addressToByte(tx.Account)
+	if err != nil {
+		return nil, err
+	}
+	toData, err := addressToByte(tx.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	if ignoreSigningFields {
+		t := &pb.RemoveValidator{
+			TransactionType: uint32(tx.TransactionType),
+
+			Account:     fromData,
+			Sequence:    tx.Sequence,
+			Amount:      tx.Amount,
+			Gas:         tx.Gas,
+			Type:        tx.Type,
+			ChainID:     tx.ChainID,
+			ValidAfter:  tx.ValidAfter,
+			ValidUntil:  tx.ValidUntil,
+			Validator:   []byte(tx.Validator),
+			Destination: toData,
+			Payload:     tx.Payload,
+			PublicKey:   []byte(tx.PublicKey),
+		}
+		return core.Marshal(t)
+	}
+	return tx.MarshalBinary()
+}
+
+// Apply returns the validators after tx, the last validator can not be
+// removed.
+func (tx *RemoveValidator) Apply(validators []libcore.PublicKey) ([]libcore.PublicKey, error) {
+	err := checkValidatorSender(validators, &tx.Transaction, tx.Validator)
+	if err != nil {
+		return nil, err
+	}
+	i := IndexOfValidator(validators, tx.Validator)
+	if i < 0 {
+		return nil, &ResultError{Result: ResultUnknownValidator, Message: hex.EncodeToString(tx.Validator)}
+	}
+	if len(validators) == 1 {
+		return nil, &ResultError{Result: ResultLastValidator, Message: hex.EncodeToString(tx.Validator)}
+	}
+	next := make([]libcore.PublicKey, 0, len(validators)-1)
+	next = append(next, validators[:i]...)
+	return append(next, validators[i+1:]...), nil
+}
+
+//endregion
+
+//region AddValidatorWithData
+
+type AddValidatorWithData struct {
+	TransactionWithData
+
+	Transaction libblock.Transaction
+	Receipt     libblock.Receipt
+}
+
+func (txWithData *AddValidatorWithData) GetHash() libcore.Hash {
+	return txWithData.Hash
+}
+
+func (txWithData *AddValidatorWithData) SetHash(h libcore.Hash) {
+	txWithData.Hash = h
+}
+
+func (txWithData *AddValidatorWithData) GetTransaction() libblock.Transaction {
+	return txWithData.Transaction
+}
+
+func (txWithData *AddValidatorWithData) GetReceipt() libblock.Receipt {
+	return txWithData.Receipt
+}
+
+func (txWithData *AddValidatorWithData) UnmarshalBinary(data []byte) error {
+	meta, msg, err := core.Unmarshal(data)
+	if err != nil {
+		return err
+	}
+	if meta != core.CORE_ADDVALIDATOR_WITH_DATA {
+		return errors.New("error add validator with data")
+	}
+
+	td := msg.(*pb.AddValidatorWithData)
+
+	txData, err := core.Marshal(td.Transaction)
+	if err != nil {
+		return err
+	}
+	tx := &AddValidator{}
+	err = tx.UnmarshalBinary(txData)
+	if err != nil {
+		return err
+	}
+	txWithData.Transaction = tx
+
+	receiptData, err := core.Marshal(td.Receipt)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	receipt := &Receipt{}
+	err = receipt.UnmarshalBinary(receiptData)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	txWithData.Receipt = receipt
+	return nil
+}
+
+func (txWithData *AddValidatorWithData) MarshalBinary() ([]byte, error) {
+	return txWithData.marshal(txWithData.Receipt.MarshalBinary, txWithData.Transaction.MarshalBinary)
+}
+
+func (txWithData *AddValidatorWithData) Raw(ignoreSigningFields bool) ([]byte, error) {
+	receiptRaw := func() ([]byte, error) {
+		return txWithData.Receipt.Raw(ignoreSigningFields)
+	}
+	txRaw := func() ([]byte, error) {
+		return txWithData.Transaction.Raw(ignoreSigningFields)
+	}
+	return txWithData.marshal(receiptRaw, txRaw)
+}
+
+func (txWithData *AddValidatorWithData) marshal(receiptData func() ([]byte, error), txData func() ([]byte, error)) ([]byte, error) {
+	data, err := receiptData()
+	if err != nil {
+		return nil, err
+	}
+	_, msg, err := core.Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	receipt := msg.(*pb.Receipt)
+
+	data, err = txData()
+	if err != nil {
+		return nil, err
+	}
+	_, msg, err = core.Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	tx, ok := msg.(*pb.AddValidator)
+	if !ok {
+		return nil, errors.New("error add validator data")
+	}
+
+	return core.Marshal(&pb.AddValidatorWithData{
+		Transaction: tx,
+		Receipt:     receipt,
+	})
+}
+
+//endregion
+
+//region RemoveValidatorWithData
+
+type RemoveValidatorWithData struct {
+	TransactionWithData
+
+	Transaction libblock.Transaction
+	Receipt     libblock.Receipt
+}
+
+func (txWithData *RemoveValidatorWithData) GetHash() libcore.Hash {
+	return txWithData.Hash
+}
+
+func (txWithData *RemoveValidatorWithData) SetHash(h libcore.Hash) {
+	txWithData.Hash = h
+}
+
+func (txWithData *RemoveValidatorWithData) GetTransaction() libblock.Transaction {
+	return txWithData.Transaction
+}
+
+func (txWithData *RemoveValidatorWithData) GetReceipt() libblock.Receipt {
+	return txWithData.Receipt
+}
+
+func (txWithData *RemoveValidatorWithData) UnmarshalBinary(data []byte) error {
+	meta, msg, err := core.Unmarshal(data)
+	if err != nil {
+		return err
+	}
+	if meta != core.CORE_REMOVEVALIDATOR_WITH_DATA {
+		return errors.New("error remove validator with data")
+	}
+
+	td := msg.(*pb.RemoveValidatorWithData)
+
+	txData, err := core.Marshal(td.Transaction)
+	if err != nil {
+		return err
+	}
+	tx := &RemoveValidator{}
+	err = tx.UnmarshalBinary(txData)
+	if err != nil {
+		return err
+	}
+	txWithData.Transaction = tx
+
+	receiptData, err := core.Marshal(td.Receipt)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	receipt := &Receipt{}
+	err = receipt.UnmarshalBinary(receiptData)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	txWithData.Receipt = receipt
+	return nil
+}
+
+func (txWithData *RemoveValidatorWithData) MarshalBinary() ([]byte, error) {
+	return txWithData.marshal(txWithData.Receipt.MarshalBinary, txWithData.Transaction.MarshalBinary)
+}
+
+func (txWithData *RemoveValidatorWithData) Raw(ignoreSigningFields bool) ([]byte, error) {
+	receiptRaw := func() ([]byte, error) {
+		return txWithData.Receipt.Raw(ignoreSigningFields)
+	}
+	txRaw := func() ([]byte, error) {
+		return txWithData.Transaction.Raw(ignoreSigningFields)
+	}
+	return txWithData.marshal(receiptRaw, txRaw)
+}
+
+func (txWithData *RemoveValidatorWithData) marshal(receiptData func() ([]byte, error), txData func() ([]byte, error)) ([]byte, error) {
+	data, err := receiptData()
+	if err != nil {
+		return nil, err
+	}
+	_, msg, err := core.Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	receipt := msg.(*pb.Receipt)
+
+	data, err = txData()
+	if err != nil {
+		return nil, err
+	}
+	_, msg, err = core.Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	tx, ok := msg.(*pb.RemoveValidator)
+	if !ok {
+		return nil, errors.New("error remove validator data")
+	}
+
+	return core.Marshal(&pb.RemoveValidatorWithData{
+		Transaction: tx,
+		Receipt:     receipt,
+	})
+}
+
+//endregion
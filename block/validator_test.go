@@ -0,0 +1,72 @@
+package block
+
+import (
+	"testing"
+
+	. "github.com/tokentransfer/check"
+	libcore "github.com/tokentransfer/interfaces/core"
+)
+
+type ValidatorSuite struct{}
+
+func Test_Validator(t *testing.T) {
+	s := Suite(&ValidatorSuite{})
+	TestingRun(t, s)
+}
+
+func (suite *ValidatorSuite) TestApply(c *C) {
+	owner := newTestAddress(c, "jngGY9W1F2Ky8wCzeHTahbtxjadU9wNFRz")
+	a := libcore.PublicKey{1}
+	b := libcore.PublicKey{2}
+	validators := []libcore.PublicKey{a}
+
+	add := &AddValidator{
+		Transaction: Transaction{Account: owner, Destination: owner, PublicKey: b},
+		Validator:   b,
+	}
+	_, err := add.Apply(validators)
+	c.Assert(err, NotNil)
+	c.Assert(err.(*ResultError).Result, Equals, ResultNotValidator)
+
+	add.PublicKey = a
+	next, err := add.Apply(validators)
+	c.Assert(err, IsNil)
+	c.Assert(next, DeepEquals, []libcore.PublicKey{a, b})
+	c.Assert(validators, DeepEquals, []libcore.PublicKey{a})
+	_, err = add.Apply(next)
+	c.Assert(err.(*ResultError).Result, Equals, ResultValidatorExists)
+
+	remove := &RemoveValidator{
+		Transaction: Transaction{Account: owner, Destination: owner, PublicKey: b},
+		Validator:   a,
+	}
+	last, err := remove.Apply(next)
+	c.Assert(err, IsNil)
+	c.Assert(last, DeepEquals, []libcore.PublicKey{b})
+	_, err = remove.Apply(last)
+	c.Assert(err.(*ResultError).Result, Equals, ResultUnknownValidator)
+	remove.Validator = b
+	_, err = remove.Apply(last)
+	c.Assert(err.(*ResultError).Result, Equals, ResultLastValidator)
+}
+
+func (suite *ValidatorSuite) TestWithData(c *C) {
+	owner := newTestAddress(c, "jngGY9W1F2Ky8wCzeHTahbtxjadU9wNFRz")
+
+	txWithData := &RemoveValidatorWithData{
+		Transaction: &RemoveValidator{
+			Transaction: Transaction{Account: owner, Destination: owner, Sequence: uint64(4)},
+			Validator:   libcore.PublicKey{1, 2, 3},
+		},
+		Receipt: &Receipt{},
+	}
+	data, err := txWithData.MarshalBinary()
+	c.Assert(err, IsNil)
+
+	tx, err := ReadTxWithData(data)
+	c.Assert(err, IsNil)
+	remove, ok := tx.GetTransaction().(*RemoveValidator)
+	c.Assert(ok, Equals, true)
+	c.Assert(remove.Validator, DeepEquals, libcore.PublicKey{1, 2, 3})
+	c.Assert(remove.Sequence, Equals, uint64(4))
+}
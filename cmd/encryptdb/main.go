@@ -0,0 +1,115 @@
+// Command encryptdb copies the databases of a data directory into a new one,
+// encrypting, decrypting or re-encrypting them with another key ring.
+// Key files hold one hex key per line, the last key is the active one, a
+// line "-" is a retired key which keeps the position of the others.
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"flag"
+	"log"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/tokentransfer/chain/store"
+
+	libstore "github.com/tokentransfer/interfaces/store"
+)
+
+var names = []string{"index", "block", "transaction", "state"}
+
+func readKeys(file string) ([][]byte, error) {
+	if len(file) == 0 {
+		return nil, nil
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	keys := make([][]byte, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if line == "-" {
+			keys = append(keys, nil)
+			continue
+		}
+		key, err := hex.DecodeString(line)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, scanner.Err()
+}
+
+func openStore(dir string, name string, keys [][]byte, encryptKeys bool) (libstore.KvService, error) {
+	var db libstore.KvService = &store.LevelService{Path: path.Join(dir, name)}
+	if len(keys) > 0 {
+		db = &store.EncryptedService{
+			Service:     db,
+			Keys:        keys,
+			EncryptKeys: encryptKeys,
+		}
+	}
+	err := db.Init(nil)
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func main() {
+	from := flag.String("from", "", "source data directory")
+	to := flag.String("to", "", "target data directory")
+	fromKeys := flag.String("from-keys", "", "key file of the source, empty if it is not encrypted")
+	toKeys := flag.String("to-keys", "", "key file of the target, empty to decrypt")
+	fromEncryptKeys := flag.Bool("from-encrypt-keys", false, "source keys are encrypted")
+	toEncryptKeys := flag.Bool("to-encrypt-keys", false, "encrypt the keys of the target")
+	flag.Parse()
+
+	if len(*from) == 0 || len(*to) == 0 || *from == *to {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	fk, err := readKeys(*fromKeys)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tk, err := readKeys(*toKeys)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, name := range names {
+		src, err := openStore(*from, name, fk, *fromEncryptKeys)
+		if err != nil {
+			log.Fatal(err)
+		}
+		dst, err := openStore(*to, name, tk, *toEncryptKeys)
+		if err != nil {
+			log.Fatal(err)
+		}
+		err = store.Migrate(src, dst)
+		if err != nil {
+			log.Fatal(err)
+		}
+		err = src.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+		err = dst.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Println("migrated", name)
+	}
+}
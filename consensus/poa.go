@@ -0,0 +1,312 @@
+package consensus
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/tokentransfer/chain/block"
+	"github.com/tokentransfer/chain/crypto"
+
+	libaccount "github.com/tokentransfer/interfaces/account"
+	libblock "github.com/tokentransfer/interfaces/block"
+	libcore "github.com/tokentransfer/interfaces/core"
+	libcrypto "github.com/tokentransfer/interfaces/crypto"
+)
+
+// PoA is a proof-of-authority engine, the validators take turns to propose
+// the blocks: block N is proposed by validator N modulo their number. A block
+// is signed by its proposer, see block.Block.PublicKey, the blocks signed by
+// another key are rejected. The genesis block is not signed, it is checked
+// against GenesisHash when it is set.
+//
+// The validators start with those of the genesis and are changed by the
+// successful block.AddValidator and block.RemoveValidator transactions, each
+// one signed by a validator is a vote for its change. A change applies from
+// the block after the one in which a majority of the validators voted for
+// it, the pending votes are dropped when a change applies. Apply must be
+// called with each block put, in order.
+type PoA struct {
+	// Validators are the public keys of the validators of the genesis.
+	Validators []libcore.PublicKey
+
+	// GenesisHash, when set, is the hash of the genesis block.
+	GenesisHash libcore.Hash
+
+	// Key is the key of the node, it is only needed to propose blocks.
+	Key libaccount.Key
+
+	CryptoService *crypto.CryptoService
+
+	locker *sync.RWMutex
+	sets   []validatorSet
+}
+
+// validatorSet are the validators from block from, and the voters of each
+// change which has not reached a majority.
+type validatorSet struct {
+	from       uint64
+	validators []libcore.PublicKey
+	votes      map[string][]libcore.PublicKey
+}
+
+func (e *PoA) Init(c libcore.Config) error {
+	l := len(e.Validators)
+	if l == 0 {
+		return errors.New("no validator")
+	}
+	for i := 0; i < l; i++ {
+		if len(e.Validators[i]) == 0 {
+			return fmt.Errorf("error validator %d", i)
+		}
+		if block.IndexOfValidator(e.Validators[:i], e.Validators[i]) >= 0 {
+			return fmt.Errorf("validator %d is duplicated", i)
+		}
+	}
+	if e.CryptoService == nil {
+		e.CryptoService = &crypto.CryptoService{}
+	}
+	validators := make([]libcore.PublicKey, l)
+	copy(validators, e.Validators)
+
+	e.locker = &sync.RWMutex{}
+	e.sets = []validatorSet{{from: 0, validators: validators}}
+	return nil
+}
+
+func (e *PoA) Start() error {
+	return nil
+}
+
+func (e *PoA) Close() error {
+	return nil
+}
+
+func (e *PoA) getSet(index uint64) validatorSet {
+	l := len(e.sets)
+	for i := l - 1; i > 0; i-- {
+		if e.sets[i].from <= index {
+			return e.sets[i]
+		}
+	}
+	return e.sets[0]
+}
+
+// GetValidators returns the validators of block index, as far as the blocks
+// applied tell.
+func (e *PoA) GetValidators(index uint64) []libcore.PublicKey {
+	e.locker.RLock()
+	defer e.locker.RUnlock()
+
+	validators := e.getSet(index).validators
+	ret := make([]libcore.PublicKey, len(validators))
+	copy(ret, validators)
+	return ret
+}
+
+// GetProposer returns the public key of the validator whose turn is block
+// index.
+func (e *PoA) GetProposer(index uint64) libcore.PublicKey {
+	e.locker.RLock()
+	defer e.locker.RUnlock()
+
+	validators := e.getSet(index).validators
+	return validators[index%uint64(len(validators))]
+}
+
+func (e *PoA) publicKey() (libcore.PublicKey, error) {
+	if e.Key == nil {
+		return nil, errors.New("no key")
+	}
+	p, err := e.Key.GetPublic()
+	if err != nil {
+		return nil, err
+	}
+	data, err := p.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return libcore.PublicKey(data), nil
+}
+
+// IsProposer returns true when it is the turn of the node to propose block
+// index.
+func (e *PoA) IsProposer(index uint64) (bool, error) {
+	key, err := e.publicKey()
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(key, e.GetProposer(index)), nil
+}
+
+// Seal signs b with Key, it fails when it is not the turn of the node.
+func (e *PoA) Seal(b *block.Block) error {
+	ok, err := e.IsProposer(b.GetIndex())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("error not proposer of block %d", b.GetIndex())
+	}
+	return e.CryptoService.Sign(e.Key, b)
+}
+
+// VerifyBlock returns an error when b is not signed by its proposer, or when
+// it is another genesis block than the one of GenesisHash.
+func (e *PoA) VerifyBlock(b libblock.Block) error {
+	if b.GetIndex() == 0 {
+		if len(e.GenesisHash) == 0 {
+			return nil
+		}
+		h, _, err := e.CryptoService.Raw(b, libcrypto.RawBinary)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(h, e.GenesisHash) {
+			return fmt.Errorf("error genesis block %s", h.String())
+		}
+		return nil
+	}
+	sb, ok := b.(*block.Block)
+	if !ok {
+		return errors.New("error block type")
+	}
+	proposer := e.GetProposer(b.GetIndex())
+	if !bytes.Equal(sb.PublicKey, proposer) {
+		return fmt.Errorf("error block %d is out of turn", b.GetIndex())
+	}
+	ok, err := e.CryptoService.Verify(sb)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("error signature of block %d", b.GetIndex())
+	}
+	return nil
+}
+
+// addVote returns votes with the vote of voter for change, votes is not
+// changed.
+func addVote(votes map[string][]libcore.PublicKey, change string, voter libcore.PublicKey) map[string][]libcore.PublicKey {
+	next := make(map[string][]libcore.PublicKey, len(votes)+1)
+	for k, v := range votes {
+		next[k] = v
+	}
+	voters := next[change]
+	if block.IndexOfValidator(voters, voter) < 0 {
+		next[change] = append(append([]libcore.PublicKey{}, voters...), voter)
+	}
+	return next
+}
+
+// applyChange returns validators with key added or removed, a change which
+// conflicts with one applied before in the same block is ignored.
+func applyChange(validators []libcore.PublicKey, add bool, key libcore.PublicKey) []libcore.PublicKey {
+	i := block.IndexOfValidator(validators, key)
+	if add {
+		if i >= 0 {
+			return validators
+		}
+		next := make([]libcore.PublicKey, 0, len(validators)+1)
+		next = append(next, validators...)
+		return append(next, key)
+	}
+	if i < 0 || len(validators) == 1 {
+		return validators
+	}
+	next := make([]libcore.PublicKey, 0, len(validators)-1)
+	next = append(next, validators[:i]...)
+	return append(next, validators[i+1:]...)
+}
+
+// Apply records the votes of the validator transactions of b and changes the
+// validators from the block after b when a change reaches a majority. The
+// failed transactions are skipped, as block.Executor checks them against the
+// validators of b, Apply fails when one which succeeded is not signed by its
+// sender or can not be applied to them. Applying a block again forgets the
+// votes and the changes of the blocks after it.
+func (e *PoA) Apply(b libblock.Block) error {
+	e.locker.Lock()
+	defer e.locker.Unlock()
+
+	index := b.GetIndex()
+	l := len(e.sets)
+	for l > 1 && e.sets[l-1].from > index {
+		l--
+	}
+	e.sets = e.sets[:l]
+
+	set := e.getSet(index)
+	validators := set.validators
+	votes := set.votes
+	applied := map[string]bool{}
+	changed := false
+	for _, txWithData := range b.GetTransactions() {
+		if block.TransactionError(txWithData) != nil {
+			continue
+		}
+		var add bool
+		var key libcore.PublicKey
+		var err error
+		tx := txWithData.GetTransaction()
+		switch t := tx.(type) {
+		case *block.AddValidator:
+			add, key = true, t.Validator
+			_, err = t.Apply(set.validators)
+		case *block.RemoveValidator:
+			key = t.Validator
+			_, err = t.Apply(set.validators)
+		default:
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("block %d: %s", index, err)
+		}
+		ok, err := e.CryptoService.Verify(tx)
+		if err != nil {
+			return fmt.Errorf("block %d: %s", index, err)
+		}
+		if !ok {
+			return fmt.Errorf("error signature of validator transaction of block %d", index)
+		}
+		changed = true
+
+		change := "remove@" + hex.EncodeToString(key)
+		if add {
+			change = "add@" + hex.EncodeToString(key)
+		}
+		if applied[change] {
+			continue
+		}
+		votes = addVote(votes, change, tx.GetPublicKey())
+		if 2*len(votes[change]) > len(set.validators) {
+			validators = applyChange(validators, add, key)
+			applied[change] = true
+		}
+	}
+	if len(applied) > 0 {
+		votes = nil
+	}
+	if changed {
+		e.sets = append(e.sets, validatorSet{from: index + 1, validators: validators, votes: votes})
+	}
+	return nil
+}
+
+// Load applies the blocks of a chain from the genesis to head, it is called
+// after Init when the chain is not empty.
+func (e *PoA) Load(head uint64, getBlock func(index uint64) (libblock.Block, error)) error {
+	for i := uint64(0); i <= head; i++ {
+		b, err := getBlock(i)
+		if err != nil {
+			return err
+		}
+		err = e.Apply(b)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package consensus
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/tokentransfer/chain/account"
+	"github.com/tokentransfer/chain/block"
+
+	. "github.com/tokentransfer/check"
+	libaccount "github.com/tokentransfer/interfaces/account"
+	libblock "github.com/tokentransfer/interfaces/block"
+	libcore "github.com/tokentransfer/interfaces/core"
+	libcrypto "github.com/tokentransfer/interfaces/crypto"
+)
+
+type PoASuite struct{}
+
+func Test_PoA(t *testing.T) {
+	s := Suite(&PoASuite{})
+	TestingRun(t, s)
+}
+
+func newTestKey(c *C, i int) (libaccount.Key, libcore.PublicKey) {
+	k, err := account.GenerateFamilySeed(fmt.Sprintf("validator%d", i))
+	c.Assert(err, IsNil)
+	p, err := k.GetPublic()
+	c.Assert(err, IsNil)
+	data, err := p.MarshalBinary()
+	c.Assert(err, IsNil)
+	return k, libcore.PublicKey(data)
+}
+
+func newTestEngine(c *C, key libaccount.Key, validators ...libcore.PublicKey) *PoA {
+	e := &PoA{Validators: validators, Key: key}
+	err := e.Init(nil)
+	c.Assert(err, IsNil)
+	return e
+}
+
+func (suite *PoASuite) TestRotation(c *C) {
+	k0, p0 := newTestKey(c, 0)
+	k1, p1 := newTestKey(c, 1)
+	e0 := newTestEngine(c, k0, p0, p1)
+	e1 := newTestEngine(c, k1, p0, p1)
+
+	for i := uint64(1); i < 5; i++ {
+		proposer, other := e0, e1
+		if i%2 == 1 {
+			proposer, other = e1, e0
+		}
+		ok, err := proposer.IsProposer(i)
+		c.Assert(err, IsNil)
+		c.Assert(ok, Equals, true)
+
+		b := &block.Block{BlockIndex: i, Timestamp: int64(i)}
+		err = other.Seal(b)
+		c.Assert(err, NotNil)
+		err = proposer.Seal(b)
+		c.Assert(err, IsNil)
+		err = other.VerifyBlock(b)
+		c.Assert(err, IsNil)
+
+		data, err := b.MarshalBinary()
+		c.Assert(err, IsNil)
+		received := &block.Block{}
+		err = received.UnmarshalBinary(data)
+		c.Assert(err, IsNil)
+		err = other.VerifyBlock(received)
+		c.Assert(err, IsNil)
+	}
+
+	out := &block.Block{BlockIndex: 2}
+	err := e0.CryptoService.Sign(k1, out)
+	c.Assert(err, IsNil)
+	err = e0.VerifyBlock(out)
+	c.Assert(err, NotNil)
+
+	forged := &block.Block{BlockIndex: 2}
+	err = e0.Seal(forged)
+	c.Assert(err, IsNil)
+	forged.Timestamp++
+	err = e1.VerifyBlock(forged)
+	c.Assert(err, NotNil)
+
+	err = e0.VerifyBlock(&block.Block{BlockIndex: 3})
+	c.Assert(err, NotNil)
+	err = e0.VerifyBlock(&block.Block{BlockIndex: 0})
+	c.Assert(err, IsNil)
+}
+
+func newValidatorTx(c *C, e *PoA, key libaccount.Key, add bool, validator libcore.PublicKey, result libblock.TransactionResult) libblock.TransactionWithData {
+	a, err := key.GetAddress()
+	c.Assert(err, IsNil)
+	tx := block.Transaction{Account: a, Destination: a}
+	receipt := &block.Receipt{TransactionResult: result}
+	if add {
+		t := &block.AddValidator{Transaction: tx, Validator: validator}
+		err = e.CryptoService.Sign(key, t)
+		c.Assert(err, IsNil)
+		return &block.AddValidatorWithData{Transaction: t, Receipt: receipt}
+	}
+	t := &block.RemoveValidator{Transaction: tx, Validator: validator}
+	err = e.CryptoService.Sign(key, t)
+	c.Assert(err, IsNil)
+	return &block.RemoveValidatorWithData{Transaction: t, Receipt: receipt}
+}
+
+func (suite *PoASuite) TestGovernance(c *C) {
+	k0, p0 := newTestKey(c, 0)
+	k1, p1 := newTestKey(c, 1)
+	k2, p2 := newTestKey(c, 2)
+	e := newTestEngine(c, k0, p0)
+
+	err := e.Apply(&block.Block{BlockIndex: 0})
+	c.Assert(err, IsNil)
+	b1 := &block.Block{
+		BlockIndex: 1,
+		Transactions: []libblock.TransactionWithData{
+			newValidatorTx(c, e, k0, true, p1, block.ResultSuccess),
+			newValidatorTx(c, e, k2, true, p2, block.ResultNotValidator),
+		},
+	}
+	err = e.Apply(b1)
+	c.Assert(err, IsNil)
+	c.Assert(e.GetValidators(1), DeepEquals, []libcore.PublicKey{p0})
+	c.Assert(e.GetValidators(2), DeepEquals, []libcore.PublicKey{p0, p1})
+	c.Assert(e.GetProposer(1), DeepEquals, p0)
+	c.Assert(e.GetProposer(3), DeepEquals, p1)
+
+	b2 := &block.Block{
+		BlockIndex: 2,
+		Transactions: []libblock.TransactionWithData{
+			newValidatorTx(c, e, k1, false, p0, block.ResultSuccess),
+			newValidatorTx(c, e, k0, false, p0, block.ResultSuccess),
+		},
+	}
+	err = e.Apply(b2)
+	c.Assert(err, IsNil)
+	c.Assert(e.GetValidators(3), DeepEquals, []libcore.PublicKey{p1})
+	ok, err := e.IsProposer(4)
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, false)
+
+	bad := &block.Block{
+		BlockIndex: 3,
+		Transactions: []libblock.TransactionWithData{
+			newValidatorTx(c, e, k2, true, p2, block.ResultSuccess),
+		},
+	}
+	err = e.Apply(bad)
+	c.Assert(err, NotNil)
+
+	err = e.Apply(b1)
+	c.Assert(err, IsNil)
+	c.Assert(e.GetValidators(5), DeepEquals, []libcore.PublicKey{p0, p1})
+
+	blocks := []libblock.Block{&block.Block{BlockIndex: 0}, b1, b2}
+	loaded := newTestEngine(c, k1, p0)
+	err = loaded.Load(2, func(index uint64) (libblock.Block, error) {
+		return blocks[index], nil
+	})
+	c.Assert(err, IsNil)
+	c.Assert(loaded.GetValidators(3), DeepEquals, []libcore.PublicKey{p1})
+	ok, err = loaded.IsProposer(3)
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, true)
+}
+
+func (suite *PoASuite) TestVotes(c *C) {
+	k0, p0 := newTestKey(c, 0)
+	k1, p1 := newTestKey(c, 1)
+	k2, p2 := newTestKey(c, 2)
+	k3, p3 := newTestKey(c, 3)
+	e := newTestEngine(c, k0, p0, p1, p2)
+	validators := []libcore.PublicKey{p0, p1, p2}
+
+	err := e.Apply(&block.Block{BlockIndex: 0})
+	c.Assert(err, IsNil)
+	b1 := &block.Block{
+		BlockIndex: 1,
+		Transactions: []libblock.TransactionWithData{
+			newValidatorTx(c, e, k0, true, p3, block.ResultSuccess),
+			newValidatorTx(c, e, k0, true, p3, block.ResultSuccess),
+		},
+	}
+	err = e.Apply(b1)
+	c.Assert(err, IsNil)
+	c.Assert(e.GetValidators(2), DeepEquals, validators)
+
+	b2 := &block.Block{
+		BlockIndex: 2,
+		Transactions: []libblock.TransactionWithData{
+			newValidatorTx(c, e, k1, true, p3, block.ResultSuccess),
+		},
+	}
+	err = e.Apply(b2)
+	c.Assert(err, IsNil)
+	c.Assert(e.GetValidators(2), DeepEquals, validators)
+	c.Assert(e.GetValidators(3), DeepEquals, []libcore.PublicKey{p0, p1, p2, p3})
+
+	err = e.Apply(b1)
+	c.Assert(err, IsNil)
+	c.Assert(e.GetValidators(3), DeepEquals, validators)
+
+	forged := newValidatorTx(c, e, k3, true, p3, block.ResultSuccess)
+	tx := forged.GetTransaction().(*block.AddValidator)
+	a, err := k1.GetAddress()
+	c.Assert(err, IsNil)
+	tx.Account = a
+	tx.PublicKey = p1
+	err = e.Apply(&block.Block{BlockIndex: 2, Transactions: []libblock.TransactionWithData{forged}})
+	c.Assert(err, NotNil)
+
+	unsigned := newValidatorTx(c, e, k2, true, p3, block.ResultSuccess)
+	unsigned.GetTransaction().(*block.AddValidator).Signature = nil
+	err = e.Apply(&block.Block{BlockIndex: 2, Transactions: []libblock.TransactionWithData{unsigned}})
+	c.Assert(err, NotNil)
+	c.Assert(e.GetValidators(3), DeepEquals, validators)
+}
+
+func (suite *PoASuite) TestGenesis(c *C) {
+	k0, p0 := newTestKey(c, 0)
+	e := newTestEngine(c, k0, p0)
+	genesis := &block.Block{BlockIndex: 0, Timestamp: 1}
+	h, _, err := e.CryptoService.Raw(genesis, libcrypto.RawBinary)
+	c.Assert(err, IsNil)
+	e.GenesisHash = h
+	err = e.VerifyBlock(genesis)
+	c.Assert(err, IsNil)
+	err = e.VerifyBlock(&block.Block{BlockIndex: 0, Timestamp: 2})
+	c.Assert(err, NotNil)
+}
+
+func (suite *PoASuite) TestInit(c *C) {
+	_, p0 := newTestKey(c, 0)
+	e := &PoA{}
+	err := e.Init(nil)
+	c.Assert(err, NotNil)
+	e = &PoA{Validators: []libcore.PublicKey{p0, p0}}
+	err = e.Init(nil)
+	c.Assert(err, NotNil)
+	e = &PoA{Validators: []libcore.PublicKey{p0}}
+	err = e.Init(nil)
+	c.Assert(err, IsNil)
+	_, err = e.IsProposer(1)
+	c.Assert(err, NotNil)
+}
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"io"
 
-	"github.com/tokentransfer/chain/core/pb"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -19,54 +18,57 @@ const (
 	CORE_PAYMENT_WITH_DATA     = byte(106)
 	CORE_NEWDEVICE_WITH_DATA   = byte(107)
 
+	CORE_UPDATEDEVICE             = byte(130)
+	CORE_TRANSFERDEVICE           = byte(131)
+	CORE_RETIREDEVICE             = byte(132)
+	CORE_UPDATEDEVICE_WITH_DATA   = byte(133)
+	CORE_TRANSFERDEVICE_WITH_DATA = byte(134)
+	CORE_RETIREDEVICE_WITH_DATA   = byte(135)
+
 	// CORE_STATE         = byte(110)
 	CORE_ACCOUNT_STATE  = byte(111)
 	CORE_CURRENCY_STATE = byte(112)
 	CORE_DEVICE_STATE   = byte(113)
 
+	CORE_PROOF           = byte(120)
+	CORE_SNAPSHOT_HEADER = byte(121)
+	CORE_SNAPSHOT_CHUNK  = byte(122)
+	CORE_BLOCK_DIFF      = byte(123)
+
+	CORE_HELLO       = byte(140)
+	CORE_GET_HEADERS = byte(141)
+	CORE_HEADERS     = byte(142)
+	CORE_GET_BLOCKS  = byte(143)
+	CORE_BLOCKS      = byte(144)
+
+	CORE_ADDVALIDATOR              = byte(150)
+	CORE_REMOVEVALIDATOR           = byte(151)
+	CORE_ADDVALIDATOR_WITH_DATA    = byte(152)
+	CORE_REMOVEVALIDATOR_WITH_DATA = byte(153)
+
 	CORE_PAYMENT_TYPE      = byte(201)
 	CORE_NEW_CURRENCY_TYPE = byte(202)
 	CORE_NEW_DEVICE_TYPE   = byte(203)
+
+	CORE_UPDATE_DEVICE_TYPE   = byte(204)
+	CORE_TRANSFER_DEVICE_TYPE = byte(205)
+	CORE_RETIRE_DEVICE_TYPE   = byte(206)
+
+	CORE_ADD_VALIDATOR_TYPE    = byte(207)
+	CORE_REMOVE_VALIDATOR_TYPE = byte(208)
 )
 
 func GetInfo(data []byte) string {
 	if len(data) > 0 {
-		meta := data[0]
-		switch meta {
-		case CORE_BLOCK:
-			return "block"
-		case CORE_TRANSACTION:
-			return "transaction"
-		case CORE_PAYMENT:
-			return "payment"
-		case CORE_NEWDEVICE:
-			return "new_device"
-		case CORE_RECEIPT:
-			return "receipt"
-		case CORE_TRANSACTION_WITH_DATA:
-			return "transaction_with_data"
-		case CORE_PAYMENT_WITH_DATA:
-			return "payment_with_data"
-		case CORE_NEWDEVICE_WITH_DATA:
-			return "newdevice_with_data"
-
-		case CORE_ACCOUNT_STATE:
-			return "account_state"
-		case CORE_CURRENCY_STATE:
-			return "currency_state"
-		case CORE_DEVICE_STATE:
-			return "device_state"
-
-		case CORE_PAYMENT_TYPE:
-			return "payment_type"
-		case CORE_NEW_CURRENCY_TYPE:
-			return "new_currency_type"
-		case CORE_NEW_DEVICE_TYPE:
-			return "new_device_type"
-
-		default:
+		tag, ok := GetTag(data)
+		if !ok {
+			return "unknown"
+		}
+		t, ok := GetType(tag)
+		if !ok {
 			return "unknown"
 		}
+		return t.Name
 	}
 	return ""
 }
@@ -84,87 +86,31 @@ func Clone(t proto.Message) (proto.Message, error) {
 }
 
 func Marshal(message proto.Message) ([]byte, error) {
-	var meta byte
-	switch message.(type) {
-	case *pb.Block:
-		meta = CORE_BLOCK
-	case *pb.Transaction:
-		meta = CORE_TRANSACTION
-	case *pb.Payment:
-		meta = CORE_PAYMENT
-	case *pb.NewDevice:
-		meta = CORE_NEWDEVICE
-	case *pb.Receipt:
-		meta = CORE_RECEIPT
-	case *pb.TransactionWithData:
-		meta = CORE_TRANSACTION_WITH_DATA
-	case *pb.PaymentWithData:
-		meta = CORE_PAYMENT_WITH_DATA
-	case *pb.NewDeviceWithData:
-		meta = CORE_NEWDEVICE_WITH_DATA
-
-	case *pb.AccountState:
-		meta = CORE_ACCOUNT_STATE
-	case *pb.CurrencyState:
-		meta = CORE_CURRENCY_STATE
-	case *pb.DeviceState:
-		meta = CORE_DEVICE_STATE
-
-	default:
+	meta, ok := getTag(message)
+	if !ok {
 		err := errors.New("error data type")
 		return nil, err
 	}
-	data, err := proto.Marshal(message)
-	if err != nil {
-		return nil, err
-	}
-	return append([]byte{meta}, data...), nil
+	t, _ := GetType(meta)
+	return MarshalVersion(message, t.Version)
 }
 
 func Unmarshal(data []byte) (byte, proto.Message, error) {
-	meta := data[0]
-	bs := data[1:]
-
-	var msg proto.Message
-	switch meta {
-	case CORE_BLOCK:
-		msg = &pb.Block{}
-	case CORE_TRANSACTION:
-		msg = &pb.Transaction{}
-	case CORE_PAYMENT:
-		msg = &pb.Payment{}
-	case CORE_NEWDEVICE:
-		msg = &pb.NewDevice{}
-	case CORE_RECEIPT:
-		msg = &pb.Receipt{}
-	case CORE_TRANSACTION_WITH_DATA:
-		msg = &pb.TransactionWithData{}
-	case CORE_PAYMENT_WITH_DATA:
-		msg = &pb.PaymentWithData{}
-	case CORE_NEWDEVICE_WITH_DATA:
-		msg = &pb.NewDeviceWithData{}
-
-	case CORE_ACCOUNT_STATE:
-		msg = &pb.AccountState{}
-	case CORE_CURRENCY_STATE:
-		msg = &pb.CurrencyState{}
-	case CORE_DEVICE_STATE:
-		msg = &pb.DeviceState{}
-
-	default:
-		err := errors.New("error data format")
-		return 0, nil, err
-	}
-
-	err := proto.Unmarshal(bs, msg)
+	meta, _, msg, err := UnmarshalVersion(data)
 	if err != nil {
 		return 0, nil, err
 	}
 	return meta, msg, nil
 }
 
+// MaxBytesSize is the largest length accepted by ReadBytes and ReadRecord.
+const MaxBytesSize = 64 << 20
+
 func WriteBytes(w io.Writer, b []byte) error {
 	l := len(b)
+	if l > MaxBytesSize {
+		return errors.New("error write size")
+	}
 	err := binary.Write(w, binary.LittleEndian, uint32(l))
 	if err != nil {
 		return err
@@ -182,20 +128,29 @@ func WriteBytes(w io.Writer, b []byte) error {
 }
 
 func ReadBytes(r io.Reader) ([]byte, error) {
+	return ReadLimitedBytes(r, MaxBytesSize)
+}
+
+// ReadLimitedBytes is ReadBytes refusing a length over limit before the data
+// is allocated.
+func ReadLimitedBytes(r io.Reader, limit int) ([]byte, error) {
 	l := uint32(0)
 	err := binary.Read(r, binary.LittleEndian, &l)
 	if err != nil {
 		return nil, err
 	}
+	if uint64(l) > uint64(limit) || l > MaxBytesSize {
+		return nil, errors.New("error read size")
+	}
 	b := make([]byte, l)
 	if l > 0 {
-		n, err := r.Read(b)
+		_, err := io.ReadFull(r, b)
 		if err != nil {
+			if err == io.EOF {
+				return nil, io.ErrUnexpectedEOF
+			}
 			return nil, err
 		}
-		if n != int(l) {
-			return nil, errors.New("error read")
-		}
 	}
 	return b, nil
 }
@@ -0,0 +1,139 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/tokentransfer/chain/core/pb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Type binds a tag byte to a name and to the protobuf message encoded after
+// the tag, New is nil for the tags which only have a name. Version is the
+// version new data of the type is written at, see MarshalVersion.
+type Type struct {
+	Tag     byte
+	Name    string
+	New     func() proto.Message
+	Version byte
+}
+
+var (
+	types       = map[byte]Type{}
+	tags        = map[protoreflect.FullName]byte{}
+	typesLocker = &sync.RWMutex{}
+)
+
+// RegisterType registers a tag, a tag and a message can be registered only
+// once.
+func RegisterType(t Type) error {
+	return RegisterTypes(t)
+}
+
+// RegisterTypes registers the tags of list, none of them is registered when
+// one of them can not be.
+func RegisterTypes(list ...Type) error {
+	typesLocker.Lock()
+	defer typesLocker.Unlock()
+
+	newTags := make(map[byte]bool)
+	names := make(map[protoreflect.FullName]byte)
+	for _, t := range list {
+		_, ok := types[t.Tag]
+		if ok || newTags[t.Tag] {
+			return fmt.Errorf("type %d already registered", t.Tag)
+		}
+		if len(t.Name) == 0 {
+			return fmt.Errorf("type %d has no name", t.Tag)
+		}
+		if t.Tag == CORE_VERSIONED {
+			return fmt.Errorf("type %d is reserved", t.Tag)
+		}
+		if t.New != nil {
+			name := t.New().ProtoReflect().Descriptor().FullName()
+			tag, ok := tags[name]
+			if !ok {
+				tag, ok = names[name]
+			}
+			if ok {
+				return fmt.Errorf("message %s already registered as type %d", name, tag)
+			}
+			names[name] = t.Tag
+		}
+		newTags[t.Tag] = true
+	}
+	for name, tag := range names {
+		tags[name] = tag
+	}
+	for _, t := range list {
+		types[t.Tag] = t
+	}
+	return nil
+}
+
+func GetType(tag byte) (Type, bool) {
+	typesLocker.RLock()
+	defer typesLocker.RUnlock()
+
+	t, ok := types[tag]
+	return t, ok
+}
+
+// Types returns the registered types ordered by tag.
+func Types() []Type {
+	typesLocker.RLock()
+	defer typesLocker.RUnlock()
+
+	list := make([]Type, 0, len(types))
+	for _, t := range types {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Tag < list[j].Tag
+	})
+	return list
+}
+
+func getTag(message proto.Message) (byte, bool) {
+	typesLocker.RLock()
+	defer typesLocker.RUnlock()
+
+	tag, ok := tags[message.ProtoReflect().Descriptor().FullName()]
+	return tag, ok
+}
+
+func init() {
+	// the transactions and states are registered by the block package
+	list := []Type{
+		{Tag: CORE_BLOCK, Name: "block", New: func() proto.Message { return &pb.Block{} }},
+		{Tag: CORE_RECEIPT, Name: "receipt", New: func() proto.Message { return &pb.Receipt{} }},
+
+		{Tag: CORE_PROOF, Name: "proof", New: func() proto.Message { return &pb.Proof{} }},
+		{Tag: CORE_SNAPSHOT_HEADER, Name: "snapshot_header", New: func() proto.Message { return &pb.SnapshotHeader{} }},
+		{Tag: CORE_SNAPSHOT_CHUNK, Name: "snapshot_chunk", New: func() proto.Message { return &pb.SnapshotChunk{} }},
+		{Tag: CORE_BLOCK_DIFF, Name: "block_diff", New: func() proto.Message { return &pb.BlockDiff{} }},
+
+		{Tag: CORE_HELLO, Name: "hello", New: func() proto.Message { return &pb.Hello{} }},
+		{Tag: CORE_GET_HEADERS, Name: "get_headers", New: func() proto.Message { return &pb.GetHeaders{} }},
+		{Tag: CORE_HEADERS, Name: "headers", New: func() proto.Message { return &pb.Headers{} }},
+		{Tag: CORE_GET_BLOCKS, Name: "get_blocks", New: func() proto.Message { return &pb.GetBlocks{} }},
+		{Tag: CORE_BLOCKS, Name: "blocks", New: func() proto.Message { return &pb.Blocks{} }},
+
+		{Tag: CORE_PAYMENT_TYPE, Name: "payment_type"},
+		{Tag: CORE_NEW_CURRENCY_TYPE, Name: "new_currency_type"},
+		{Tag: CORE_NEW_DEVICE_TYPE, Name: "new_device_type"},
+		{Tag: CORE_UPDATE_DEVICE_TYPE, Name: "update_device_type"},
+		{Tag: CORE_TRANSFER_DEVICE_TYPE, Name: "transfer_device_type"},
+		{Tag: CORE_RETIRE_DEVICE_TYPE, Name: "retire_device_type"},
+		{Tag: CORE_ADD_VALIDATOR_TYPE, Name: "add_validator_type"},
+		{Tag: CORE_REMOVE_VALIDATOR_TYPE, Name: "remove_validator_type"},
+	}
+	for _, t := range list {
+		err := RegisterType(t)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
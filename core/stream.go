@@ -0,0 +1,48 @@
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+)
+
+// A record is the length of its data, the data and the CRC-32 (Castagnoli)
+// of the data, the length and the checksum are little endian.
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+func WriteRecord(w io.Writer, data []byte) error {
+	err := WriteBytes(w, data)
+	if err != nil {
+		return err
+	}
+	return binary.Write(w, binary.LittleEndian, crc32.Checksum(data, crcTable))
+}
+
+// ReadRecord returns io.EOF when r ends before a record and
+// io.ErrUnexpectedEOF when it ends inside a record.
+func ReadRecord(r io.Reader) ([]byte, error) {
+	return ReadLimitedRecord(r, MaxBytesSize)
+}
+
+// ReadLimitedRecord is ReadRecord refusing a record longer than limit, see
+// ReadLimitedBytes.
+func ReadLimitedRecord(r io.Reader, limit int) ([]byte, error) {
+	data, err := ReadLimitedBytes(r, limit)
+	if err != nil {
+		return nil, err
+	}
+	sum := uint32(0)
+	err = binary.Read(r, binary.LittleEndian, &sum)
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if sum != crc32.Checksum(data, crcTable) {
+		return nil, errors.New("error record checksum")
+	}
+	return data, nil
+}
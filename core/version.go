@@ -0,0 +1,101 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Versioned data is the CORE_VERSIONED byte, the version, the tag and the
+// protobuf message: [CORE_VERSIONED][version][tag][message]. Data written
+// before versions existed is the tag and the message and is version 0, it is
+// still written for the types at version 0 so their hashes do not change.
+//
+// The rules to change a message of a registered type:
+//   - only add fields, with new field numbers, never reuse, renumber or
+//     change the type of a field, reserve the numbers of removed fields
+//   - the fields common to the transactions are numbered 1-10, new common
+//     fields use 50 and above, the fields of one transaction 11-49
+//   - bump the Version of the type when a field is added, the data written
+//     before keeps its version and its hash
+//   - a change of meaning of an existing field needs a new tag
+//
+// Decoding accepts every version, newer ones included. The block types keep
+// only the fields they know and are encoded at the Version of their type, 0
+// for all of them, so an object decoded from data of another version is not
+// encoded back to the same data. The hashes of the objects are computed by
+// crypto.CryptoService.Raw on their encoding, not on the data they were
+// decoded from: data of another version gets the hash of the object it
+// decodes to, the hash of the data itself is computed on the data.
+
+const CORE_VERSIONED = byte(1)
+
+// GetTag returns the tag of data, versioned or not.
+func GetTag(data []byte) (byte, bool) {
+	if len(data) == 0 {
+		return 0, false
+	}
+	if data[0] != CORE_VERSIONED {
+		return data[0], true
+	}
+	if len(data) < 3 {
+		return 0, false
+	}
+	return data[2], true
+}
+
+// GetVersion returns the version of data, 0 for data without a version.
+func GetVersion(data []byte) byte {
+	if len(data) >= 3 && data[0] == CORE_VERSIONED {
+		return data[1]
+	}
+	return 0
+}
+
+// MarshalVersion encodes message at version, version 0 is the encoding
+// without a version.
+func MarshalVersion(message proto.Message, version byte) ([]byte, error) {
+	meta, ok := getTag(message)
+	if !ok {
+		err := errors.New("error data type")
+		return nil, err
+	}
+	data, err := proto.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	if version == 0 {
+		return append([]byte{meta}, data...), nil
+	}
+	return append([]byte{CORE_VERSIONED, version, meta}, data...), nil
+}
+
+// UnmarshalVersion decodes data and returns its tag and its version.
+func UnmarshalVersion(data []byte) (byte, byte, proto.Message, error) {
+	meta, ok := GetTag(data)
+	if !ok {
+		return 0, 0, nil, errors.New("error data")
+	}
+	version := GetVersion(data)
+	bs := data[1:]
+	if data[0] == CORE_VERSIONED {
+		if version == 0 {
+			return 0, 0, nil, fmt.Errorf("error data version %d", version)
+		}
+		bs = data[3:]
+	}
+
+	t, ok := GetType(meta)
+	if !ok || t.New == nil {
+		err := errors.New("error data format")
+		return 0, 0, nil, err
+	}
+	msg := t.New()
+
+	err := proto.Unmarshal(bs, msg)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	return meta, version, msg, nil
+}
@@ -13,6 +13,32 @@ import (
 )
 
 type CryptoService struct {
+	// ChainID is the id of the network, Verify rejects the transactions
+	// signed for another chain.
+	ChainID uint32
+}
+
+type chainSignable interface {
+	GetChainID() uint32
+}
+
+// CheckChainID returns an error when s is signed for another chain than
+// chainID.
+func CheckChainID(s libcrypto.Signable, chainID uint32) error {
+	c, ok := s.(chainSignable)
+	if !ok {
+		return nil
+	}
+	if c.GetChainID() != chainID {
+		return fmt.Errorf("error chain id %d, expected %d", c.GetChainID(), chainID)
+	}
+	return nil
+}
+
+// CheckChainID returns an error when s is signed for another chain than the
+// chain of the service.
+func (service *CryptoService) CheckChainID(s libcrypto.Signable) error {
+	return CheckChainID(s, service.ChainID)
 }
 
 func (service *CryptoService) GetSize() int {
@@ -26,6 +52,9 @@ func (service *CryptoService) Hash(msg []byte) (libcore.Hash, error) {
 	return libcore.Hash(b), nil
 }
 
+// Raw returns the hash of h, computed on h.Raw(false), and its encoding of
+// type rt, both are encodings of the object and not the data it was decoded
+// from, see core.MarshalVersion.
 func (service *CryptoService) Raw(h libcrypto.Hashable, rt libcrypto.RawType) (libcore.Hash, []byte, error) {
 	var rawData []byte
 	var err error
@@ -89,10 +118,14 @@ func (service *CryptoService) Sign(p libaccount.Key, s libcrypto.Signable) error
 }
 
 func (service *CryptoService) Verify(s libcrypto.Signable) (bool, error) {
+	err := service.CheckChainID(s)
+	if err != nil {
+		return false, err
+	}
 	publicBytes := []byte(s.GetPublicKey())
 
 	p := account.NewPublicKey()
-	err := p.UnmarshalBinary(publicBytes)
+	err = p.UnmarshalBinary(publicBytes)
 	if err != nil {
 		return false, err
 	}
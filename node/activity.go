@@ -0,0 +1,228 @@
+package node
+
+import (
+	"fmt"
+
+	libblock "github.com/tokentransfer/interfaces/block"
+	libcore "github.com/tokentransfer/interfaces/core"
+	libcrypto "github.com/tokentransfer/interfaces/crypto"
+)
+
+type Direction int
+
+const (
+	DirectionAll Direction = iota
+	DirectionSent
+	DirectionReceived
+)
+
+const DefaultListLimit = 100
+
+// The transactions of an address are listed in the order they are first
+// put, for each direction "activity@dir@address" holds the length of the
+// list, "activity@dir@address:N" the hash of its Nth transaction and
+// "activity@dir@address@hash" the position of the transaction of hash hash,
+// which is listed once even when it is put again with a block.
+
+func getActivityKey(dir Direction, address string) string {
+	return fmt.Sprintf("activity@%d@%s", dir, address)
+}
+
+func getDirections(tx libblock.Transaction) (map[string][]Direction, error) {
+	dirs := make(map[string][]Direction)
+	from, err := tx.GetAccount().GetAddress()
+	if err != nil {
+		return nil, err
+	}
+	dirs[from] = []Direction{DirectionAll, DirectionSent}
+
+	destination := tx.GetDestination()
+	if destination == nil {
+		return dirs, nil
+	}
+	to, err := destination.GetAddress()
+	if err != nil {
+		return nil, err
+	}
+	if to == from {
+		dirs[from] = append(dirs[from], DirectionReceived)
+	} else {
+		dirs[to] = []Direction{DirectionAll, DirectionReceived}
+	}
+	return dirs, nil
+}
+
+func (service *MerkleService) getCount(key string) (uint64, error) {
+	data, err := service.im.GetData([]byte(key))
+	if err != nil {
+		return 0, err
+	}
+	count, _ := getUint64(data)
+	return count, nil
+}
+
+// putActivity appends the transaction tx of hash txHash to the lists of its
+// addresses, unless it is already listed.
+func (service *MerkleService) putActivity(tx libblock.Transaction, txHash libcore.Hash) error {
+	dirs, err := getDirections(tx)
+	if err != nil {
+		return err
+	}
+	for address, list := range dirs {
+		for _, dir := range list {
+			key := getActivityKey(dir, address)
+			posKey := []byte(getHashKey(key, txHash))
+			pos, err := service.im.GetData(posKey)
+			if err != nil {
+				return err
+			}
+			if len(pos) > 0 {
+				continue
+			}
+			count, err := service.getCount(key)
+			if err != nil {
+				return err
+			}
+			err = service.im.PutData([]byte(getIndexKey(key, count)), txHash)
+			if err != nil {
+				return err
+			}
+			err = service.im.PutData(posKey, putUint64(count))
+			if err != nil {
+				return err
+			}
+			err = service.im.PutData([]byte(key), putUint64(count+1))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// removeActivity removes the transaction tx of hash txHash from the lists,
+// the transactions listed after it move up by one.
+func (service *MerkleService) removeActivity(tx libblock.Transaction, txHash libcore.Hash) error {
+	dirs, err := getDirections(tx)
+	if err != nil {
+		return err
+	}
+	for address, list := range dirs {
+		for _, dir := range list {
+			key := getActivityKey(dir, address)
+			posKey := getHashKey(key, txHash)
+			data, err := service.im.GetData([]byte(posKey))
+			if err != nil {
+				return err
+			}
+			pos, ok := getUint64(data)
+			if !ok {
+				continue
+			}
+			count, err := service.getCount(key)
+			if err != nil {
+				return err
+			}
+			for i := pos + 1; i < count; i++ {
+				h, err := service.im.GetData([]byte(getIndexKey(key, i)))
+				if err != nil {
+					return err
+				}
+				err = service.im.PutData([]byte(getIndexKey(key, i-1)), h)
+				if err != nil {
+					return err
+				}
+				err = service.im.PutData([]byte(getHashKey(key, libcore.Hash(h))), putUint64(i-1))
+				if err != nil {
+					return err
+				}
+			}
+			err = service.removeIndex(getIndexKey(key, count-1))
+			if err != nil {
+				return err
+			}
+			err = service.removeIndex(posKey)
+			if err != nil {
+				return err
+			}
+			err = service.im.PutData([]byte(key), putUint64(count-1))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ListTransactions returns at most limit transactions of address in the
+// direction dir in the order they were first put, starting at position cursor, with the cursor
+// of the next page, which is the length of the list after the last page.
+func (service *MerkleService) ListTransactions(address string, dir Direction, cursor uint64, limit int) ([]libblock.TransactionWithData, uint64, error) {
+	if dir < DirectionAll || dir > DirectionReceived {
+		return nil, 0, fmt.Errorf("error direction %d", dir)
+	}
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	key := getActivityKey(dir, address)
+	count, err := service.getCount(key)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	list := make([]libblock.TransactionWithData, 0)
+	for ; cursor < count && len(list) < limit; cursor++ {
+		h, err := service.im.GetData([]byte(getIndexKey(key, cursor)))
+		if err != nil {
+			return nil, 0, err
+		}
+		txWithData, err := service.GetTransactionByHash(libcore.Hash(h))
+		if err != nil {
+			return nil, 0, err
+		}
+		list = append(list, txWithData)
+	}
+	return list, cursor, nil
+}
+
+func init() {
+	err := RegisterMigration(3, "index account transactions", func(service *MerkleService) error {
+		cs := service.CryptoService
+		for i := uint64(0); ; i = service.nextIndex(i) {
+			h, err := service.getBlockHash(i)
+			if err != nil {
+				return err
+			}
+			if h == nil {
+				return nil
+			}
+			if service.isHeader(i) {
+				continue
+			}
+			b, err := service.GetBlockByHash(h)
+			if err != nil {
+				return err
+			}
+			for _, txWithData := range b.GetTransactions() {
+				tx := txWithData.GetTransaction()
+				txHash, _, err := cs.Raw(tx, libcrypto.RawBinary)
+				if err != nil {
+					return err
+				}
+				err = service.putActivity(tx, txHash)
+				if err != nil {
+					return err
+				}
+			}
+			if (i+1)%migrateBatch == 0 {
+				err = service.Commit()
+				if err != nil {
+					return err
+				}
+			}
+		}
+	})
+	if err != nil {
+		panic(err)
+	}
+}
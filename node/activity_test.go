@@ -0,0 +1,111 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/tokentransfer/chain/block"
+
+	. "github.com/tokentransfer/check"
+	libblock "github.com/tokentransfer/interfaces/block"
+)
+
+type ActivitySuite struct{}
+
+func Test_Activity(t *testing.T) {
+	s := Suite(&ActivitySuite{})
+	TestingRun(t, s)
+}
+
+// checkTransactions checks that list holds the transactions of the blocks.
+func checkTransactions(c *C, chain *testChain, list []libblock.TransactionWithData, blocks ...int) {
+	c.Assert(len(list), Equals, len(blocks))
+	for i, index := range blocks {
+		expected := chain.blocks[index].GetTransactions()[0]
+		c.Assert([]byte(chain.hash(c, list[i])), DeepEquals, []byte(chain.hash(c, expected)))
+	}
+}
+
+func (suite *ActivitySuite) TestListTransactions(c *C) {
+	chain := newTestChain(c, 3)
+	chain.grow(c, 9)
+	service := newTestService(c)
+	defer service.Close()
+	chain.putBlocks(c, service, 0)
+
+	address, err := chain.accounts[0].GetAddress()
+	c.Assert(err, IsNil)
+	list, cursor, err := service.ListTransactions(address, DirectionAll, 0, 4)
+	c.Assert(err, IsNil)
+	c.Assert(cursor, Equals, uint64(4))
+	checkTransactions(c, chain, list, 1, 3, 4, 6)
+	list, cursor, err = service.ListTransactions(address, DirectionAll, cursor, 4)
+	c.Assert(err, IsNil)
+	c.Assert(cursor, Equals, uint64(6))
+	checkTransactions(c, chain, list, 7, 9)
+
+	list, _, err = service.ListTransactions(address, DirectionSent, 0, 0)
+	c.Assert(err, IsNil)
+	checkTransactions(c, chain, list, 1, 4, 7)
+	list, _, err = service.ListTransactions(address, DirectionReceived, 0, 0)
+	c.Assert(err, IsNil)
+	checkTransactions(c, chain, list, 3, 6, 9)
+	_, _, err = service.ListTransactions(address, Direction(3), 0, 0)
+	c.Assert(err, NotNil)
+
+	err = service.Rollback(5)
+	c.Assert(err, IsNil)
+	list, cursor, err = service.ListTransactions(address, DirectionAll, 0, 0)
+	c.Assert(err, IsNil)
+	c.Assert(cursor, Equals, uint64(3))
+	checkTransactions(c, chain, list, 1, 3, 4)
+}
+
+func (suite *ActivitySuite) TestPendingTransactions(c *C) {
+	chain := newTestChain(c, 3)
+	chain.grow(c, 6)
+	service := newTestService(c)
+	defer service.Close()
+	for i := 0; i < 5; i++ {
+		err := service.PutBlock(chain.blocks[i])
+		c.Assert(err, IsNil)
+		err = service.Commit()
+		c.Assert(err, IsNil)
+	}
+	pending := func(index int) libblock.TransactionWithData {
+		return &block.PaymentWithData{
+			Transaction: chain.blocks[index].GetTransactions()[0].GetTransaction(),
+			Receipt:     &block.Receipt{},
+		}
+	}
+	checkList := func(blocks ...int) []libblock.TransactionWithData {
+		address, err := chain.accounts[2].GetAddress()
+		c.Assert(err, IsNil)
+		list, cursor, err := service.ListTransactions(address, DirectionAll, 0, 0)
+		c.Assert(err, IsNil)
+		c.Assert(cursor, Equals, uint64(len(blocks)))
+		c.Assert(len(list), Equals, len(blocks))
+		for i, index := range blocks {
+			expected := chain.blocks[index].GetTransactions()[0].GetTransaction()
+			c.Assert([]byte(chain.hash(c, list[i].GetTransaction())), DeepEquals, []byte(chain.hash(c, expected)))
+		}
+		return list
+	}
+
+	// the transactions of blocks 5 and 6 are put before block 5
+	err := service.PutTransaction(pending(5))
+	c.Assert(err, IsNil)
+	err = service.PutTransaction(pending(6))
+	c.Assert(err, IsNil)
+	err = service.Commit()
+	c.Assert(err, IsNil)
+	err = service.PutBlock(chain.blocks[5])
+	c.Assert(err, IsNil)
+	err = service.Commit()
+	c.Assert(err, IsNil)
+	list := checkList(2, 3, 5, 6)
+	c.Assert(list[2].GetReceipt().GetStates(), NotNil)
+
+	err = service.Rollback(4)
+	c.Assert(err, IsNil)
+	checkList(2, 3, 6)
+}
@@ -0,0 +1,122 @@
+package node
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/tokentransfer/chain/block"
+
+	libcrypto "github.com/tokentransfer/interfaces/crypto"
+)
+
+func (service *MerkleService) progress(blockIndex uint64) {
+	if service.Progress != nil {
+		service.Progress(blockIndex)
+	}
+}
+
+// Export writes the canonical blocks from..to included to w as a block
+// stream, see block.BlockWriter.
+func (service *MerkleService) Export(from uint64, to uint64, w io.Writer) error {
+	if to < from {
+		return fmt.Errorf("error export range %d-%d", from, to)
+	}
+	bw, err := block.NewBlockWriter(w)
+	if err != nil {
+		return err
+	}
+	for i := from; i <= to; i++ {
+		h, err := service.getBlockHash(i)
+		if err != nil {
+			return err
+		}
+		if h == nil {
+			return fmt.Errorf("no block %d", i)
+		}
+		data, err := service.bm.GetData(h)
+		if err != nil {
+			return err
+		}
+		err = bw.WriteData(data)
+		if err != nil {
+			return err
+		}
+		service.progress(i)
+	}
+	return nil
+}
+
+// Import puts and commits the blocks of a block stream one by one and
+// returns the number of blocks put. The blocks already in the chain are
+// checked and skipped, so an interrupted import is resumed by importing the
+// same stream again. Each block must follow the head of the chain and its
+// StateHash must be the state root after it.
+func (service *MerkleService) Import(r io.Reader) (uint64, error) {
+	cs := service.CryptoService
+
+	br, err := block.NewBlockReader(r)
+	if err != nil {
+		return 0, err
+	}
+	count := uint64(0)
+	for {
+		b, err := br.Read()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+		index := b.GetIndex()
+		h, _, err := cs.Raw(b, libcrypto.RawBinary)
+		if err != nil {
+			return count, err
+		}
+
+		head, ok, err := service.getHead()
+		if err != nil {
+			return count, err
+		}
+		if ok && index <= head {
+			canonical, err := service.getBlockHash(index)
+			if err != nil {
+				return count, err
+			}
+			if !bytes.Equal(canonical, h) {
+				return count, fmt.Errorf("block %d %s is not the block of the chain", index, h.String())
+			}
+			service.progress(index)
+			continue
+		}
+		if (!ok && index != 0) || (ok && index != head+1) {
+			return count, fmt.Errorf("block %d does not follow the chain", index)
+		}
+		if index > 0 {
+			parent, err := service.getBlockHash(index - 1)
+			if err != nil {
+				return count, err
+			}
+			if !bytes.Equal(parent, b.GetParentHash()) {
+				return count, fmt.Errorf("block %d has parent %s, expected %s", index, b.GetParentHash().String(), parent.String())
+			}
+		}
+
+		err = service.putBlock(b)
+		if err != nil {
+			service.Cancel()
+			return count, err
+		}
+		root := service.GetStateRoot()
+		if len(b.GetStateHash()) > 0 && !bytes.Equal(root, b.GetStateHash()) {
+			service.Cancel()
+			return count, fmt.Errorf("block %d has state root %s, expected %s", index, root.String(), b.GetStateHash().String())
+		}
+		err = service.Commit()
+		if err != nil {
+			return count, err
+		}
+		count++
+		service.progress(index)
+	}
+}
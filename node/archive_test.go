@@ -0,0 +1,100 @@
+package node
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tokentransfer/chain/block"
+
+	. "github.com/tokentransfer/check"
+)
+
+type ArchiveSuite struct{}
+
+func Test_Archive(t *testing.T) {
+	s := Suite(&ArchiveSuite{})
+	TestingRun(t, s)
+}
+
+func (suite *ArchiveSuite) TestExportImport(c *C) {
+	chain := newTestChain(c, 3)
+	chain.grow(c, 8)
+	service := newTestService(c)
+	defer service.Close()
+	chain.putBlocks(c, service, 0)
+
+	var part, full bytes.Buffer
+	err := service.Export(0, 4, &part)
+	c.Assert(err, IsNil)
+	exported := make([]uint64, 0)
+	service.Progress = func(blockIndex uint64) {
+		exported = append(exported, blockIndex)
+	}
+	err = service.Export(0, 8, &full)
+	c.Assert(err, IsNil)
+	c.Assert(len(exported), Equals, 9)
+	err = service.Export(4, 9, &bytes.Buffer{})
+	c.Assert(err, NotNil)
+
+	imported := newTestService(c)
+	defer imported.Close()
+	count, err := imported.Import(&part)
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, uint64(5))
+	checkChain(c, imported, chain, 4)
+
+	// the blocks already imported are skipped
+	count, err = imported.Import(bytes.NewReader(full.Bytes()))
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, uint64(4))
+	checkChain(c, imported, chain, 8)
+	count, err = imported.Import(bytes.NewReader(full.Bytes()))
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, uint64(0))
+}
+
+func (suite *ArchiveSuite) TestImportErrors(c *C) {
+	chain := newTestChain(c, 3)
+	chain.grow(c, 4)
+	other := chain.fork(c, 2)
+	other.grow(c, 3)
+
+	write := func(blocks ...*block.Block) *bytes.Buffer {
+		var buf bytes.Buffer
+		bw, err := block.NewBlockWriter(&buf)
+		c.Assert(err, IsNil)
+		for _, b := range blocks {
+			err = bw.Write(b)
+			c.Assert(err, IsNil)
+		}
+		return &buf
+	}
+
+	service := newTestService(c)
+	defer service.Close()
+	count, err := service.Import(write(chain.blocks[0], chain.blocks[1], chain.blocks[2], chain.blocks[3]))
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, uint64(4))
+
+	// a block of another branch in the chain
+	_, err = service.Import(write(chain.blocks[0], other.blocks[3]))
+	c.Assert(err, NotNil)
+	// a gap
+	_, err = service.Import(write(other.blocks[5]))
+	c.Assert(err, NotNil)
+	// another parent
+	_, err = service.Import(write(other.blocks[4]))
+	c.Assert(err, NotNil)
+
+	// a state root which is not the one after the block
+	b := *chain.blocks[4]
+	b.StateHash = chain.blocks[3].StateHash
+	_, err = service.Import(write(&b))
+	c.Assert(err, NotNil)
+	checkChain(c, service, chain, 3)
+
+	count, err = service.Import(write(chain.blocks[4]))
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, uint64(1))
+	checkChain(c, service, chain, 4)
+}
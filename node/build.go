@@ -0,0 +1,124 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tokentransfer/chain/block"
+	"github.com/tokentransfer/chain/crypto"
+
+	libblock "github.com/tokentransfer/interfaces/block"
+	libcore "github.com/tokentransfer/interfaces/core"
+	libcrypto "github.com/tokentransfer/interfaces/crypto"
+)
+
+// getLatestState returns the latest state of key, nil when there is none.
+func (service *MerkleService) getLatestState(key string) (libblock.State, error) {
+	h, err := service.im.GetData([]byte(getNameKey("state", key)))
+	if err != nil {
+		return nil, err
+	}
+	if len(h) == 0 {
+		return nil, nil
+	}
+	return service.GetState(libcore.Hash(h))
+}
+
+// BuildBlock builds the block after the head of the chain with transactions
+// at timestamp, see block.Executor, they must be signed for the chain of the
+// metadata. The fees are charged with GasSchedule and FeeMarket, producer
+// collects them when the FeeMarket has no collector, the validator
+// transactions are checked against GetValidators. The states of the block
+// are put in the state trie to set its StateHash, the block is then put with
+// PutBlock, once sealed, or dropped with Cancel.
+func (service *MerkleService) BuildBlock(producer libcore.Address, timestamp int64, transactions []libblock.Transaction) (*block.Block, error) {
+	cs := service.CryptoService
+
+	l := len(transactions)
+	for i := 0; i < l; i++ {
+		err := crypto.CheckChainID(transactions[i], service.meta.ChainID)
+		if err != nil {
+			return nil, err
+		}
+		ok, err := cs.Verify(transactions[i])
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("error signature of transaction %d", i)
+		}
+	}
+
+	head, err := service.GetHead()
+	if err != nil {
+		return nil, err
+	}
+	parent, ok := head.(*block.Block)
+	if !ok {
+		return nil, errors.New("error block type")
+	}
+	parentHash, _, err := cs.Raw(parent, libcrypto.RawBinary)
+	if err != nil {
+		return nil, err
+	}
+	var validators []libcore.PublicKey
+	if service.GetValidators != nil {
+		validators = service.GetValidators(parent.GetIndex() + 1)
+	}
+	e := &block.Executor{
+		GetState:    service.getLatestState,
+		Validators:  validators,
+		GasSchedule: service.GasSchedule,
+		FeeMarket:   service.FeeMarket,
+		Producer:    producer,
+	}
+	b, err := e.NewBlock(parent, parentHash, timestamp, transactions)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range b.States {
+		h, data, err := cs.Raw(s, libcrypto.RawBinary)
+		if err != nil {
+			return nil, err
+		}
+		err = service.sm.PutData(h, data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	b.StateHash = service.sm.GetRoot()
+	return b, nil
+}
+
+// checkBlockFees returns an error when the GasUsed or the BaseFee of b are
+// not the ones of GasSchedule and FeeMarket.
+func (service *MerkleService) checkBlockFees(b libblock.Block) error {
+	if service.GasSchedule == nil && service.FeeMarket == nil {
+		return nil
+	}
+	sb, ok := b.(*block.Block)
+	if !ok {
+		return errors.New("error block type")
+	}
+	var parent *block.Block
+	if b.GetIndex() > 0 {
+		pb, err := service.GetBlockByHash(b.GetParentHash())
+		if err != nil {
+			return err
+		}
+		parent, ok = pb.(*block.Block)
+		if !ok {
+			return errors.New("error block type")
+		}
+	}
+	r := &VerifyReport{}
+	err := service.checkFees(r, parent, sb)
+	if err != nil {
+		return err
+	}
+	if !r.OK() {
+		return errors.New(r.Errors[0])
+	}
+	return nil
+}
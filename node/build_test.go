@@ -0,0 +1,167 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/tokentransfer/chain/block"
+	"github.com/tokentransfer/chain/consensus"
+	"github.com/tokentransfer/chain/core"
+
+	. "github.com/tokentransfer/check"
+	libblock "github.com/tokentransfer/interfaces/block"
+	libcore "github.com/tokentransfer/interfaces/core"
+)
+
+type BuildSuite struct{}
+
+func Test_Build(t *testing.T) {
+	s := Suite(&BuildSuite{})
+	TestingRun(t, s)
+}
+
+func (suite *BuildSuite) TestFees(c *C) {
+	chain := newTestChain(c, 3)
+	market := &block.FeeMarket{
+		TargetGas:      10,
+		InitialBaseFee: 8,
+		MinBaseFee:     1,
+		Denominator:    8,
+	}
+	chain.blocks[0].BaseFee = market.InitialBaseFee
+	service := openTestService(c, c.MkDir(), &MerkleService{
+		GasSchedule: &block.GasSchedule{DefaultBase: 6, TagByte: 1},
+		FeeMarket:   market,
+	})
+	defer service.Close()
+	chain.putBlocks(c, service, 0)
+	producer := chain.accounts[2]
+	amount := func(i int) int64 {
+		address, err := chain.accounts[i].GetAddress()
+		c.Assert(err, IsNil)
+		s, err := service.GetStateByKey(address)
+		c.Assert(err, IsNil)
+		return s.(*block.AccountState).Amount
+	}
+
+	// the payments use 10 gas each, the base fee falls under the target gas
+	b1 := buildBlock(c, service, producer, 60,
+		chain.payment(c, 0, 1, 5, 1),
+		chain.payment(c, 1, 0, 3, 1),
+	)
+	c.Assert(b1.GasUsed, Equals, int64(20))
+	c.Assert(b1.BaseFee, Equals, int64(7))
+	c.Assert(amount(0), Equals, int64(1000000-5+3-70))
+	c.Assert(amount(1), Equals, int64(1000000+5-3-70))
+	c.Assert(amount(2), Equals, int64(1000000+140))
+
+	// a device with 11 bytes of tags is out of gas and a payment with the
+	// sequence of another one is a replay, they are left out of the block;
+	// a payment over the balance pays its fee, and the producer pays itself
+	newDevice := &block.NewDevice{
+		Transaction: chain.deviceTransaction(0, core.CORE_NEWDEVICE),
+		Symbol:      "sensor",
+		DeviceTags:  []string{"temperature"},
+	}
+	err := chain.cs.Sign(chain.keys[0], newDevice)
+	c.Assert(err, IsNil)
+	chain.sequences[0]--
+	payment := chain.payment(c, 2, 0, 1, 2)
+	b2, err := service.BuildBlock(producer, 120, []libblock.Transaction{newDevice, payment, payment, chain.payment(c, 0, 1, 2000000, 2)})
+	c.Assert(err, IsNil)
+	c.Assert(len(b2.Transactions), Equals, 2)
+	c.Assert(b2.Transactions[0].GetTransaction(), Equals, payment)
+	c.Assert(b2.Transactions[1].GetReceipt().GetTransactionResult(), Equals, block.ResultInsufficientBalance)
+	c.Assert(b2.Transactions[1].GetReceipt().(*block.Receipt).TransactionIndex, Equals, uint32(1))
+	c.Assert(b2.GasUsed, Equals, int64(20))
+	c.Assert(b2.BaseFee, Equals, int64(8))
+
+	bad := *b2
+	bad.BaseFee++
+	err = service.PutBlock(&bad)
+	c.Assert(err, NotNil)
+	bad = *b2
+	bad.GasUsed--
+	err = service.PutBlock(&bad)
+	c.Assert(err, NotNil)
+	err = service.Cancel()
+	c.Assert(err, IsNil)
+
+	err = service.PutBlock(b2)
+	c.Assert(err, IsNil)
+	err = service.Commit()
+	c.Assert(err, IsNil)
+	c.Assert(amount(0), Equals, int64(1000000-5+3-70+1-80))
+	c.Assert(amount(2), Equals, int64(1000000+140-1+80))
+
+	// the block uses at most twice the target gas
+	b3, err := service.BuildBlock(producer, 180, []libblock.Transaction{
+		chain.payment(c, 0, 1, 1, 3),
+		chain.payment(c, 1, 0, 1, 2),
+		chain.payment(c, 2, 0, 1, 3),
+	})
+	c.Assert(err, IsNil)
+	c.Assert(len(b3.Transactions), Equals, 2)
+	c.Assert(b3.GasUsed, Equals, int64(20))
+	err = service.Cancel()
+	c.Assert(err, IsNil)
+	bad = *b3
+	bad.GasUsed = 30
+	err = service.PutBlock(&bad)
+	c.Assert(err, NotNil)
+	err = service.Cancel()
+	c.Assert(err, IsNil)
+	report, err := service.Verify()
+	c.Assert(err, IsNil)
+	c.Assert(report.Errors, IsNil)
+}
+
+func (suite *BuildSuite) TestValidators(c *C) {
+	chain := newTestChain(c, 3)
+	keys := make([]libcore.PublicKey, 3)
+	for i := 0; i < 3; i++ {
+		p, err := chain.keys[i].GetPublic()
+		c.Assert(err, IsNil)
+		data, err := p.MarshalBinary()
+		c.Assert(err, IsNil)
+		keys[i] = libcore.PublicKey(data)
+	}
+	e := &consensus.PoA{Validators: keys[:1]}
+	err := e.Init(nil)
+	c.Assert(err, IsNil)
+	service := openTestService(c, c.MkDir(), &MerkleService{GetValidators: e.GetValidators})
+	defer service.Close()
+	chain.putBlocks(c, service, 0)
+	err = e.Apply(chain.blocks[0])
+	c.Assert(err, IsNil)
+	add := func(i int, validator libcore.PublicKey) libblock.Transaction {
+		tx := &block.AddValidator{
+			Transaction: chain.deviceTransaction(i, core.CORE_ADDVALIDATOR),
+			Validator:   validator,
+		}
+		err := chain.cs.Sign(chain.keys[i], tx)
+		c.Assert(err, IsNil)
+		return tx
+	}
+
+	// a transaction of a non-validator fails and does not stop the block
+	b1 := buildBlock(c, service, nil, 60, add(2, keys[2]), add(0, keys[0]), add(0, keys[1]))
+	c.Assert(b1.Transactions[0].GetReceipt().GetTransactionResult(), Equals, block.ResultNotValidator)
+	c.Assert(b1.Transactions[1].GetReceipt().GetTransactionResult(), Equals, block.ResultValidatorExists)
+	c.Assert(b1.Transactions[2].GetReceipt().GetTransactionResult(), Equals, block.ResultSuccess)
+	err = e.Apply(b1)
+	c.Assert(err, IsNil)
+	c.Assert(e.GetValidators(2), DeepEquals, keys[:2])
+
+	b2 := buildBlock(c, service, nil, 120, add(1, keys[2]))
+	c.Assert(b2.Transactions[0].GetReceipt().GetTransactionResult(), Equals, block.ResultSuccess)
+	err = e.Apply(b2)
+	c.Assert(err, IsNil)
+	c.Assert(e.GetValidators(3), DeepEquals, keys[:2])
+
+	service.GetValidators = nil
+	b3 := buildBlock(c, service, nil, 180, add(0, keys[2]))
+	c.Assert(b3.Transactions[0].GetReceipt().GetTransactionResult(), Equals, block.ResultNotValidator)
+	report, err := service.Verify()
+	c.Assert(err, IsNil)
+	c.Assert(report.Errors, IsNil)
+}
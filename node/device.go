@@ -0,0 +1,175 @@
+package node
+
+import (
+	"fmt"
+
+	"github.com/tokentransfer/chain/block"
+
+	libcore "github.com/tokentransfer/interfaces/core"
+)
+
+// Every owner and tag a device had is listed with its symbol in
+// "device@owner@address" and "device@tag@tag", with the length of the list
+// and "...:N" its Nth symbol, a symbol is listed once. The lists are checked
+// against the latest version of the devices when they are read.
+
+func getDeviceKey(field string, value string) string {
+	return fmt.Sprintf("device@%s@%s", field, value)
+}
+
+func (service *MerkleService) addDevice(listKey string, symbol string) error {
+	markKey := []byte(getNameKey(listKey, symbol))
+	mark, err := service.im.GetData(markKey)
+	if err != nil {
+		return err
+	}
+	if len(mark) > 0 {
+		return nil
+	}
+	count, err := service.getCount(listKey)
+	if err != nil {
+		return err
+	}
+	err = service.im.PutData([]byte(getIndexKey(listKey, count)), []byte(symbol))
+	if err != nil {
+		return err
+	}
+	err = service.im.PutData([]byte(listKey), putUint64(count+1))
+	if err != nil {
+		return err
+	}
+	return service.im.PutData(markKey, []byte{1})
+}
+
+func (service *MerkleService) putDevice(s *block.DeviceState) error {
+	owner, err := s.Account.GetAddress()
+	if err != nil {
+		return err
+	}
+	err = service.addDevice(getDeviceKey("owner", owner), s.Symbol)
+	if err != nil {
+		return err
+	}
+	for _, tag := range s.Tags {
+		err := service.addDevice(getDeviceKey("tag", tag), s.Symbol)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getDevice returns the latest version of a device, nil if it was removed by
+// a rollback.
+func (service *MerkleService) getDevice(symbol string) (*block.DeviceState, error) {
+	h, err := service.im.GetData([]byte(getNameKey("state", symbol)))
+	if err != nil {
+		return nil, err
+	}
+	if len(h) == 0 {
+		return nil, nil
+	}
+	s, err := service.GetState(libcore.Hash(h))
+	if err != nil {
+		return nil, err
+	}
+	d, ok := s.(*block.DeviceState)
+	if !ok {
+		return nil, nil
+	}
+	return d, nil
+}
+
+func (service *MerkleService) listDevices(listKey string, match func(d *block.DeviceState) (bool, error)) ([]*block.DeviceState, error) {
+	count, err := service.getCount(listKey)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*block.DeviceState, 0)
+	for i := uint64(0); i < count; i++ {
+		symbol, err := service.im.GetData([]byte(getIndexKey(listKey, i)))
+		if err != nil {
+			return nil, err
+		}
+		d, err := service.getDevice(string(symbol))
+		if err != nil {
+			return nil, err
+		}
+		if d == nil || d.Retired {
+			continue
+		}
+		ok, err := match(d)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			list = append(list, d)
+		}
+	}
+	return list, nil
+}
+
+// ListDevicesByOwner returns the devices owned by the address, in the order
+// they were first owned by it, the retired devices are left out.
+func (service *MerkleService) ListDevicesByOwner(owner string) ([]*block.DeviceState, error) {
+	return service.listDevices(getDeviceKey("owner", owner), func(d *block.DeviceState) (bool, error) {
+		address, err := d.Account.GetAddress()
+		if err != nil {
+			return false, err
+		}
+		return address == owner, nil
+	})
+}
+
+// ListDevicesByTag returns the devices tagged with tag, in the order they
+// were first tagged, the retired devices are left out.
+func (service *MerkleService) ListDevicesByTag(tag string) ([]*block.DeviceState, error) {
+	return service.listDevices(getDeviceKey("tag", tag), func(d *block.DeviceState) (bool, error) {
+		for _, t := range d.Tags {
+			if t == tag {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+}
+
+func init() {
+	err := RegisterMigration(5, "index devices", func(service *MerkleService) error {
+		for i := uint64(0); ; i = service.nextIndex(i) {
+			h, err := service.getBlockHash(i)
+			if err != nil {
+				return err
+			}
+			if h == nil {
+				return nil
+			}
+			if service.isHeader(i) {
+				continue
+			}
+			b, err := service.GetBlockByHash(h)
+			if err != nil {
+				return err
+			}
+			for _, s := range b.GetStates() {
+				d, ok := s.(*block.DeviceState)
+				if !ok {
+					continue
+				}
+				err = service.putDevice(d)
+				if err != nil {
+					return err
+				}
+			}
+			if (i+1)%migrateBatch == 0 {
+				err = service.Commit()
+				if err != nil {
+					return err
+				}
+			}
+		}
+	})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,131 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/tokentransfer/chain/block"
+	"github.com/tokentransfer/chain/core"
+
+	. "github.com/tokentransfer/check"
+)
+
+type DeviceSuite struct{}
+
+func Test_Device(t *testing.T) {
+	s := Suite(&DeviceSuite{})
+	TestingRun(t, s)
+}
+
+func checkDevices(c *C, list []*block.DeviceState, symbols ...string) {
+	c.Assert(len(list), Equals, len(symbols))
+	for i, d := range list {
+		c.Assert(d.Symbol, Equals, symbols[i])
+	}
+}
+
+func (suite *DeviceSuite) TestListDevices(c *C) {
+	chain := newTestChain(c, 3)
+	service := newTestService(c)
+	defer service.Close()
+	chain.putBlocks(c, service, 0)
+	addresses := make([]string, 3)
+	for i := 0; i < 3; i++ {
+		a, err := chain.accounts[i].GetAddress()
+		c.Assert(err, IsNil)
+		addresses[i] = a
+	}
+
+	sensor := &block.NewDevice{
+		Transaction: chain.deviceTransaction(0, core.CORE_NEWDEVICE),
+		Symbol:      "sensor",
+		DeviceTags:  []string{"temperature", "outdoor"},
+	}
+	err := chain.cs.Sign(chain.keys[0], sensor)
+	c.Assert(err, IsNil)
+	meter := &block.NewDevice{
+		Transaction: chain.deviceTransaction(0, core.CORE_NEWDEVICE),
+		Symbol:      "meter",
+		DeviceTags:  []string{"power"},
+	}
+	err = chain.cs.Sign(chain.keys[0], meter)
+	c.Assert(err, IsNil)
+	buildBlock(c, service, nil, 60, sensor, meter)
+
+	list, err := service.ListDevicesByOwner(addresses[0])
+	c.Assert(err, IsNil)
+	checkDevices(c, list, "sensor", "meter")
+	list, err = service.ListDevicesByTag("temperature")
+	c.Assert(err, IsNil)
+	checkDevices(c, list, "sensor")
+
+	// the sensor changes its tags and owner, the meter is retired
+	update := &block.UpdateDevice{
+		Transaction: chain.deviceTransaction(0, core.CORE_UPDATEDEVICE),
+		Symbol:      "sensor",
+		Description: "roof",
+		DeviceTags:  []string{"humidity"},
+	}
+	err = chain.cs.Sign(chain.keys[0], update)
+	c.Assert(err, IsNil)
+	transfer := &block.TransferDevice{
+		Transaction: chain.deviceTransaction(0, core.CORE_TRANSFERDEVICE),
+		Symbol:      "sensor",
+	}
+	transfer.Destination = chain.accounts[1]
+	err = chain.cs.Sign(chain.keys[0], transfer)
+	c.Assert(err, IsNil)
+	retire := &block.RetireDevice{
+		Transaction: chain.deviceTransaction(0, core.CORE_RETIREDEVICE),
+		Symbol:      "meter",
+	}
+	err = chain.cs.Sign(chain.keys[0], retire)
+	c.Assert(err, IsNil)
+	b := buildBlock(c, service, nil, 120, update, transfer, retire)
+	for _, tx := range b.Transactions {
+		c.Assert(tx.GetReceipt().GetTransactionResult(), Equals, block.ResultSuccess)
+	}
+
+	list, err = service.ListDevicesByOwner(addresses[0])
+	c.Assert(err, IsNil)
+	checkDevices(c, list)
+	list, err = service.ListDevicesByOwner(addresses[1])
+	c.Assert(err, IsNil)
+	checkDevices(c, list, "sensor")
+	c.Assert(list[0].Description, Equals, "roof")
+	list, err = service.ListDevicesByTag("temperature")
+	c.Assert(err, IsNil)
+	checkDevices(c, list)
+	list, err = service.ListDevicesByTag("humidity")
+	c.Assert(err, IsNil)
+	checkDevices(c, list, "sensor")
+	list, err = service.ListDevicesByTag("power")
+	c.Assert(err, IsNil)
+	checkDevices(c, list)
+
+	// a retired device can not be changed, only its owner can change it
+	again := &block.UpdateDevice{
+		Transaction: chain.deviceTransaction(0, core.CORE_UPDATEDEVICE),
+		Symbol:      "meter",
+	}
+	err = chain.cs.Sign(chain.keys[0], again)
+	c.Assert(err, IsNil)
+	stolen := &block.RetireDevice{
+		Transaction: chain.deviceTransaction(0, core.CORE_RETIREDEVICE),
+		Symbol:      "sensor",
+	}
+	err = chain.cs.Sign(chain.keys[0], stolen)
+	c.Assert(err, IsNil)
+	b = buildBlock(c, service, nil, 180, again, stolen)
+	c.Assert(b.Transactions[0].GetReceipt().GetTransactionResult(), Equals, block.ResultDeviceRetired)
+	c.Assert(b.Transactions[1].GetReceipt().GetTransactionResult(), Equals, block.ResultDeviceNotOwned)
+
+	// the rollback puts the sensor back to its first owner
+	err = service.Rollback(1)
+	c.Assert(err, IsNil)
+	list, err = service.ListDevicesByOwner(addresses[0])
+	c.Assert(err, IsNil)
+	checkDevices(c, list, "sensor", "meter")
+	list, err = service.ListDevicesByOwner(addresses[1])
+	c.Assert(err, IsNil)
+	checkDevices(c, list)
+}
@@ -0,0 +1,275 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tokentransfer/chain/block"
+	"github.com/tokentransfer/chain/core"
+	"github.com/tokentransfer/chain/core/pb"
+
+	libblock "github.com/tokentransfer/interfaces/block"
+	libcore "github.com/tokentransfer/interfaces/core"
+)
+
+// StateDiff is the change of a state key in a block, PrevHash is nil when
+// the key is created, the amounts are set for the account states.
+type StateDiff struct {
+	BlockIndex uint64
+	Key        string
+	PrevHash   libcore.Hash
+	Hash       libcore.Hash
+	Account    bool
+	PrevAmount int64
+	Amount     int64
+}
+
+// The diff of block N is kept in "diff@N", the blocks changing a key are
+// listed in "diffs@key", with the length of the list and "diffs@key:N" the
+// index of the Nth block.
+
+func getDiffKey(index uint64) string {
+	return fmt.Sprintf("diff@%d", index)
+}
+
+func (service *MerkleService) getAmount(h libcore.Hash) (bool, int64, error) {
+	s, err := service.GetState(h)
+	if err != nil {
+		return false, 0, err
+	}
+	a, ok := s.(*block.AccountState)
+	if !ok {
+		return false, 0, nil
+	}
+	return true, a.Amount, nil
+}
+
+// putDiff records the diff of b from the state history, it must run after
+// the states of b are put, it does nothing when the diff of b is recorded,
+// so the lists stay in block order when the index is rebuilt.
+func (service *MerkleService) putDiff(b libblock.Block) error {
+	old, err := service.readDiff(b.GetIndex())
+	if err != nil {
+		return err
+	}
+	if old != nil {
+		return nil
+	}
+
+	diff := &pb.BlockDiff{BlockIndex: b.GetIndex()}
+	seen := make(map[string]bool)
+	for _, s := range b.GetStates() {
+		key := s.GetStateKey()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		data, err := service.im.GetData([]byte(getNameKey("history", getIndexKey(key, s.GetBlockIndex()))))
+		if err != nil {
+			return err
+		}
+		l := &stateLog{}
+		err = l.UnmarshalBinary(data)
+		if err != nil {
+			return err
+		}
+		d := &pb.StateDiff{
+			Key:  key,
+			Hash: l.Hash,
+		}
+		d.Account, d.Amount, err = service.getAmount(l.Hash)
+		if err != nil {
+			return err
+		}
+		if l.HasPrev {
+			data, err := service.im.GetData([]byte(getNameKey("history", getIndexKey(key, l.Prev))))
+			if err != nil {
+				return err
+			}
+			prev := &stateLog{}
+			err = prev.UnmarshalBinary(data)
+			if err != nil {
+				return err
+			}
+			d.PrevHash = prev.Hash
+			_, d.PrevAmount, err = service.getAmount(prev.Hash)
+			if err != nil {
+				return err
+			}
+		}
+		diff.Diffs = append(diff.Diffs, d)
+
+		listKey := getNameKey("diffs", key)
+		count, err := service.getCount(listKey)
+		if err != nil {
+			return err
+		}
+		err = service.im.PutData([]byte(getIndexKey(listKey, count)), putUint64(b.GetIndex()))
+		if err != nil {
+			return err
+		}
+		err = service.im.PutData([]byte(listKey), putUint64(count+1))
+		if err != nil {
+			return err
+		}
+	}
+
+	data, err := core.Marshal(diff)
+	if err != nil {
+		return err
+	}
+	return service.im.PutData([]byte(getDiffKey(b.GetIndex())), data)
+}
+
+func (service *MerkleService) removeDiff(index uint64) error {
+	diff, err := service.readDiff(index)
+	if err != nil {
+		return err
+	}
+	if diff == nil {
+		return nil
+	}
+	for _, d := range diff.Diffs {
+		listKey := getNameKey("diffs", d.Key)
+		count, err := service.getCount(listKey)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			continue
+		}
+		err = service.removeIndex(getIndexKey(listKey, count-1))
+		if err != nil {
+			return err
+		}
+		err = service.im.PutData([]byte(listKey), putUint64(count-1))
+		if err != nil {
+			return err
+		}
+	}
+	return service.removeIndex(getDiffKey(index))
+}
+
+func (service *MerkleService) readDiff(index uint64) (*pb.BlockDiff, error) {
+	data, err := service.im.GetData([]byte(getDiffKey(index)))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	meta, msg, err := core.Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	if meta != core.CORE_BLOCK_DIFF {
+		return nil, errors.New("error block diff data")
+	}
+	return msg.(*pb.BlockDiff), nil
+}
+
+func toStateDiff(index uint64, d *pb.StateDiff) *StateDiff {
+	diff := &StateDiff{
+		BlockIndex: index,
+		Key:        d.Key,
+		Hash:       libcore.Hash(d.Hash),
+		Account:    d.Account,
+		PrevAmount: d.PrevAmount,
+		Amount:     d.Amount,
+	}
+	if len(d.PrevHash) > 0 {
+		diff.PrevHash = libcore.Hash(d.PrevHash)
+	}
+	return diff
+}
+
+// GetBlockDiff returns the changes of the state keys in block blockIndex.
+func (service *MerkleService) GetBlockDiff(blockIndex uint64) ([]*StateDiff, error) {
+	diff, err := service.readDiff(blockIndex)
+	if err != nil {
+		return nil, err
+	}
+	if diff == nil {
+		return nil, fmt.Errorf("no diff of block %d", blockIndex)
+	}
+	list := make([]*StateDiff, len(diff.Diffs))
+	for i, d := range diff.Diffs {
+		list[i] = toStateDiff(diff.BlockIndex, d)
+	}
+	return list, nil
+}
+
+// ListStateDiffs returns at most limit changes of a state key, the address
+// of an account, in block order, starting at position cursor, with the
+// cursor of the next page.
+func (service *MerkleService) ListStateDiffs(key string, cursor uint64, limit int) ([]*StateDiff, uint64, error) {
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	listKey := getNameKey("diffs", key)
+	count, err := service.getCount(listKey)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	list := make([]*StateDiff, 0)
+	for ; cursor < count && len(list) < limit; cursor++ {
+		data, err := service.im.GetData([]byte(getIndexKey(listKey, cursor)))
+		if err != nil {
+			return nil, 0, err
+		}
+		index, ok := getUint64(data)
+		if !ok {
+			return nil, 0, errors.New("error diff index")
+		}
+		diff, err := service.readDiff(index)
+		if err != nil {
+			return nil, 0, err
+		}
+		if diff == nil {
+			return nil, 0, fmt.Errorf("no diff of block %d", index)
+		}
+		for _, d := range diff.Diffs {
+			if d.Key == key {
+				list = append(list, toStateDiff(index, d))
+				break
+			}
+		}
+	}
+	return list, cursor, nil
+}
+
+func init() {
+	err := RegisterMigration(6, "record state diffs", func(service *MerkleService) error {
+		for i := uint64(0); ; i = service.nextIndex(i) {
+			h, err := service.getBlockHash(i)
+			if err != nil {
+				return err
+			}
+			if h == nil {
+				return nil
+			}
+			if service.isHeader(i) {
+				continue
+			}
+			b, err := service.GetBlockByHash(h)
+			if err != nil {
+				return err
+			}
+			err = service.putDiff(b)
+			if err != nil {
+				return err
+			}
+			if (i+1)%migrateBatch == 0 {
+				err = service.Commit()
+				if err != nil {
+					return err
+				}
+			}
+		}
+	})
+	if err != nil {
+		panic(err)
+	}
+}
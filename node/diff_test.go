@@ -0,0 +1,104 @@
+package node
+
+import (
+	"testing"
+
+	. "github.com/tokentransfer/check"
+)
+
+type DiffSuite struct{}
+
+func Test_Diff(t *testing.T) {
+	s := Suite(&DiffSuite{})
+	TestingRun(t, s)
+}
+
+func (suite *DiffSuite) TestDiffs(c *C) {
+	chain := newTestChain(c, 3)
+	chain.grow(c, 9)
+	service := newTestService(c)
+	defer service.Close()
+	chain.putBlocks(c, service, 0)
+
+	diffs, err := service.GetBlockDiff(0)
+	c.Assert(err, IsNil)
+	c.Assert(len(diffs), Equals, 3)
+	for _, d := range diffs {
+		c.Assert(d.PrevHash, IsNil)
+		c.Assert(d.Account, Equals, true)
+		c.Assert(d.Amount, Equals, int64(1000000))
+	}
+
+	// block 4 has a payment of 4 from account 0 to account 1, account 0
+	// received a payment in block 3
+	address, err := chain.accounts[0].GetAddress()
+	c.Assert(err, IsNil)
+	diffs, err = service.GetBlockDiff(4)
+	c.Assert(err, IsNil)
+	c.Assert(len(diffs), Equals, 2)
+	c.Assert(diffs[0].Key, Equals, address)
+	c.Assert(diffs[0].BlockIndex, Equals, uint64(4))
+	c.Assert([]byte(diffs[0].PrevHash), DeepEquals, []byte(chain.hash(c, chain.blocks[3].States[1])))
+	c.Assert([]byte(diffs[0].Hash), DeepEquals, []byte(chain.hash(c, chain.blocks[4].States[0])))
+	c.Assert(diffs[0].Amount, Equals, diffs[0].PrevAmount-4)
+	c.Assert(diffs[1].Amount, Equals, diffs[1].PrevAmount+4)
+
+	list, cursor, err := service.ListStateDiffs(address, 0, 4)
+	c.Assert(err, IsNil)
+	c.Assert(cursor, Equals, uint64(4))
+	list2, cursor, err := service.ListStateDiffs(address, cursor, 4)
+	c.Assert(err, IsNil)
+	c.Assert(cursor, Equals, uint64(7))
+	list = append(list, list2...)
+	blocks := []uint64{0, 1, 3, 4, 6, 7, 9}
+	c.Assert(len(list), Equals, len(blocks))
+	for i, d := range list {
+		c.Assert(d.BlockIndex, Equals, blocks[i])
+		c.Assert(d.Key, Equals, address)
+		if i > 0 {
+			c.Assert([]byte(d.PrevHash), DeepEquals, []byte(list[i-1].Hash))
+			c.Assert(d.PrevAmount, Equals, list[i-1].Amount)
+		}
+	}
+
+	err = service.Rollback(5)
+	c.Assert(err, IsNil)
+	_, err = service.GetBlockDiff(6)
+	c.Assert(err, NotNil)
+	list, cursor, err = service.ListStateDiffs(address, 0, 0)
+	c.Assert(err, IsNil)
+	c.Assert(cursor, Equals, uint64(4))
+	c.Assert(len(list), Equals, 4)
+}
+
+func (suite *DiffSuite) TestRecordTwice(c *C) {
+	chain := newTestChain(c, 3)
+	chain.grow(c, 9)
+	service := newTestService(c)
+	defer service.Close()
+	chain.putBlocks(c, service, 0)
+
+	// migration 6 run on blocks with recorded diffs keeps the lists
+	m, ok := getMigration(6)
+	c.Assert(ok, Equals, true)
+	err := m.Migrate(service)
+	c.Assert(err, IsNil)
+	err = service.Commit()
+	c.Assert(err, IsNil)
+
+	address, err := chain.accounts[0].GetAddress()
+	c.Assert(err, IsNil)
+	list, _, err := service.ListStateDiffs(address, 0, 0)
+	c.Assert(err, IsNil)
+	c.Assert(len(list), Equals, 7)
+
+	err = service.Rollback(5)
+	c.Assert(err, IsNil)
+	list, _, err = service.ListStateDiffs(address, 0, 0)
+	c.Assert(err, IsNil)
+	blocks := []uint64{0, 1, 3, 4}
+	c.Assert(len(list), Equals, len(blocks))
+	for i, d := range list {
+		c.Assert(d.BlockIndex, Equals, blocks[i])
+	}
+}
@@ -0,0 +1,789 @@
+package node
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/tokentransfer/go-MerklePatriciaTree/mpt"
+
+	"github.com/tokentransfer/chain/core"
+
+	libblock "github.com/tokentransfer/interfaces/block"
+	libcore "github.com/tokentransfer/interfaces/core"
+	libcrypto "github.com/tokentransfer/interfaces/crypto"
+	libstore "github.com/tokentransfer/interfaces/store"
+)
+
+// Every block put is kept in the block trie and listed in "blocks@N", the
+// canonical chain is the one indexed by "block@N" and "head" holds the index
+// of its last block. A removed index entry is written empty.
+
+var headKey = []byte("head")
+
+// ErrNoBlock is returned by GetHead when the chain is empty.
+var ErrNoBlock = errors.New("no block")
+
+// ForkChoice returns true when the branch ending with candidate must replace
+// the canonical chain ending with head.
+type ForkChoice func(head libblock.Block, candidate libblock.Block) bool
+
+// LongestChain is the default fork choice, a branch replaces the chain when
+// it is longer, the first block seen wins at the same height.
+func LongestChain(head libblock.Block, candidate libblock.Block) bool {
+	return candidate.GetIndex() > head.GetIndex()
+}
+
+func getBlocksKey(index uint64) string {
+	return fmt.Sprintf("blocks@%d", index)
+}
+
+func (service *MerkleService) removeIndex(key string) error {
+	return service.im.PutData([]byte(key), []byte{})
+}
+
+func (service *MerkleService) getHead() (uint64, bool, error) {
+	data, err := service.im.GetData(headKey)
+	if err != nil {
+		return 0, false, err
+	}
+	head, ok := getUint64(data)
+	return head, ok, nil
+}
+
+// GetHead returns the last block of the canonical chain.
+func (service *MerkleService) GetHead() (libblock.Block, error) {
+	head, ok, err := service.getHead()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNoBlock
+	}
+	return service.GetBlockByIndex(head)
+}
+
+func (service *MerkleService) addBlockHash(index uint64, h libcore.Hash) error {
+	key := []byte(getBlocksKey(index))
+	data, err := service.im.GetData(key)
+	if err != nil {
+		return err
+	}
+	size := len(h)
+	for i := 0; i+size <= len(data); i += size {
+		if bytes.Equal(data[i:i+size], h) {
+			return nil
+		}
+	}
+	list := make([]byte, 0, len(data)+size)
+	list = append(list, data...)
+	list = append(list, h...)
+	return service.im.PutData(key, list)
+}
+
+// GetBlocksByIndex returns all the blocks put at index, the canonical one
+// first.
+func (service *MerkleService) GetBlocksByIndex(index uint64) ([]libblock.Block, error) {
+	canonical, err := service.getBlockHash(index)
+	if err != nil {
+		return nil, err
+	}
+	data, err := service.im.GetData([]byte(getBlocksKey(index)))
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]libcore.Hash, 0)
+	if canonical != nil {
+		hashes = append(hashes, canonical)
+	}
+	size := service.CryptoService.GetSize()
+	for i := 0; i+size <= len(data); i += size {
+		h := libcore.Hash(data[i : i+size])
+		if !bytes.Equal(h, canonical) {
+			hashes = append(hashes, h)
+		}
+	}
+
+	blocks := make([]libblock.Block, len(hashes))
+	for i, h := range hashes {
+		b, err := service.GetBlockByHash(h)
+		if err != nil {
+			return nil, err
+		}
+		blocks[i] = b
+	}
+	return blocks, nil
+}
+
+// isSideBlock tells if b competes with the canonical block at its index or
+// extends a known block which is not the head. A block is canonical only
+// when it is the genesis of an empty chain or its parent is the head, a block
+// whose parent is not known is refused.
+func (service *MerkleService) isSideBlock(b libblock.Block, h libcore.Hash) (bool, error) {
+	canonical, err := service.getBlockHash(b.GetIndex())
+	if err != nil {
+		return false, err
+	}
+	if canonical != nil && bytes.Equal(canonical, h) {
+		return false, nil
+	}
+	head, ok, err := service.getHead()
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		if b.GetIndex() == 0 {
+			return false, nil
+		}
+		return false, fmt.Errorf("block %d does not follow the chain", b.GetIndex())
+	}
+	if b.GetIndex() == 0 {
+		return true, nil
+	}
+	if canonical == nil && b.GetIndex() == head+1 {
+		parent, err := service.getBlockHash(head)
+		if err != nil {
+			return false, err
+		}
+		if bytes.Equal(parent, b.GetParentHash()) {
+			return false, nil
+		}
+	}
+	data, err := service.bm.GetData(b.GetParentHash())
+	if err != nil {
+		return false, err
+	}
+	if len(data) == 0 {
+		return false, fmt.Errorf("block %d has unknown parent %s", b.GetIndex(), b.GetParentHash().String())
+	}
+	return true, nil
+}
+
+// putSideBlock stores a block of another branch, its transactions and states
+// are indexed only if the fork choice switches to its branch, the pending
+// changes are committed before the switch.
+func (service *MerkleService) putSideBlock(b libblock.Block, h libcore.Hash, data []byte) error {
+	err := service.bm.PutData(h, data)
+	if err != nil {
+		return err
+	}
+	err = service.addBlockHash(b.GetIndex(), h)
+	if err != nil {
+		return err
+	}
+
+	_, err = service.chooseBranch(b, h)
+	return err
+}
+
+// ChooseBranch reorganizes the chain to the branch ending with the block of
+// hash h when the fork choice prefers it to the canonical chain, it returns
+// false when the chain is kept and true when the block is canonical.
+func (service *MerkleService) ChooseBranch(h libcore.Hash) (bool, error) {
+	b, err := service.GetBlockByHash(h)
+	if err != nil {
+		return false, err
+	}
+	canonical, err := service.getBlockHash(b.GetIndex())
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(canonical, h) {
+		return true, nil
+	}
+	return service.chooseBranch(b, h)
+}
+
+func (service *MerkleService) chooseBranch(b libblock.Block, h libcore.Hash) (bool, error) {
+	head, err := service.GetHead()
+	if err != nil {
+		return false, err
+	}
+	choice := service.ForkChoice
+	if choice == nil {
+		choice = LongestChain
+	}
+	if !choice(head, b) {
+		return false, nil
+	}
+	err = service.Commit()
+	if err != nil {
+		return false, err
+	}
+	err = service.Reorg(h)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Reorg makes the branch ending with the block of hash h canonical, the
+// blocks of the branch are checked, the chain is rolled back to the last
+// common block and the blocks of the branch are put and committed one by
+// one. When one of them fails, the chain is rolled back again and its
+// blocks are put back.
+func (service *MerkleService) Reorg(h libcore.Hash) error {
+	branch := make([]libblock.Block, 0)
+	for {
+		b, err := service.GetBlockByHash(h)
+		if err != nil {
+			return err
+		}
+		canonical, err := service.getBlockHash(b.GetIndex())
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(canonical, h) {
+			break
+		}
+		branch = append([]libblock.Block{b}, branch...)
+		if b.GetIndex() == 0 {
+			return errors.New("branch does not join the chain")
+		}
+		h = b.GetParentHash()
+	}
+	if len(branch) == 0 {
+		return nil
+	}
+	for _, b := range branch {
+		err := service.checkBlock(b)
+		if err != nil {
+			return err
+		}
+	}
+
+	common := branch[0].GetIndex() - 1
+	head, _, err := service.getHead()
+	if err != nil {
+		return err
+	}
+	old := make([]libblock.Block, 0, head-common)
+	for i := common + 1; i <= head; i++ {
+		b, err := service.GetBlockByIndex(i)
+		if err != nil {
+			return err
+		}
+		old = append(old, b)
+	}
+
+	err = service.Rollback(common)
+	if err != nil {
+		return err
+	}
+	err = service.putBranch(branch)
+	if err == nil {
+		return nil
+	}
+	rerr := service.Rollback(common)
+	if rerr == nil {
+		rerr = service.putBranch(old)
+	}
+	if rerr != nil {
+		return fmt.Errorf("%s, restoring the chain: %s", err, rerr)
+	}
+	return err
+}
+
+// putBranch puts and commits blocks one by one, the state root after each
+// block must be its StateHash when it is set.
+func (service *MerkleService) putBranch(blocks []libblock.Block) error {
+	for _, b := range blocks {
+		err := service.putBlock(b)
+		if err != nil {
+			service.Cancel()
+			return err
+		}
+		root := service.GetStateRoot()
+		if len(b.GetStateHash()) > 0 && !bytes.Equal(root, b.GetStateHash()) {
+			service.Cancel()
+			return fmt.Errorf("block %d has state root %s, expected %s", b.GetIndex(), root.String(), b.GetStateHash().String())
+		}
+		err = service.Commit()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rollback removes the blocks after toIndex from the canonical chain, their
+// transactions and states are removed from the indexes, every state key gets
+// back its version at toIndex and the transaction and state roots are the
+// ones committed after block toIndex. The pending changes are discarded and
+// the removed blocks stay in the block trie. The roots of the trees are
+// written back together, see applyRollback.
+func (service *MerkleService) Rollback(toIndex uint64) error {
+	data, err := service.writeRollback(toIndex)
+	if err != nil {
+		return err
+	}
+	return service.applyRollback(data)
+}
+
+// writeRollback commits the index without its root and writes the rollback
+// record, which is returned.
+func (service *MerkleService) writeRollback(toIndex uint64) ([]byte, error) {
+	err := service.Cancel()
+	if err != nil {
+		return nil, err
+	}
+	head, ok, err := service.getHead()
+	if err != nil {
+		return nil, err
+	}
+	if !ok || toIndex > head {
+		return nil, fmt.Errorf("no block %d", toIndex)
+	}
+	if service.Mode == PrunedMode && toIndex+service.Retention < head {
+		return nil, fmt.Errorf("block %d is older than the retention of %d blocks", toIndex, service.Retention)
+	}
+
+	keys := make(map[string]bool)
+	for index := head; index > toIndex; index-- {
+		b, err := service.GetBlockByIndex(index)
+		if err != nil {
+			return nil, err
+		}
+		err = service.removeBlock(b, keys)
+		if err != nil {
+			service.Cancel()
+			return nil, err
+		}
+	}
+	for key := range keys {
+		err := service.restoreState(key, toIndex)
+		if err != nil {
+			service.Cancel()
+			return nil, err
+		}
+	}
+	err = service.im.PutData(headKey, putUint64(toIndex))
+	if err != nil {
+		service.Cancel()
+		return nil, err
+	}
+
+	rs := service.roots["index"]
+	rs.entries = make(map[string][]byte)
+	rs.deferred = true
+	err = service.im.Commit()
+	rs.deferred = false
+	if err != nil {
+		service.im.reload()
+		return nil, err
+	}
+	roots := map[string]map[string][]byte{"index": rs.entries}
+	for _, name := range []string{"transaction", "state"} {
+		entries, err := service.findRoots(name, toIndex)
+		if err != nil {
+			service.im.reload()
+			return nil, err
+		}
+		roots[name] = entries
+	}
+	data, err := encodeRollback(toIndex, head, roots)
+	if err != nil {
+		service.im.reload()
+		return nil, err
+	}
+	err = service.rootdb.PutData(rollbackKey, data)
+	if err != nil {
+		service.im.reload()
+		return nil, err
+	}
+	return data, nil
+}
+
+func (service *MerkleService) removeBlock(b libblock.Block, keys map[string]bool) error {
+	cs := service.CryptoService
+
+	err := service.removeDiff(b.GetIndex())
+	if err != nil {
+		return err
+	}
+	err = service.removePayments(b)
+	if err != nil {
+		return err
+	}
+
+	transactions := b.GetTransactions()
+	l := len(transactions)
+	for i := 0; i < l; i++ {
+		tx := transactions[i].GetTransaction()
+		txHash, _, err := cs.Raw(tx, libcrypto.RawBinary)
+		if err != nil {
+			return err
+		}
+		err = service.removeIndex(getHashKey("transaction", txHash))
+		if err != nil {
+			return err
+		}
+		address, err := tx.GetAccount().GetAddress()
+		if err != nil {
+			return err
+		}
+		err = service.removeIndex(getNameKey("transaction", getIndexKey(address, tx.GetIndex())))
+		if err != nil {
+			return err
+		}
+		err = service.removeActivity(tx, txHash)
+		if err != nil {
+			return err
+		}
+	}
+
+	states := b.GetStates()
+	l = len(states)
+	for i := 0; i < l; i++ {
+		s := states[i]
+		key := s.GetStateKey()
+		err := service.removeIndex(getNameKey("state", getIndexKey(key, s.GetIndex())))
+		if err != nil {
+			return err
+		}
+		keys[key] = true
+	}
+
+	err = service.removeIndex(getBlockKey(b.GetIndex()))
+	if err != nil {
+		return err
+	}
+	return service.removeIndex(getRootKey(b.GetIndex()))
+}
+
+// restoreState indexes the version of key at the end of block blockIndex as
+// the latest one, or removes the key if it did not exist yet.
+func (service *MerkleService) restoreState(key string, blockIndex uint64) error {
+	headKey := getNameKey("history", key)
+	newKey := getNameKey("state", key)
+
+	data, err := service.im.GetData([]byte(headKey))
+	if err != nil {
+		return err
+	}
+	index, ok := getUint64(data)
+	for ok {
+		logKey := getNameKey("history", getIndexKey(key, index))
+		data, err := service.im.GetData([]byte(logKey))
+		if err != nil {
+			return err
+		}
+		l := &stateLog{}
+		err = l.UnmarshalBinary(data)
+		if err != nil {
+			return err
+		}
+		if index <= blockIndex {
+			s, err := service.GetState(l.Hash)
+			if err != nil {
+				return err
+			}
+			err = service.im.PutData([]byte(getNameKey("state", getIndexKey(key, s.GetIndex()))), l.Hash)
+			if err != nil {
+				return err
+			}
+			err = service.im.PutData([]byte(newKey), l.Hash)
+			if err != nil {
+				return err
+			}
+			return service.im.PutData([]byte(headKey), putUint64(index))
+		}
+		index, ok = l.Prev, l.HasPrev
+	}
+
+	err = service.removeIndex(newKey)
+	if err != nil {
+		return err
+	}
+	return service.removeIndex(headKey)
+}
+
+// A trie addresses its nodes by their hash, any other entry it writes holds
+// its root. rootService records those entries so the root committed after
+// each block can be written back by a rollback, nothing else is assumed of
+// the entries. When deferred is set they are only recorded, the nodes are
+// still written.
+type rootService struct {
+	libstore.KvService
+
+	cs       libcrypto.CryptoService
+	entries  map[string][]byte
+	deferred bool
+}
+
+func isNode(cs libcrypto.CryptoService, key []byte, value []byte) (bool, error) {
+	h, err := cs.Hash(value)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(h, key), nil
+}
+
+func (rs *rootService) PutData(key []byte, value []byte) error {
+	return rs.PutDatas([][]byte{key}, [][]byte{value})
+}
+
+func (rs *rootService) PutDatas(keys [][]byte, values [][]byte) error {
+	nodeKeys := make([][]byte, 0, len(keys))
+	nodeValues := make([][]byte, 0, len(values))
+	l := len(keys)
+	for i := 0; i < l; i++ {
+		node, err := isNode(rs.cs, keys[i], values[i])
+		if err != nil {
+			return err
+		}
+		if !node {
+			rs.entries[string(keys[i])] = append([]byte(nil), values[i]...)
+			if rs.deferred {
+				continue
+			}
+		}
+		nodeKeys = append(nodeKeys, keys[i])
+		nodeValues = append(nodeValues, values[i])
+	}
+	if len(nodeKeys) == 0 {
+		return nil
+	}
+	return rs.KvService.PutDatas(nodeKeys, nodeValues)
+}
+
+func getRootEntriesKey(tree string, index uint64) []byte {
+	return []byte(fmt.Sprintf("%s@%d", tree, index))
+}
+
+func encodeRoots(entries map[string][]byte) ([]byte, error) {
+	var buf bytes.Buffer
+	for key, value := range entries {
+		err := core.WriteBytes(&buf, []byte(key))
+		if err != nil {
+			return nil, err
+		}
+		err = core.WriteBytes(&buf, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeRoots(data []byte) (map[string][]byte, error) {
+	entries := make(map[string][]byte)
+	r := bytes.NewReader(data)
+	for r.Len() > 0 {
+		key, err := core.ReadBytes(r)
+		if err != nil {
+			return nil, err
+		}
+		value, err := core.ReadBytes(r)
+		if err != nil {
+			return nil, err
+		}
+		entries[string(key)] = value
+	}
+	return entries, nil
+}
+
+// saveRoots stores the root entries written by the last commit under the
+// index of the head committed, they are kept until a block is committed when
+// the chain is empty.
+func (service *MerkleService) saveRoots() error {
+	head, ok, err := service.getHead()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	for name, rs := range service.roots {
+		if len(rs.entries) == 0 {
+			continue
+		}
+		data, err := encodeRoots(rs.entries)
+		if err != nil {
+			return err
+		}
+		err = service.rootdb.PutData(getRootEntriesKey(name, head), data)
+		if err != nil {
+			return err
+		}
+		rs.entries = make(map[string][]byte)
+	}
+	return nil
+}
+
+// getRoots returns the root entries of tree saved after block index, nil
+// when none were saved.
+func (service *MerkleService) getRoots(tree string, index uint64) (map[string][]byte, error) {
+	data, err := service.rootdb.GetData(getRootEntriesKey(tree, index))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return decodeRoots(data)
+}
+
+// findRoots returns the root entries of tree as they were after block index,
+// a block without entries left the root unchanged so the entries of the
+// blocks before it are used.
+func (service *MerkleService) findRoots(tree string, index uint64) (map[string][]byte, error) {
+	for i := index; ; i-- {
+		entries, err := service.getRoots(tree, i)
+		if err != nil {
+			return nil, err
+		}
+		if entries != nil {
+			return entries, nil
+		}
+		if i == 0 {
+			return nil, fmt.Errorf("no %s root of block %d", tree, index)
+		}
+	}
+}
+
+var rollbackKey = []byte("rollback")
+
+// A rollback writes the index nodes without the index root, then a record
+// in the root database with the root entries of every tree after it, and
+// applies the record. The record is written at once, a rollback stopped
+// before it leaves the chain unchanged and one stopped after it is applied
+// again by Init.
+
+func encodeRollback(toIndex uint64, head uint64, roots map[string]map[string][]byte) ([]byte, error) {
+	var buf bytes.Buffer
+	err := core.WriteBytes(&buf, putUint64(toIndex))
+	if err != nil {
+		return nil, err
+	}
+	err = core.WriteBytes(&buf, putUint64(head))
+	if err != nil {
+		return nil, err
+	}
+	for name, entries := range roots {
+		data, err := encodeRoots(entries)
+		if err != nil {
+			return nil, err
+		}
+		err = core.WriteBytes(&buf, []byte(name))
+		if err != nil {
+			return nil, err
+		}
+		err = core.WriteBytes(&buf, data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// applyRollback writes the root entries of the rollback record, reloads the
+// trees, removes the roots saved after the blocks rolled back and then the
+// record.
+func (service *MerkleService) applyRollback(data []byte) error {
+	r := bytes.NewReader(data)
+	values := make([]uint64, 2)
+	for i := 0; i < len(values); i++ {
+		value, err := core.ReadBytes(r)
+		if err != nil {
+			return err
+		}
+		index, ok := getUint64(value)
+		if !ok {
+			return errors.New("error rollback record")
+		}
+		values[i] = index
+	}
+	toIndex, head := values[0], values[1]
+
+	trees := service.trees()
+	for r.Len() > 0 {
+		name, err := core.ReadBytes(r)
+		if err != nil {
+			return err
+		}
+		value, err := core.ReadBytes(r)
+		if err != nil {
+			return err
+		}
+		rs, ok := service.roots[string(name)]
+		if !ok {
+			return fmt.Errorf("error rollback tree %s", string(name))
+		}
+		entries, err := decodeRoots(value)
+		if err != nil {
+			return err
+		}
+		for key, value := range entries {
+			err := rs.KvService.PutData([]byte(key), value)
+			if err != nil {
+				return err
+			}
+		}
+		rs.entries = make(map[string][]byte)
+		trees[string(name)].reload()
+	}
+	for name := range service.roots {
+		for index := toIndex + 1; index <= head; index++ {
+			err := service.rootdb.RemoveData(getRootEntriesKey(name, index))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	service.height = toIndex
+	return service.rootdb.RemoveData(rollbackKey)
+}
+
+// recoverRollback applies the rollback record left by a rollback which did
+// not complete.
+func (service *MerkleService) recoverRollback() error {
+	data, err := service.rootdb.GetData(rollbackKey)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return service.applyRollback(data)
+}
+
+// reload drops the pending changes and opens the root committed in the store.
+func (t *MerkleTree) reload() {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+
+	t.mt = mpt.New(t.cs, t.ss)
+}
+
+func init() {
+	err := RegisterMigration(2, "index chain head", func(service *MerkleService) error {
+		last := uint64(0)
+		for i := uint64(0); ; i = service.nextIndex(i) {
+			h, err := service.getBlockHash(i)
+			if err != nil {
+				return err
+			}
+			if h == nil {
+				if i == 0 {
+					return nil
+				}
+				return service.im.PutData(headKey, putUint64(last))
+			}
+			last = i
+			err = service.addBlockHash(i, h)
+			if err != nil {
+				return err
+			}
+			if (i+1)%migrateBatch == 0 {
+				err = service.Commit()
+				if err != nil {
+					return err
+				}
+			}
+		}
+	})
+	if err != nil {
+		panic(err)
+	}
+}
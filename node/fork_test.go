@@ -0,0 +1,188 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/tokentransfer/chain/block"
+
+	. "github.com/tokentransfer/check"
+	libcore "github.com/tokentransfer/interfaces/core"
+	libcrypto "github.com/tokentransfer/interfaces/crypto"
+)
+
+type ForkSuite struct{}
+
+func Test_Fork(t *testing.T) {
+	s := Suite(&ForkSuite{})
+	TestingRun(t, s)
+}
+
+// checkChain checks that the canonical chain of service is chain up to head.
+func checkChain(c *C, service *MerkleService, chain *testChain, head uint64) {
+	b, err := service.GetHead()
+	c.Assert(err, IsNil)
+	c.Assert(b.GetIndex(), Equals, head)
+	for i := uint64(0); i <= head; i++ {
+		b, err := service.GetBlockByIndex(i)
+		c.Assert(err, IsNil)
+		c.Assert([]byte(chain.hash(c, b)), DeepEquals, []byte(chain.hash(c, chain.blocks[i])))
+	}
+	c.Assert([]byte(service.GetStateRoot()), DeepEquals, []byte(chain.blocks[head].StateHash))
+	checkStatesAt(c, service, chain, head)
+	report, err := service.Verify()
+	c.Assert(err, IsNil)
+	c.Assert(report.Errors, IsNil)
+}
+
+func (suite *ForkSuite) TestReorg(c *C) {
+	chain := newTestChain(c, 3)
+	chain.grow(c, 6)
+	service := newTestService(c)
+	defer service.Close()
+	chain.putBlocks(c, service, 0)
+
+	branch := chain.fork(c, 2)
+	branch.grow(c, 5)
+	for i := uint64(3); i <= 6; i++ {
+		err := service.PutBlock(branch.blocks[i])
+		c.Assert(err, IsNil)
+		err = service.Commit()
+		c.Assert(err, IsNil)
+	}
+	checkChain(c, service, chain, 6)
+	blocks, err := service.GetBlocksByIndex(4)
+	c.Assert(err, IsNil)
+	c.Assert(len(blocks), Equals, 2)
+
+	// the longer branch replaces the chain
+	err = service.PutBlock(branch.blocks[7])
+	c.Assert(err, IsNil)
+	err = service.Commit()
+	c.Assert(err, IsNil)
+	checkChain(c, service, branch, 7)
+
+	tx := chain.blocks[4].GetTransactions()[0].GetTransaction()
+	h, _, err := chain.cs.Raw(tx, libcrypto.RawBinary)
+	c.Assert(err, IsNil)
+	_, err = service.GetTransactionByHash(h)
+	c.Assert(err, NotNil)
+}
+
+func (suite *ForkSuite) TestBadBranch(c *C) {
+	chain := newTestChain(c, 3)
+	chain.grow(c, 6)
+	service := newTestService(c)
+	defer service.Close()
+	chain.putBlocks(c, service, 0)
+
+	// the last block of the longer branch has a wrong state root, the chain
+	// comes back once the branch fails
+	branch := chain.fork(c, 2)
+	branch.grow(c, 5)
+	branch.blocks[7].StateHash = chain.blocks[6].StateHash
+	for i := uint64(3); i <= 6; i++ {
+		err := service.PutBlock(branch.blocks[i])
+		c.Assert(err, IsNil)
+		err = service.Commit()
+		c.Assert(err, IsNil)
+	}
+	err := service.PutBlock(branch.blocks[7])
+	c.Assert(err, NotNil)
+	checkChain(c, service, chain, 6)
+	c.Assert(service.height, Equals, uint64(6))
+
+	tx := chain.blocks[4].GetTransactions()[0].GetTransaction()
+	h, _, err := chain.cs.Raw(tx, libcrypto.RawBinary)
+	c.Assert(err, IsNil)
+	_, err = service.GetTransactionByHash(h)
+	c.Assert(err, IsNil)
+}
+
+func (suite *ForkSuite) TestUnknownParent(c *C) {
+	chain := newTestChain(c, 3)
+	chain.grow(c, 4)
+	service := newTestService(c)
+	defer service.Close()
+	chain.putBlocks(c, service, 0)
+
+	// a block of another chain at the next index
+	other := newTestChain(c, 2)
+	other.grow(c, 5)
+	err := service.PutBlock(other.blocks[5])
+	c.Assert(err, NotNil)
+
+	// a block with no parent after the head
+	b := &block.Block{BlockIndex: 5, ParentHash: libcore.Hash(make([]byte, 32)), Timestamp: 300}
+	err = service.PutBlock(b)
+	c.Assert(err, NotNil)
+	b.ParentHash = nil
+	err = service.PutBlock(b)
+	c.Assert(err, NotNil)
+
+	// a block whose parent is not put yet
+	next := chain.fork(c, 4)
+	next.grow(c, 2)
+	err = service.PutBlock(next.blocks[6])
+	c.Assert(err, NotNil)
+	checkChain(c, service, chain, 4)
+
+	next.putBlocks(c, service, 5)
+	checkChain(c, service, next, 6)
+}
+
+func (suite *ForkSuite) TestRollback(c *C) {
+	chain := newTestChain(c, 3)
+	chain.grow(c, 8)
+	dir := c.MkDir()
+	service := openTestService(c, dir, &MerkleService{})
+	chain.putBlocks(c, service, 0)
+
+	err := service.Rollback(3)
+	c.Assert(err, IsNil)
+	checkChain(c, service, chain, 3)
+	root, err := service.GetStateRootAt(3)
+	c.Assert(err, IsNil)
+	c.Assert([]byte(root), DeepEquals, []byte(chain.blocks[3].StateHash))
+	_, err = service.GetStateRootAt(4)
+	c.Assert(err, NotNil)
+
+	chain.putBlocks(c, service, 4)
+	checkChain(c, service, chain, 8)
+
+	// a rollback stopped after its record is completed by Init
+	_, err = service.writeRollback(2)
+	c.Assert(err, IsNil)
+	err = service.Close()
+	c.Assert(err, IsNil)
+	service = openTestService(c, dir, &MerkleService{})
+	defer service.Close()
+	checkChain(c, service, chain, 2)
+	data, err := service.rootdb.GetData(rollbackKey)
+	c.Assert(err, IsNil)
+	c.Assert(len(data), Equals, 0)
+
+	chain.putBlocks(c, service, 3)
+	checkChain(c, service, chain, 8)
+}
+
+func (suite *ForkSuite) TestPrunedRollback(c *C) {
+	chain := newTestChain(c, 3)
+	chain.grow(c, 10)
+	service := openTestService(c, c.MkDir(), &MerkleService{Mode: PrunedMode, Retention: 4})
+	defer service.Close()
+	chain.putBlocks(c, service, 0)
+	err := service.Prune()
+	c.Assert(err, IsNil)
+
+	err = service.Rollback(5)
+	c.Assert(err, NotNil)
+	err = service.Rollback(7)
+	c.Assert(err, IsNil)
+	b, err := service.GetHead()
+	c.Assert(err, IsNil)
+	c.Assert(b.GetIndex(), Equals, uint64(7))
+	c.Assert([]byte(service.GetStateRoot()), DeepEquals, []byte(chain.blocks[7].StateHash))
+
+	chain.putBlocks(c, service, 8)
+	c.Assert([]byte(service.GetStateRoot()), DeepEquals, []byte(chain.head().StateHash))
+}
@@ -0,0 +1,263 @@
+package node
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/tokentransfer/go-MerklePatriciaTree/mpt"
+
+	libblock "github.com/tokentransfer/interfaces/block"
+	libcore "github.com/tokentransfer/interfaces/core"
+	libcrypto "github.com/tokentransfer/interfaces/crypto"
+	libstore "github.com/tokentransfer/interfaces/store"
+)
+
+// Every state key has a chain of versions in the index, "history@key" holds
+// the block index of the latest version and "history@key:N" the state hash
+// written in block N with the block index of the version before it.
+
+type stateLog struct {
+	Hash    libcore.Hash
+	HasPrev bool
+	Prev    uint64
+}
+
+func (l *stateLog) MarshalBinary() []byte {
+	data := make([]byte, 9+len(l.Hash))
+	if l.HasPrev {
+		data[0] = 1
+	}
+	binary.BigEndian.PutUint64(data[1:9], l.Prev)
+	copy(data[9:], l.Hash)
+	return data
+}
+
+func (l *stateLog) UnmarshalBinary(data []byte) error {
+	if len(data) < 9 {
+		return errors.New("error state history")
+	}
+	l.HasPrev = data[0] == 1
+	l.Prev = binary.BigEndian.Uint64(data[1:9])
+	l.Hash = libcore.Hash(data[9:])
+	return nil
+}
+
+func getUint64(data []byte) (uint64, bool) {
+	if len(data) != 8 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(data), true
+}
+
+func putUint64(i uint64) []byte {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, i)
+	return data
+}
+
+const migrateBatch = 1000
+
+func getRootKey(index uint64) string {
+	return fmt.Sprintf("root@%d", index)
+}
+
+func (service *MerkleService) getBlockHash(index uint64) (libcore.Hash, error) {
+	data, err := service.im.GetData([]byte(getBlockKey(index)))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return libcore.Hash(data), nil
+}
+
+func (service *MerkleService) putStateLog(key string, blockIndex uint64, h libcore.Hash) error {
+	headKey := getNameKey("history", key)
+	logKey := getNameKey("history", getIndexKey(key, blockIndex))
+
+	l := &stateLog{Hash: h}
+	head, err := service.im.GetData([]byte(headKey))
+	if err != nil {
+		return err
+	}
+	last, ok := getUint64(head)
+	if ok {
+		if last > blockIndex {
+			return fmt.Errorf("state %s of block %d is older than block %d", key, blockIndex, last)
+		}
+		if last == blockIndex {
+			data, err := service.im.GetData([]byte(logKey))
+			if err != nil {
+				return err
+			}
+			old := &stateLog{}
+			err = old.UnmarshalBinary(data)
+			if err != nil {
+				return err
+			}
+			l.HasPrev = old.HasPrev
+			l.Prev = old.Prev
+		} else {
+			l.HasPrev = true
+			l.Prev = last
+		}
+	}
+
+	err = service.im.PutData([]byte(logKey), l.MarshalBinary())
+	if err != nil {
+		return err
+	}
+	return service.im.PutData([]byte(headKey), putUint64(blockIndex))
+}
+
+// rootView is a read only view of the store of a trie whose root entries are
+// entries, a trie opened on it is the trie of these entries. The other
+// entries which are not addressed by their hash are hidden.
+type rootView struct {
+	libstore.KvService
+
+	cs      libcrypto.CryptoService
+	entries map[string][]byte
+}
+
+func (v *rootView) GetData(key []byte) ([]byte, error) {
+	value, ok := v.entries[string(key)]
+	if ok {
+		return value, nil
+	}
+	data, err := v.KvService.GetData(key)
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+	node, err := isNode(v.cs, key, data)
+	if err != nil || !node {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (v *rootView) PutData(key []byte, value []byte) error {
+	return errors.New("read only store")
+}
+
+func (v *rootView) PutDatas(keys [][]byte, values [][]byte) error {
+	return errors.New("read only store")
+}
+
+func (v *rootView) RemoveData(key []byte) error {
+	return errors.New("read only store")
+}
+
+// GetStateAt returns the state of key as it was at the end of block
+// blockIndex, it is read from the index trie opened at the root saved after
+// the block. The versions of the key are followed from the latest one when
+// no root was saved after the block, for the blocks committed in a batch or
+// before the roots were saved.
+func (service *MerkleService) GetStateAt(key string, blockIndex uint64) (libblock.State, error) {
+	entries, err := service.getRoots("index", blockIndex)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		return service.getStateLogAt(key, blockIndex)
+	}
+	view := &rootView{
+		KvService: service.roots["index"].KvService,
+		cs:        service.CryptoService,
+		entries:   entries,
+	}
+	h, err := mpt.New(service.CryptoService, view).Get([]byte(getNameKey("state", key)))
+	if err != nil {
+		return nil, err
+	}
+	if len(h) == 0 {
+		return nil, fmt.Errorf("no state %s at block %d", key, blockIndex)
+	}
+	return service.GetState(libcore.Hash(h))
+}
+
+func (service *MerkleService) getStateLogAt(key string, blockIndex uint64) (libblock.State, error) {
+	headKey := getNameKey("history", key)
+	head, err := service.im.GetData([]byte(headKey))
+	if err != nil {
+		return nil, err
+	}
+	index, ok := getUint64(head)
+	if !ok {
+		return nil, fmt.Errorf("no state %s", key)
+	}
+	for {
+		logKey := getNameKey("history", getIndexKey(key, index))
+		data, err := service.im.GetData([]byte(logKey))
+		if err != nil {
+			return nil, err
+		}
+		l := &stateLog{}
+		err = l.UnmarshalBinary(data)
+		if err != nil {
+			return nil, err
+		}
+		if index <= blockIndex {
+			return service.GetState(l.Hash)
+		}
+		if !l.HasPrev {
+			return nil, fmt.Errorf("no state %s at block %d", key, blockIndex)
+		}
+		index = l.Prev
+	}
+}
+
+// GetStateRootAt returns the state root recorded after block blockIndex.
+func (service *MerkleService) GetStateRootAt(blockIndex uint64) (libcore.Hash, error) {
+	data, err := service.im.GetData([]byte(getRootKey(blockIndex)))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("no state root at block %d", blockIndex)
+	}
+	return libcore.Hash(data), nil
+}
+
+func init() {
+	err := RegisterMigration(1, "index state history", func(service *MerkleService) error {
+		for i := uint64(0); ; i = service.nextIndex(i) {
+			h, err := service.getBlockHash(i)
+			if err != nil {
+				return err
+			}
+			if h == nil {
+				return nil
+			}
+			if service.isHeader(i) {
+				continue
+			}
+			b, err := service.GetBlockByHash(h)
+			if err != nil {
+				return err
+			}
+			states := b.GetStates()
+			for _, s := range states {
+				sh, _, err := service.CryptoService.Raw(s, libcrypto.RawBinary)
+				if err != nil {
+					return err
+				}
+				err = service.putStateLog(s.GetStateKey(), s.GetBlockIndex(), sh)
+				if err != nil {
+					return err
+				}
+			}
+			if (i+1)%migrateBatch == 0 {
+				err = service.Commit()
+				if err != nil {
+					return err
+				}
+			}
+		}
+	})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,54 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/tokentransfer/chain/block"
+
+	. "github.com/tokentransfer/check"
+)
+
+type HistorySuite struct{}
+
+func Test_History(t *testing.T) {
+	s := Suite(&HistorySuite{})
+	TestingRun(t, s)
+}
+
+// checkStatesAt checks the state of every account at every block up to head
+// against the chain.
+func checkStatesAt(c *C, service *MerkleService, chain *testChain, head uint64) {
+	for i := uint64(0); i <= head; i++ {
+		at := chain.fork(c, i)
+		for j := 0; j < len(chain.accounts); j++ {
+			address, err := chain.accounts[j].GetAddress()
+			c.Assert(err, IsNil)
+			expected, err := at.getState(c, j)
+			c.Assert(err, IsNil)
+			s, err := service.GetStateAt(address, i)
+			c.Assert(err, IsNil)
+			c.Assert(s.GetBlockIndex(), Equals, expected.GetBlockIndex())
+			c.Assert(s.(*block.AccountState).Amount, Equals, expected.Amount)
+		}
+		root, err := service.GetStateRootAt(i)
+		c.Assert(err, IsNil)
+		c.Assert([]byte(root), DeepEquals, []byte(chain.blocks[i].StateHash))
+	}
+}
+
+func (suite *HistorySuite) TestGetStateAt(c *C) {
+	chain := newTestChain(c, 3)
+	chain.grow(c, 8)
+	service := newTestService(c)
+	defer service.Close()
+	chain.putBlocks(c, service, 0)
+
+	checkStatesAt(c, service, chain, 8)
+	_, err := service.GetStateAt("unknown", 3)
+	c.Assert(err, NotNil)
+
+	// the versions are followed without the saved root
+	err = service.rootdb.RemoveData(getRootEntriesKey("index", 3))
+	c.Assert(err, IsNil)
+	checkStatesAt(c, service, chain, 8)
+}
@@ -0,0 +1,95 @@
+package node
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/tokentransfer/chain/block"
+
+	libcore "github.com/tokentransfer/interfaces/core"
+	libcrypto "github.com/tokentransfer/interfaces/crypto"
+)
+
+// LogEntry is a log with the position of its transaction in the chain.
+type LogEntry struct {
+	BlockIndex       uint64
+	TransactionHash  libcore.Hash
+	TransactionIndex int
+	LogIndex         int
+	Log              *block.Log
+}
+
+func blockMayMatch(b *block.Block, topics []string) bool {
+	if len(b.LogsBloom) == 0 {
+		return false
+	}
+	for i := 0; i < len(topics); i++ {
+		if len(topics[i]) > 0 && !b.MayHaveTopic(topics[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterLogs returns the logs of the canonical blocks fromBlock..toBlock
+// included whose topics start with topics, an empty topic matches any topic.
+// The blocks are skipped with their logs bloom filter, toBlock is capped at
+// the head of the chain.
+func (service *MerkleService) FilterLogs(fromBlock uint64, toBlock uint64, topics []string) ([]LogEntry, error) {
+	cs := service.CryptoService
+
+	if toBlock < fromBlock {
+		return nil, fmt.Errorf("error log range %d-%d", fromBlock, toBlock)
+	}
+	head, ok, err := service.getHead()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]LogEntry, 0)
+	if !ok {
+		return list, nil
+	}
+	if toBlock > head {
+		toBlock = head
+	}
+	for i := fromBlock; i <= toBlock; i++ {
+		lb, err := service.GetBlockByIndex(i)
+		if err != nil {
+			return nil, err
+		}
+		b, ok := lb.(*block.Block)
+		if !ok || !blockMayMatch(b, topics) {
+			continue
+		}
+		txs := b.GetTransactions()
+		for j := 0; j < len(txs); j++ {
+			logs := block.GetLogs(txs[j])
+			for k := 0; k < len(logs); k++ {
+				if !logs[k].Matches(topics) {
+					continue
+				}
+				txHash, _, err := cs.Raw(txs[j].GetTransaction(), libcrypto.RawBinary)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, LogEntry{
+					BlockIndex:       i,
+					TransactionHash:  txHash,
+					TransactionIndex: j,
+					LogIndex:         k,
+					Log:              logs[k],
+				})
+			}
+		}
+	}
+	return list, nil
+}
+
+// checkLogsBloom reports a block whose logs bloom filter is not the filter
+// of its logs.
+func checkLogsBloom(r *VerifyReport, b *block.Block) {
+	bloom := block.CreateLogsBloom(b.GetTransactions())
+	if !bytes.Equal(bloom, b.LogsBloom) {
+		r.addError("block %d has a wrong logs bloom", b.GetIndex())
+	}
+}
@@ -0,0 +1,90 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/tokentransfer/chain/block"
+	"github.com/tokentransfer/chain/core"
+
+	. "github.com/tokentransfer/check"
+)
+
+type LogsSuite struct{}
+
+func Test_Logs(t *testing.T) {
+	s := Suite(&LogsSuite{})
+	TestingRun(t, s)
+}
+
+func (suite *LogsSuite) TestFilterLogs(c *C) {
+	chain := newTestChain(c, 3)
+	service := newTestService(c)
+	defer service.Close()
+	chain.putBlocks(c, service, 0)
+	addresses := make([]string, 3)
+	for i := 0; i < 3; i++ {
+		a, err := chain.accounts[i].GetAddress()
+		c.Assert(err, IsNil)
+		addresses[i] = a
+	}
+
+	newDevice := &block.NewDevice{
+		Transaction: chain.deviceTransaction(1, core.CORE_NEWDEVICE),
+		Symbol:      "sensor",
+		DeviceTags:  []string{"temperature"},
+	}
+	err := chain.cs.Sign(chain.keys[1], newDevice)
+	c.Assert(err, IsNil)
+	b1 := buildBlock(c, service, nil, 60,
+		chain.payment(c, 0, 1, 5, 1),
+		newDevice,
+		chain.payment(c, 2, 0, 2000000, 1),
+	)
+	c.Assert(b1.Transactions[2].GetReceipt().GetTransactionResult(), Equals, block.ResultInsufficientBalance)
+	// the failed payment only increases the sequence of its sender
+	c.Assert(len(b1.States), Equals, 4)
+
+	transfer := &block.TransferDevice{
+		Transaction: chain.deviceTransaction(1, core.CORE_TRANSFERDEVICE),
+		Symbol:      "sensor",
+	}
+	transfer.Destination = chain.accounts[2]
+	err = chain.cs.Sign(chain.keys[1], transfer)
+	c.Assert(err, IsNil)
+	update := &block.UpdateDevice{
+		Transaction: chain.deviceTransaction(1, core.CORE_UPDATEDEVICE),
+		Symbol:      "sensor",
+	}
+	err = chain.cs.Sign(chain.keys[1], update)
+	c.Assert(err, IsNil)
+	b2 := buildBlock(c, service, nil, 120, transfer, update)
+	c.Assert(b2.Transactions[1].GetReceipt().GetTransactionResult(), Equals, block.ResultDeviceNotOwned)
+
+	list, err := service.FilterLogs(0, 10, nil)
+	c.Assert(err, IsNil)
+	c.Assert(len(list), Equals, 3)
+	c.Assert(list[0].BlockIndex, Equals, uint64(1))
+	c.Assert(list[0].Log.Topics, DeepEquals, []string{block.EventTransfer, addresses[0], addresses[1]})
+	c.Assert([]byte(list[0].Log.Data), DeepEquals, []byte{0, 0, 0, 0, 0, 0, 0, 5})
+	c.Assert(list[1].TransactionIndex, Equals, 1)
+	c.Assert(list[1].Log.Topics, DeepEquals, []string{block.EventDeviceRegistered, "sensor", addresses[1]})
+	c.Assert(list[2].BlockIndex, Equals, uint64(2))
+	c.Assert(list[2].Log.Topics, DeepEquals, []string{block.EventDeviceTransferred, "sensor", addresses[1], addresses[2]})
+
+	list, err = service.FilterLogs(0, 10, []string{"", "sensor"})
+	c.Assert(err, IsNil)
+	c.Assert(len(list), Equals, 2)
+	list, err = service.FilterLogs(2, 10, []string{block.EventDeviceRegistered})
+	c.Assert(err, IsNil)
+	c.Assert(len(list), Equals, 0)
+
+	s, err := service.GetStateByKey(addresses[0])
+	c.Assert(err, IsNil)
+	c.Assert(s.(*block.AccountState).Amount, Equals, int64(1000000-5))
+	d, err := service.getDevice("sensor")
+	c.Assert(err, IsNil)
+	c.Assert(d.Sequence, Equals, uint64(2))
+	report, err := service.Verify()
+	c.Assert(err, IsNil)
+	c.Assert(report.Errors, IsNil)
+}
@@ -1,8 +1,11 @@
 package node
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/tokentransfer/go-MerklePatriciaTree/mpt"
 
@@ -13,19 +16,23 @@ import (
 	libblock "github.com/tokentransfer/interfaces/block"
 	libcore "github.com/tokentransfer/interfaces/core"
 	libcrypto "github.com/tokentransfer/interfaces/crypto"
-	libnode "github.com/tokentransfer/interfaces/node"
 	libstore "github.com/tokentransfer/interfaces/store"
 )
 
 type MerkleTree struct {
-	mt     *mpt.Trie
-	locker *sync.RWMutex
+	mt      *mpt.Trie
+	cs      libcrypto.CryptoService
+	ss      libstore.KvService
+	journal func(key []byte) error
+	locker  *sync.RWMutex
 }
 
 func NewMerkleTree(cs libcrypto.CryptoService, ss libstore.KvService) *MerkleTree {
 	mt := mpt.New(cs, ss)
 	return &MerkleTree{
 		mt:     mt,
+		cs:     cs,
+		ss:     ss,
 		locker: &sync.RWMutex{},
 	}
 }
@@ -62,50 +69,199 @@ func (t *MerkleTree) PutData(key, value []byte) error {
 	t.locker.Lock()
 	defer t.locker.Unlock()
 
-	return t.mt.Put(key, value)
+	err := t.mt.Put(key, value)
+	if err != nil {
+		return err
+	}
+	if t.journal != nil {
+		return t.journal(key)
+	}
+	return nil
 }
 
 type MerkleService struct {
 	config libcore.Config
 
-	im libnode.MerkleTree // index -> hash
-	bm libnode.MerkleTree // block
-	tm libnode.MerkleTree // transaction
-	sm libnode.MerkleTree // state
+	im *MerkleTree // index -> hash
+	bm *MerkleTree // block
+	tm *MerkleTree // transaction
+	sm *MerkleTree // state
 
 	CryptoService *crypto.CryptoService
+
+	// EncryptionKeys enables the encryption at rest of the databases, the last
+	// key is used for new data, see store.EncryptedService.
+	EncryptionKeys [][]byte
+	EncryptKeys    bool
+
+	// CacheSize is the number of raw entries cached in front of each
+	// database and ObjectCacheSize the number of blocks, transactions and
+	// states cached by hash, zero disables them. The objects are kept
+	// encoded, each read decodes a new object owned by the caller.
+	CacheSize       int
+	ObjectCacheSize int
+
+	// ChainID and GenesisHash, when set, must match the metadata of the
+	// data directory. The transactions put must be signed for the chain of
+	// the metadata, the ones of the blocks before its ChainHeight may also
+	// be signed for chain 0.
+	ChainID     uint32
+	GenesisHash libcore.Hash
+
+	metadb  libstore.KvService
+	meta    *Metadata
+	genesis libcore.Hash
+
+	// Mode selects archive or pruned storage, in pruned mode the nodes
+	// written in the last Retention blocks are always kept, Init fails when
+	// Retention is 0.
+	Mode      StorageMode
+	Retention uint64
+
+	prunedb libstore.KvService
+	height  uint64
+	pruned  uint64
+
+	// ForkChoice decides if a branch replaces the canonical chain, it is
+	// LongestChain when not set.
+	ForkChoice ForkChoice
+
+	// Progress, when set, is called with the index of each block exported
+	// or imported.
+	Progress func(blockIndex uint64)
+
+	// GasSchedule and FeeMarket, when set, charge the fees of the blocks
+	// built by BuildBlock, the GasUsed and BaseFee of the blocks put and
+	// verified are checked against them.
+	GasSchedule *block.GasSchedule
+	FeeMarket   *block.FeeMarket
+
+	// GetValidators returns the validators of block index, see
+	// consensus.PoA, the validator transactions of the blocks built by
+	// BuildBlock fail when it is not set.
+	GetValidators func(index uint64) []libcore.PublicKey
+
+	rootdb libstore.KvService
+	roots  map[string]*rootService
+
+	stores      []libstore.KvService
+	storeCaches map[string]*store.CachedService
+	blockCache  *store.Cache
+	txCache     *store.Cache
+	stateCache  *store.Cache
+}
+
+func (service *MerkleService) openStore(name string) (libstore.KvService, error) {
+	var db libstore.KvService = &store.LevelService{Name: name}
+	if len(service.EncryptionKeys) > 0 {
+		db = &store.EncryptedService{
+			Service:     db,
+			Keys:        service.EncryptionKeys,
+			EncryptKeys: service.EncryptKeys,
+		}
+	}
+	if service.CacheSize > 0 {
+		cached := &store.CachedService{
+			Service: db,
+			Size:    service.CacheSize,
+		}
+		service.storeCaches[name] = cached
+		db = cached
+	}
+	err := db.Init(service.config)
+	if err != nil {
+		return nil, err
+	}
+	service.stores = append(service.stores, db)
+	return db, nil
+}
+
+func (service *MerkleService) newTree(name string) (*MerkleTree, error) {
+	db, err := service.openStore(name)
+	if err != nil {
+		return nil, err
+	}
+	if service.Mode == PrunedMode {
+		db = &journalService{KvService: db, tree: name, service: service}
+	}
+	if name == "index" || name == "transaction" || name == "state" {
+		rs := &rootService{KvService: db, cs: service.CryptoService, entries: make(map[string][]byte)}
+		service.roots[name] = rs
+		db = rs
+	}
+	t := NewMerkleTree(service.CryptoService, db)
+	if service.Mode == PrunedMode {
+		t.journal = service.journalKey(name)
+	}
+	return t, nil
 }
 
 func (service *MerkleService) Init(c libcore.Config) error {
+	var err error
+
 	service.config = c
+	service.storeCaches = make(map[string]*store.CachedService)
+	service.roots = make(map[string]*rootService)
+	if service.ObjectCacheSize > 0 {
+		service.blockCache = store.NewCache(service.ObjectCacheSize)
+		service.txCache = store.NewCache(service.ObjectCacheSize)
+		service.stateCache = store.NewCache(service.ObjectCacheSize)
+	}
+	if service.Mode == PrunedMode {
+		if service.Retention == 0 {
+			return errors.New("error retention 0 in pruned mode")
+		}
+		service.prunedb, err = service.openStore("prune")
+		if err != nil {
+			return err
+		}
+	}
 
-	indexdb := &store.LevelService{Name: "index"}
-	err := indexdb.Init(c)
+	service.rootdb, err = service.openStore("root")
 	if err != nil {
 		return err
 	}
-	service.im = NewMerkleTree(service.CryptoService, indexdb)
 
-	blockdb := &store.LevelService{Name: "block"}
-	err = blockdb.Init(c)
+	service.im, err = service.newTree("index")
+	if err != nil {
+		return err
+	}
+	service.bm, err = service.newTree("block")
+	if err != nil {
+		return err
+	}
+	service.tm, err = service.newTree("transaction")
+	if err != nil {
+		return err
+	}
+	service.sm, err = service.newTree("state")
+	if err != nil {
+		return err
+	}
+	err = service.recoverRollback()
 	if err != nil {
 		return err
 	}
-	service.bm = NewMerkleTree(service.CryptoService, blockdb)
 
-	txdb := &store.LevelService{Name: "transaction"}
-	err = txdb.Init(c)
+	head, ok, err := service.getHead()
 	if err != nil {
 		return err
 	}
-	service.tm = NewMerkleTree(service.CryptoService, txdb)
+	if ok {
+		service.height = head
+	}
 
-	statedb := &store.LevelService{Name: "state"}
-	err = statedb.Init(c)
+	service.metadb, err = service.openStore("meta")
 	if err != nil {
 		return err
 	}
-	service.sm = NewMerkleTree(service.CryptoService, statedb)
+	err = service.checkMetadata()
+	if err != nil {
+		return err
+	}
+	if service.Mode == PrunedMode {
+		return service.initJournal()
+	}
 	return nil
 }
 
@@ -113,8 +269,60 @@ func (service *MerkleService) Start() error {
 	return nil
 }
 
+// Close closes the databases, the pending changes are lost.
 func (service *MerkleService) Close() error {
-	return nil
+	var ret error
+	l := len(service.stores)
+	for i := 0; i < l; i++ {
+		err := service.stores[i].Close()
+		if err != nil && ret == nil {
+			ret = err
+		}
+	}
+	service.stores = nil
+	return ret
+}
+
+// CacheStats returns the hits and misses of the enabled caches, by database
+// name for the raw caches and by object type for the decoded ones.
+func (service *MerkleService) CacheStats() map[string]store.CacheStats {
+	stats := make(map[string]store.CacheStats)
+	for name, cached := range service.storeCaches {
+		stats[getNameKey("store", name)] = cached.Stats()
+	}
+	if service.blockCache != nil {
+		stats["block"] = service.blockCache.Stats()
+		stats["transaction"] = service.txCache.Stats()
+		stats["state"] = service.stateCache.Stats()
+	}
+	return stats
+}
+
+func (service *MerkleService) purgeCaches() {
+	if service.blockCache != nil {
+		service.blockCache.Purge()
+		service.txCache.Purge()
+		service.stateCache.Purge()
+	}
+}
+
+// getCached returns the encoded object of hash h, the data must not be
+// changed.
+func getCached(c *store.Cache, h libcore.Hash) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	o, ok := c.Get(string(h))
+	if !ok {
+		return nil, false
+	}
+	return o.([]byte), true
+}
+
+func addCached(c *store.Cache, h libcore.Hash, data []byte) {
+	if c != nil {
+		c.Add(string(h), append([]byte(nil), data...))
+	}
 }
 
 func (service *MerkleService) PutState(s libblock.State) error {
@@ -128,11 +336,15 @@ func (service *MerkleService) PutState(s libblock.State) error {
 	if err != nil {
 		return err
 	}
+	return service.indexState(s, h)
+}
 
+// indexState indexes the state s of hash h, which is in the state trie.
+func (service *MerkleService) indexState(s libblock.State, h libcore.Hash) error {
 	key := s.GetStateKey()
 	indexKey := getIndexKey(key, s.GetIndex())
 	stateKey := getNameKey("state", indexKey)
-	err = service.im.PutData([]byte(stateKey), h)
+	err := service.im.PutData([]byte(stateKey), h)
 	if err != nil {
 		return err
 	}
@@ -142,18 +354,34 @@ func (service *MerkleService) PutState(s libblock.State) error {
 	if err != nil {
 		return err
 	}
-	return nil
+
+	d, ok := s.(*block.DeviceState)
+	if ok {
+		err = service.putDevice(d)
+		if err != nil {
+			return err
+		}
+	}
+
+	return service.putStateLog(key, s.GetBlockIndex(), h)
 }
 
 func (service *MerkleService) GetState(h libcore.Hash) (libblock.State, error) {
-	data, err := service.sm.GetData(h)
-	if err != nil {
-		return nil, err
+	data, ok := getCached(service.stateCache, h)
+	if !ok {
+		var err error
+		data, err = service.sm.GetData(h)
+		if err != nil {
+			return nil, err
+		}
 	}
 	state, err := block.ReadState(data)
 	if err != nil {
 		return nil, err
 	}
+	if !ok {
+		addCached(service.stateCache, h, data)
+	}
 	return state, nil
 }
 
@@ -180,20 +408,85 @@ func (service *MerkleService) GetStateRoot() libcore.Hash {
 	return service.sm.GetRoot()
 }
 
+// PutTransaction puts a new transaction, it must be signed for the chain of
+// the metadata and be valid in the next block at the current time.
 func (service *MerkleService) PutTransaction(txWithData libblock.TransactionWithData) error {
+	err := crypto.CheckChainID(txWithData.GetTransaction(), service.meta.ChainID)
+	if err != nil {
+		return err
+	}
+	err = checkValidityWindow(txWithData, service.height+1, time.Now().Unix())
+	if err != nil {
+		return err
+	}
+	h, err := service.storeTransaction(txWithData)
+	if err != nil {
+		return err
+	}
+	return service.indexTransaction(txWithData, h)
+}
+
+// checkChainID checks the chain of a transaction of block blockIndex, the
+// blocks before the ChainHeight of the metadata accept chain 0.
+func (service *MerkleService) checkChainID(tx libblock.Transaction, blockIndex uint64) error {
+	if blockIndex < service.meta.ChainHeight {
+		err := crypto.CheckChainID(tx, 0)
+		if err == nil {
+			return nil
+		}
+	}
+	return crypto.CheckChainID(tx, service.meta.ChainID)
+}
+
+// checkValidityWindow checks that a transaction which did not fail is valid
+// in the block of index blockIndex and of time timestamp, a *block.ResultError
+// ResultNotYetValid or ResultExpired is returned otherwise.
+func checkValidityWindow(txWithData libblock.TransactionWithData, blockIndex uint64, timestamp int64) error {
+	tx, ok := txWithData.GetTransaction().(validityChecker)
+	if !ok {
+		return nil
+	}
+	receipt := txWithData.GetReceipt()
+	if receipt != nil && receipt.GetTransactionResult() != block.ResultSuccess {
+		return nil
+	}
+	result := tx.CheckValidity(blockIndex, timestamp)
+	if result != block.ResultSuccess {
+		return &block.ResultError{Result: result, Message: txWithData.GetTransaction().GetHash().String()}
+	}
+	return nil
+}
+
+// storeTransaction puts txWithData in the transaction trie, its hash is
+// returned.
+func (service *MerkleService) storeTransaction(txWithData libblock.TransactionWithData) (libcore.Hash, error) {
 	cs := service.CryptoService
 
 	h, data, err := cs.Raw(txWithData, libcrypto.RawBinary)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	err = service.tm.PutData(h, data)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return h, nil
+}
+
+// indexTransaction indexes the transaction txWithData of hash h, which is
+// in the transaction trie.
+func (service *MerkleService) indexTransaction(txWithData libblock.TransactionWithData, h libcore.Hash) error {
+	cs := service.CryptoService
 
 	txHash, _, err := cs.Raw(txWithData.GetTransaction(), libcrypto.RawBinary)
+	if err != nil {
+		return err
+	}
 	txKey := getHashKey("transaction", txHash)
+	err = service.putActivity(txWithData.GetTransaction(), txHash)
+	if err != nil {
+		return err
+	}
 	err = service.im.PutData([]byte(txKey), h)
 	if err != nil {
 		return err
@@ -215,9 +508,13 @@ func (service *MerkleService) PutTransaction(txWithData libblock.TransactionWith
 }
 
 func (service *MerkleService) GetTransaction(h libcore.Hash) (libblock.TransactionWithData, error) {
-	data, err := service.tm.GetData(h)
-	if err != nil {
-		return nil, err
+	data, ok := getCached(service.txCache, h)
+	if !ok {
+		var err error
+		data, err = service.tm.GetData(h)
+		if err != nil {
+			return nil, err
+		}
 	}
 	//txWithData := &block.TransactionWithData{}
 	//err = txWithData.UnmarshalBinary(data)
@@ -225,6 +522,9 @@ func (service *MerkleService) GetTransaction(h libcore.Hash) (libblock.Transacti
 	if err != nil {
 		return nil, err
 	}
+	if !ok {
+		addCached(service.txCache, h, data)
+	}
 	return txWithData, nil
 }
 
@@ -255,9 +555,79 @@ func (service *MerkleService) GetTransactionRoot() libcore.Hash {
 	return service.tm.GetRoot()
 }
 
+// PutBlock puts b in the canonical chain, or keeps it aside when it belongs
+// to another branch, see ForkChoice. Its GasUsed and BaseFee must follow
+// GasSchedule and FeeMarket when they are set.
 func (service *MerkleService) PutBlock(b libblock.Block) error {
 	cs := service.CryptoService
 
+	h, data, err := cs.Raw(b, libcrypto.RawBinary)
+	if err != nil {
+		return err
+	}
+	side, err := service.isSideBlock(b, h)
+	if err != nil {
+		return err
+	}
+	if side {
+		err = service.checkBlockFees(b)
+		if err != nil {
+			return err
+		}
+		return service.putSideBlock(b, h, data)
+	}
+	return service.putBlock(b)
+}
+
+// checkBlock checks the fees of b and the chain and the validity window of
+// its transactions, nothing is written.
+func (service *MerkleService) checkBlock(b libblock.Block) error {
+	err := service.checkBlockFees(b)
+	if err != nil {
+		return err
+	}
+	timestamp := int64(0)
+	blk, ok := b.(*block.Block)
+	if ok {
+		timestamp = blk.Timestamp
+	}
+	transactions := b.GetTransactions()
+	l := len(transactions)
+	for i := 0; i < l; i++ {
+		err := service.checkChainID(transactions[i].GetTransaction(), b.GetIndex())
+		if err != nil {
+			return err
+		}
+		err = checkValidityWindow(transactions[i], b.GetIndex(), timestamp)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// putBlock puts b as the head of the canonical chain, the blocks imported
+// and the ones of a branch are checked as the ones put. The height is the
+// index of b once it is accepted, the nodes written for b are journaled at
+// its index.
+func (service *MerkleService) putBlock(b libblock.Block) error {
+	err := service.checkBlock(b)
+	if err != nil {
+		return err
+	}
+	height := service.height
+	service.height = b.GetIndex()
+	err = service.writeBlock(b)
+	if err != nil {
+		service.height = height
+		return err
+	}
+	return nil
+}
+
+func (service *MerkleService) writeBlock(b libblock.Block) error {
+	cs := service.CryptoService
+
 	h, data, err := cs.Raw(b, libcrypto.RawBinary)
 	if err != nil {
 		return err
@@ -266,17 +636,72 @@ func (service *MerkleService) PutBlock(b libblock.Block) error {
 	if err != nil {
 		return err
 	}
+	if b.GetIndex() == 0 && len(service.meta.GenesisHash) == 0 {
+		service.genesis = h
+	}
+
+	transactions := b.GetTransactions()
+	l := len(transactions)
+	for i := 0; i < l; i++ {
+		_, err = service.storeTransaction(transactions[i])
+		if err != nil {
+			return err
+		}
+	}
+	states := b.GetStates()
+	l = len(states)
+	for i := 0; i < l; i++ {
+		h, data, err := cs.Raw(states[i], libcrypto.RawBinary)
+		if err != nil {
+			return err
+		}
+		err = service.sm.PutData(h, data)
+		if err != nil {
+			return err
+		}
+	}
+	return service.indexBlock(b, h, service.sm.GetRoot())
+}
+
+// indexBlock indexes the block b of hash h as the head of the chain, with
+// its transactions and states which are in the tries, root is the state root
+// after it.
+func (service *MerkleService) indexBlock(b libblock.Block, h libcore.Hash, root libcore.Hash) error {
+	cs := service.CryptoService
+
+	old, err := service.getBlockHash(b.GetIndex())
+	if err != nil {
+		return err
+	}
 	name := getBlockKey(b.GetIndex())
 	err = service.im.PutData([]byte(name), h[:])
 	if err != nil {
 		return err
 	}
+	err = service.addBlockHash(b.GetIndex(), h)
+	if err != nil {
+		return err
+	}
+	err = service.im.PutData(headKey, putUint64(b.GetIndex()))
+	if err != nil {
+		return err
+	}
 
 	transactions := b.GetTransactions()
 	l := len(transactions)
 	for i := 0; i < l; i++ {
 		tx := transactions[i]
-		err := service.PutTransaction(tx)
+		th, _, err := cs.Raw(tx, libcrypto.RawBinary)
+		if err != nil {
+			return err
+		}
+		err = service.indexTransaction(tx, th)
+		if err != nil {
+			return err
+		}
+	}
+	if !bytes.Equal(old, h) {
+		err = service.putPayments(b)
 		if err != nil {
 			return err
 		}
@@ -286,34 +711,53 @@ func (service *MerkleService) PutBlock(b libblock.Block) error {
 	l = len(states)
 	for i := 0; i < l; i++ {
 		s := states[i]
-		err := service.PutState(s)
+		sh, _, err := cs.Raw(s, libcrypto.RawBinary)
+		if err != nil {
+			return err
+		}
+		err = service.indexState(s, sh)
 		if err != nil {
 			return err
 		}
 	}
-	return nil
+	if !bytes.Equal(old, h) {
+		err = service.putDiff(b)
+		if err != nil {
+			return err
+		}
+	}
+
+	return service.im.PutData([]byte(getRootKey(b.GetIndex())), root)
 }
 
 func (service *MerkleService) GetBlockByIndex(index uint64) (libblock.Block, error) {
-	name := getBlockKey(index)
-	data, err := service.im.GetData([]byte(name))
+	h, err := service.getBlockHash(index)
 	if err != nil {
 		return nil, err
 	}
-	h := libcore.Hash(data)
+	if h == nil {
+		return nil, fmt.Errorf("no block %d", index)
+	}
 	return service.GetBlockByHash(h)
 }
 
 func (service *MerkleService) GetBlockByHash(hash libcore.Hash) (libblock.Block, error) {
-	data, err := service.bm.GetData(hash)
-	if err != nil {
-		return nil, err
+	data, ok := getCached(service.blockCache, hash)
+	if !ok {
+		var err error
+		data, err = service.bm.GetData(hash)
+		if err != nil {
+			return nil, err
+		}
 	}
 	b := &block.Block{}
-	err = b.UnmarshalBinary(data)
+	err := b.UnmarshalBinary(data)
 	if err != nil {
 		return nil, err
 	}
+	if !ok {
+		addCached(service.blockCache, hash, data)
+	}
 	return b, nil
 }
 
@@ -334,10 +778,25 @@ func (service *MerkleService) Commit() error {
 	if err != nil {
 		return err
 	}
-	return nil
+	err = service.saveRoots()
+	if err != nil {
+		return err
+	}
+	if service.genesis != nil {
+		service.meta.GenesisHash = service.genesis
+		service.genesis = nil
+		err = writeMetadata(service.metadb, service.meta)
+		if err != nil {
+			return err
+		}
+	}
+	return service.afterCommit()
 }
 
 func (service *MerkleService) Cancel() error {
+	service.purgeCaches()
+	service.genesis = nil
+
 	err := service.im.Cancel()
 	if err != nil {
 		return err
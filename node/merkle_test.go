@@ -0,0 +1,340 @@
+package node
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/tokentransfer/chain/account"
+	"github.com/tokentransfer/chain/block"
+	"github.com/tokentransfer/chain/core"
+	"github.com/tokentransfer/chain/crypto"
+	"github.com/tokentransfer/chain/store"
+
+	. "github.com/tokentransfer/check"
+	libaccount "github.com/tokentransfer/interfaces/account"
+	libblock "github.com/tokentransfer/interfaces/block"
+	libcore "github.com/tokentransfer/interfaces/core"
+	libcrypto "github.com/tokentransfer/interfaces/crypto"
+)
+
+type MerkleSuite struct{}
+
+func Test_Merkle(t *testing.T) {
+	s := Suite(&MerkleSuite{})
+	TestingRun(t, s)
+}
+
+type testConfig struct {
+	libcore.Config
+
+	dir string
+}
+
+func (c *testConfig) GetDataDir() string {
+	return c.dir
+}
+
+// openTestService initializes service over the data directory dir.
+func openTestService(c *C, dir string, service *MerkleService) *MerkleService {
+	if service.CryptoService == nil {
+		service.CryptoService = &crypto.CryptoService{}
+	}
+	err := service.Init(&testConfig{dir: dir})
+	c.Assert(err, IsNil)
+	return service
+}
+
+func newTestService(c *C) *MerkleService {
+	return openTestService(c, c.MkDir(), &MerkleService{})
+}
+
+// testChain builds blocks of payments between its accounts, each block has
+// the new account states of its payments.
+type testChain struct {
+	cs       *crypto.CryptoService
+	keys     []libaccount.Key
+	accounts []libcore.Address
+	blocks   []*block.Block
+	chainID  uint32
+
+	sequences []uint64
+	amounts   []int64
+}
+
+func newTestChain(c *C, accounts int) *testChain {
+	chain := &testChain{cs: &crypto.CryptoService{}}
+	states := make([]libblock.State, 0)
+	for i := 0; i < accounts; i++ {
+		key, err := account.GenerateFamilySeed(fmt.Sprintf("account%d", i))
+		c.Assert(err, IsNil)
+		a, err := key.GetAddress()
+		c.Assert(err, IsNil)
+		chain.keys = append(chain.keys, key)
+		chain.accounts = append(chain.accounts, a)
+		chain.sequences = append(chain.sequences, 0)
+		chain.amounts = append(chain.amounts, 1000000)
+		states = append(states, chain.accountState(i, 0))
+	}
+	genesis := &block.Block{BlockIndex: 0, Timestamp: 0, States: states}
+	chain.seal(c, genesis)
+	return chain
+}
+
+// fork returns a chain with the blocks up to index, the new blocks of each
+// chain are not in the other.
+func (chain *testChain) fork(c *C, index uint64) *testChain {
+	f := &testChain{
+		cs:       chain.cs,
+		keys:     chain.keys,
+		accounts: chain.accounts,
+		chainID:  chain.chainID,
+	}
+	f.blocks = append(f.blocks, chain.blocks[:index+1]...)
+	f.sequences = make([]uint64, len(chain.accounts))
+	f.amounts = make([]int64, len(chain.accounts))
+	for i := 0; i < len(chain.accounts); i++ {
+		s, err := f.getState(c, i)
+		c.Assert(err, IsNil)
+		f.sequences[i] = s.Sequence
+		f.amounts[i] = s.Amount
+	}
+	return f
+}
+
+// getState returns the latest state of account i in the blocks.
+func (chain *testChain) getState(c *C, i int) (*block.AccountState, error) {
+	address, err := chain.accounts[i].GetAddress()
+	c.Assert(err, IsNil)
+	for j := len(chain.blocks) - 1; j >= 0; j-- {
+		states := chain.blocks[j].GetStates()
+		for k := len(states) - 1; k >= 0; k-- {
+			if states[k].GetStateKey() == address {
+				return states[k].(*block.AccountState), nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no state %s", address)
+}
+
+func (chain *testChain) accountState(i int, blockIndex uint64) *block.AccountState {
+	return &block.AccountState{
+		State: block.State{
+			BlockIndex: blockIndex,
+			StateType:  libblock.StateType(core.CORE_ACCOUNT_STATE),
+		},
+		Account:  chain.accounts[i],
+		Sequence: chain.sequences[i],
+		Amount:   chain.amounts[i],
+	}
+}
+
+func (chain *testChain) head() *block.Block {
+	return chain.blocks[len(chain.blocks)-1]
+}
+
+// payment returns a signed payment of amount from account from to account to,
+// it does not change the balances.
+func (chain *testChain) payment(c *C, from int, to int, amount int64, blockIndex uint64) *block.Payment {
+	chain.sequences[from]++
+	p := &block.Payment{
+		Transaction: block.Transaction{
+			TransactionType: libblock.TransactionType(core.CORE_PAYMENT),
+			Account:         chain.accounts[from],
+			Sequence:        chain.sequences[from],
+			Amount:          amount,
+			Gas:             10,
+			ChainID:         chain.chainID,
+			Destination:     chain.accounts[to],
+		},
+		Timestamp: int64(blockIndex) * 60,
+		Device:    fmt.Sprintf("device%d", from),
+		Tags:      []string{"test"},
+	}
+	err := chain.cs.Sign(chain.keys[from], p)
+	c.Assert(err, IsNil)
+	return p
+}
+
+// next appends a block with a payment of amount from account i to the next
+// account, and returns it.
+func (chain *testChain) next(c *C, i int, amount int64) *block.Block {
+	parent := chain.head()
+	index := parent.GetIndex() + 1
+	to := (i + 1) % len(chain.accounts)
+	p := chain.payment(c, i, to, amount, index)
+	chain.amounts[i] -= amount
+	chain.amounts[to] += amount
+
+	b := &block.Block{
+		BlockIndex: index,
+		ParentHash: chain.hash(c, parent),
+		Timestamp:  int64(index) * 60,
+		Transactions: []libblock.TransactionWithData{
+			&block.PaymentWithData{
+				Transaction: p,
+				Receipt: &block.Receipt{
+					TransactionResult: block.ResultSuccess,
+					States:            []libblock.State{chain.accountState(i, index), chain.accountState(to, index)},
+				},
+			},
+		},
+		States: []libblock.State{chain.accountState(i, index), chain.accountState(to, index)},
+	}
+	chain.seal(c, b)
+	return b
+}
+
+// grow appends n blocks of payments.
+func (chain *testChain) grow(c *C, n int) {
+	for j := 0; j < n; j++ {
+		chain.next(c, j%len(chain.accounts), int64(j+1))
+	}
+}
+
+func (chain *testChain) hash(c *C, b libcrypto.Hashable) libcore.Hash {
+	h, _, err := chain.cs.Raw(b, libcrypto.RawBinary)
+	c.Assert(err, IsNil)
+	return h
+}
+
+// seal sets the StateHash of b to the root of the state trie with the states
+// of the chain and of b, and appends b.
+func (chain *testChain) seal(c *C, b *block.Block) {
+	db := &store.MemoryService{}
+	err := db.Init(nil)
+	c.Assert(err, IsNil)
+	t := NewMerkleTree(chain.cs, db)
+	blocks := append(append([]*block.Block{}, chain.blocks...), b)
+	for _, sb := range blocks {
+		for _, s := range sb.GetStates() {
+			h, data, err := chain.cs.Raw(s, libcrypto.RawBinary)
+			c.Assert(err, IsNil)
+			err = t.PutData(h, data)
+			c.Assert(err, IsNil)
+		}
+	}
+	b.StateHash = t.GetRoot()
+	chain.blocks = append(chain.blocks, b)
+}
+
+// putBlocks puts and commits the blocks from index from.
+func (chain *testChain) putBlocks(c *C, service *MerkleService, from uint64) {
+	for i := from; i < uint64(len(chain.blocks)); i++ {
+		err := service.PutBlock(chain.blocks[i])
+		c.Assert(err, IsNil)
+		err = service.Commit()
+		c.Assert(err, IsNil)
+	}
+}
+
+// buildBlock builds, puts and commits the block after the head with
+// transactions.
+func buildBlock(c *C, service *MerkleService, producer libcore.Address, timestamp int64, transactions ...libblock.Transaction) *block.Block {
+	b, err := service.BuildBlock(producer, timestamp, transactions)
+	c.Assert(err, IsNil)
+	err = service.PutBlock(b)
+	c.Assert(err, IsNil)
+	err = service.Commit()
+	c.Assert(err, IsNil)
+	return b
+}
+
+// deviceTransaction returns the fields of a device transaction of type t
+// sent by account i.
+func (chain *testChain) deviceTransaction(i int, t byte) block.Transaction {
+	chain.sequences[i]++
+	return block.Transaction{
+		TransactionType: libblock.TransactionType(t),
+		Account:         chain.accounts[i],
+		Sequence:        chain.sequences[i],
+		Gas:             10,
+		ChainID:         chain.chainID,
+		Destination:     chain.accounts[i],
+	}
+}
+
+func (suite *MerkleSuite) TestPutBlocks(c *C) {
+	chain := newTestChain(c, 3)
+	chain.grow(c, 5)
+
+	dir := c.MkDir()
+	service := openTestService(c, dir, &MerkleService{ObjectCacheSize: 16})
+	chain.putBlocks(c, service, 0)
+
+	head, err := service.GetHead()
+	c.Assert(err, IsNil)
+	c.Assert(head.GetIndex(), Equals, uint64(5))
+	c.Assert([]byte(service.GetStateRoot()), DeepEquals, []byte(chain.head().StateHash))
+
+	b, err := service.GetBlockByIndex(2)
+	c.Assert(err, IsNil)
+	c.Assert([]byte(chain.hash(c, b)), DeepEquals, []byte(chain.hash(c, chain.blocks[2])))
+	b.(*block.Block).Timestamp++
+	cached, err := service.GetBlockByIndex(2)
+	c.Assert(err, IsNil)
+	c.Assert(cached.(*block.Block).Timestamp, Equals, chain.blocks[2].Timestamp)
+
+	address, err := chain.accounts[1].GetAddress()
+	c.Assert(err, IsNil)
+	s, err := service.GetStateByKey(address)
+	c.Assert(err, IsNil)
+	expected, err := chain.getState(c, 1)
+	c.Assert(err, IsNil)
+	c.Assert(s.(*block.AccountState).Amount, Equals, expected.Amount)
+
+	err = service.Close()
+	c.Assert(err, IsNil)
+	service = openTestService(c, dir, &MerkleService{})
+	defer service.Close()
+	head, err = service.GetHead()
+	c.Assert(err, IsNil)
+	c.Assert(head.GetIndex(), Equals, uint64(5))
+	report, err := service.Verify()
+	c.Assert(err, IsNil)
+	c.Assert(report.Errors, IsNil)
+}
+
+func (suite *MerkleSuite) TestValidityWindow(c *C) {
+	chain := newTestChain(c, 3)
+	chain.grow(c, 3)
+	service := newTestService(c)
+	defer service.Close()
+	chain.putBlocks(c, service, 0)
+
+	b := chain.next(c, 0, 1)
+	txWithData := b.GetTransactions()[0]
+	p := txWithData.GetTransaction().(*block.Payment)
+	check := func(validAfter uint64, validUntil uint64) error {
+		p.ValidAfter = validAfter
+		p.ValidUntil = validUntil
+		err := chain.cs.Sign(chain.keys[0], p)
+		c.Assert(err, IsNil)
+		err = service.PutBlock(b)
+		if err != nil {
+			service.Cancel()
+		}
+		return err
+	}
+
+	err := check(0, 3)
+	c.Assert(err.(*block.ResultError).Result, Equals, block.ResultExpired)
+	err = check(5, 0)
+	c.Assert(err.(*block.ResultError).Result, Equals, block.ResultNotYetValid)
+	err = check(block.ValidityThreshold, 0)
+	c.Assert(err.(*block.ResultError).Result, Equals, block.ResultNotYetValid)
+	c.Assert(service.height, Equals, uint64(3))
+
+	// the next block is 4 and the current time is past the threshold
+	p.ValidAfter = 0
+	p.ValidUntil = block.ValidityThreshold
+	err = chain.cs.Sign(chain.keys[0], p)
+	c.Assert(err, IsNil)
+	err = service.PutTransaction(txWithData)
+	c.Assert(err.(*block.ResultError).Result, Equals, block.ResultExpired)
+	service.Cancel()
+
+	err = check(4, 4)
+	c.Assert(err, IsNil)
+	err = service.Commit()
+	c.Assert(err, IsNil)
+}
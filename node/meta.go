@@ -0,0 +1,245 @@
+package node
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	libcore "github.com/tokentransfer/interfaces/core"
+	libstore "github.com/tokentransfer/interfaces/store"
+)
+
+// SchemaVersion is the version of the on-disk layout written by this code,
+// version 0 is a data directory created before the metadata was introduced.
+const SchemaVersion = uint32(7)
+
+var (
+	metaVersionKey  = []byte("version")
+	metaChainKey    = []byte("chain")
+	metaGenesisKey  = []byte("genesis")
+	metaHeightKey   = []byte("chain_height")
+	metaSnapshotKey = []byte("snapshot_height")
+)
+
+// Metadata describes a data directory, ChainHeight is the index of the
+// first block after the ChainID was set on a chain which had blocks, the
+// transactions of the blocks before it may be signed for chain 0.
+// SnapshotHeight is the index of the snapshot block of a data directory
+// imported from a snapshot, the blocks between the genesis block and it are
+// missing.
+type Metadata struct {
+	Version        uint32
+	ChainID        uint32
+	ChainHeight    uint64
+	SnapshotHeight uint64
+	GenesisHash    libcore.Hash
+}
+
+func readMetadata(db libstore.KvService) (*Metadata, error) {
+	m := &Metadata{}
+	version, err := db.GetData(metaVersionKey)
+	if err != nil {
+		return nil, err
+	}
+	if version == nil {
+		return nil, nil
+	}
+	if len(version) != 4 {
+		return nil, errors.New("error metadata version")
+	}
+	m.Version = binary.BigEndian.Uint32(version)
+
+	chain, err := db.GetData(metaChainKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(chain) == 4 {
+		m.ChainID = binary.BigEndian.Uint32(chain)
+	}
+	height, err := db.GetData(metaHeightKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(height) == 8 {
+		m.ChainHeight = binary.BigEndian.Uint64(height)
+	}
+	snapshot, err := db.GetData(metaSnapshotKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshot) == 8 {
+		m.SnapshotHeight = binary.BigEndian.Uint64(snapshot)
+	}
+
+	genesis, err := db.GetData(metaGenesisKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(genesis) > 0 {
+		m.GenesisHash = libcore.Hash(genesis)
+	}
+	return m, nil
+}
+
+func writeMetadata(db libstore.KvService, m *Metadata) error {
+	version := make([]byte, 4)
+	binary.BigEndian.PutUint32(version, m.Version)
+	chain := make([]byte, 4)
+	binary.BigEndian.PutUint32(chain, m.ChainID)
+
+	height := make([]byte, 8)
+	binary.BigEndian.PutUint64(height, m.ChainHeight)
+
+	snapshot := make([]byte, 8)
+	binary.BigEndian.PutUint64(snapshot, m.SnapshotHeight)
+
+	keys := [][]byte{metaVersionKey, metaChainKey, metaHeightKey, metaSnapshotKey}
+	values := [][]byte{version, chain, height, snapshot}
+	if len(m.GenesisHash) > 0 {
+		keys = append(keys, metaGenesisKey)
+		values = append(values, []byte(m.GenesisHash))
+	}
+	return db.PutDatas(keys, values)
+}
+
+// Migration upgrades a data directory from version From to From+1.
+type Migration struct {
+	From    uint32
+	Name    string
+	Migrate func(service *MerkleService) error
+}
+
+var (
+	migrations      = map[uint32]Migration{}
+	migrationLocker = &sync.Mutex{}
+)
+
+// RegisterMigration registers the step run on data directories at version
+// from, there can be only one step for each version.
+func RegisterMigration(from uint32, name string, migrate func(service *MerkleService) error) error {
+	migrationLocker.Lock()
+	defer migrationLocker.Unlock()
+
+	_, ok := migrations[from]
+	if ok {
+		return fmt.Errorf("migration from version %d already registered", from)
+	}
+	migrations[from] = Migration{
+		From:    from,
+		Name:    name,
+		Migrate: migrate,
+	}
+	return nil
+}
+
+// Migrations returns the registered steps ordered by version.
+func Migrations() []Migration {
+	migrationLocker.Lock()
+	defer migrationLocker.Unlock()
+
+	list := make([]Migration, 0, len(migrations))
+	for _, m := range migrations {
+		list = append(list, m)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].From < list[j].From
+	})
+	return list
+}
+
+func getMigration(from uint32) (Migration, bool) {
+	migrationLocker.Lock()
+	defer migrationLocker.Unlock()
+
+	m, ok := migrations[from]
+	return m, ok
+}
+
+func init() {
+	// the layout of version 0 is unchanged, only the metadata is added
+	err := RegisterMigration(0, "add metadata", func(service *MerkleService) error {
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// GetMetadata returns the metadata of the data directory.
+func (service *MerkleService) GetMetadata() Metadata {
+	return *service.meta
+}
+
+func (service *MerkleService) getGenesisHash() libcore.Hash {
+	data, err := service.im.GetData([]byte(getBlockKey(0)))
+	if err != nil || len(data) == 0 {
+		return nil
+	}
+	return libcore.Hash(data)
+}
+
+// checkMetadata compares the metadata with the configuration of the
+// service and upgrades the data directory to SchemaVersion.
+func (service *MerkleService) checkMetadata() error {
+	m, err := readMetadata(service.metadb)
+	if err != nil {
+		return err
+	}
+	if m == nil {
+		m = &Metadata{Version: SchemaVersion}
+		genesis := service.getGenesisHash()
+		if genesis != nil {
+			m.Version = 0
+			m.GenesisHash = genesis
+		}
+	}
+
+	if m.Version > SchemaVersion {
+		return fmt.Errorf("data directory version %d is newer than %d", m.Version, SchemaVersion)
+	}
+	if m.ChainID == 0 && service.ChainID != 0 {
+		// the blocks already put were not signed for the chain
+		m.ChainID = service.ChainID
+		for {
+			h, err := service.getBlockHash(m.ChainHeight)
+			if err != nil {
+				return err
+			}
+			if h == nil {
+				break
+			}
+			m.ChainHeight++
+		}
+	}
+	if service.ChainID != 0 && m.ChainID != service.ChainID {
+		return fmt.Errorf("data directory is for chain %d, not %d", m.ChainID, service.ChainID)
+	}
+	if len(service.GenesisHash) > 0 && len(m.GenesisHash) > 0 && !bytes.Equal(service.GenesisHash, m.GenesisHash) {
+		return fmt.Errorf("data directory has genesis %s, not %s", m.GenesisHash.String(), service.GenesisHash.String())
+	}
+	service.meta = m
+
+	for m.Version < SchemaVersion {
+		step, ok := getMigration(m.Version)
+		if !ok {
+			return fmt.Errorf("no migration from version %d", m.Version)
+		}
+		err := step.Migrate(service)
+		if err != nil {
+			return fmt.Errorf("migration %d %s: %s", step.From, step.Name, err)
+		}
+		err = service.Commit()
+		if err != nil {
+			return err
+		}
+		m.Version++
+		err = writeMetadata(service.metadb, m)
+		if err != nil {
+			return err
+		}
+	}
+	return writeMetadata(service.metadb, m)
+}
@@ -0,0 +1,182 @@
+package node
+
+import (
+	"path"
+	"testing"
+
+	"github.com/tokentransfer/chain/crypto"
+	"github.com/tokentransfer/chain/store"
+
+	. "github.com/tokentransfer/check"
+	libblock "github.com/tokentransfer/interfaces/block"
+	libcrypto "github.com/tokentransfer/interfaces/crypto"
+)
+
+type MetaSuite struct{}
+
+func Test_Meta(t *testing.T) {
+	s := Suite(&MetaSuite{})
+	TestingRun(t, s)
+}
+
+// putV0Block indexes b as the data directories of version 0 did.
+func putV0Block(c *C, service *MerkleService, b libblock.Block) {
+	cs := service.CryptoService
+
+	h, data, err := cs.Raw(b, libcrypto.RawBinary)
+	c.Assert(err, IsNil)
+	err = service.bm.PutData(h, data)
+	c.Assert(err, IsNil)
+	err = service.im.PutData([]byte(getBlockKey(b.GetIndex())), h)
+	c.Assert(err, IsNil)
+
+	for _, txWithData := range b.GetTransactions() {
+		th, data, err := cs.Raw(txWithData, libcrypto.RawBinary)
+		c.Assert(err, IsNil)
+		err = service.tm.PutData(th, data)
+		c.Assert(err, IsNil)
+		tx := txWithData.GetTransaction()
+		txHash, _, err := cs.Raw(tx, libcrypto.RawBinary)
+		c.Assert(err, IsNil)
+		err = service.im.PutData([]byte(getHashKey("transaction", txHash)), th)
+		c.Assert(err, IsNil)
+		address, err := tx.GetAccount().GetAddress()
+		c.Assert(err, IsNil)
+		err = service.im.PutData([]byte(getNameKey("transaction", getIndexKey(address, tx.GetIndex()))), th)
+		c.Assert(err, IsNil)
+	}
+
+	for _, s := range b.GetStates() {
+		sh, data, err := cs.Raw(s, libcrypto.RawBinary)
+		c.Assert(err, IsNil)
+		err = service.sm.PutData(sh, data)
+		c.Assert(err, IsNil)
+		key := s.GetStateKey()
+		err = service.im.PutData([]byte(getNameKey("state", getIndexKey(key, s.GetIndex()))), sh)
+		c.Assert(err, IsNil)
+		err = service.im.PutData([]byte(getNameKey("state", key)), sh)
+		c.Assert(err, IsNil)
+	}
+}
+
+func (suite *MetaSuite) TestMetadata(c *C) {
+	chain := newTestChain(c, 2)
+	chain.chainID = 3
+	chain.grow(c, 2)
+	dir := c.MkDir()
+	service := openTestService(c, dir, &MerkleService{ChainID: 3})
+	chain.putBlocks(c, service, 0)
+	m := service.GetMetadata()
+	c.Assert(m.Version, Equals, SchemaVersion)
+	c.Assert(m.ChainID, Equals, uint32(3))
+	c.Assert([]byte(m.GenesisHash), DeepEquals, []byte(chain.hash(c, chain.blocks[0])))
+	err := service.Close()
+	c.Assert(err, IsNil)
+
+	other := &MerkleService{ChainID: 4, CryptoService: &crypto.CryptoService{}}
+	err = other.Init(&testConfig{dir: dir})
+	c.Assert(err, NotNil)
+	other.Close()
+
+	other = &MerkleService{GenesisHash: chain.hash(c, chain.blocks[1]), CryptoService: &crypto.CryptoService{}}
+	err = other.Init(&testConfig{dir: dir})
+	c.Assert(err, NotNil)
+	other.Close()
+
+	service = openTestService(c, dir, &MerkleService{})
+	defer service.Close()
+	c.Assert(service.GetMetadata().ChainID, Equals, uint32(3))
+}
+
+func (suite *MetaSuite) TestChainHeight(c *C) {
+	chain := newTestChain(c, 3)
+	chain.grow(c, 4)
+	dir := c.MkDir()
+	service := openTestService(c, dir, &MerkleService{})
+	chain.putBlocks(c, service, 0)
+	err := service.Close()
+	c.Assert(err, IsNil)
+
+	// the chain id is set on a chain which has blocks
+	cs := &crypto.CryptoService{}
+	service = openTestService(c, dir, &MerkleService{ChainID: 5, CryptoService: cs})
+	defer service.Close()
+	m := service.GetMetadata()
+	c.Assert(m.ChainID, Equals, uint32(5))
+	c.Assert(m.ChainHeight, Equals, uint64(5))
+	c.Assert(cs.ChainID, Equals, uint32(0))
+
+	other := chain.fork(c, 4)
+	other.grow(c, 1)
+	err = service.PutBlock(other.blocks[5])
+	c.Assert(err, NotNil)
+	service.Cancel()
+	err = service.PutTransaction(other.blocks[5].GetTransactions()[0])
+	c.Assert(err, NotNil)
+	service.Cancel()
+
+	chain.chainID = 5
+	chain.grow(c, 2)
+	chain.putBlocks(c, service, 5)
+
+	// the blocks before are replayed with their transactions of chain 0
+	err = service.Rollback(2)
+	c.Assert(err, IsNil)
+	chain.putBlocks(c, service, 3)
+	head, err := service.GetHead()
+	c.Assert(err, IsNil)
+	c.Assert(head.GetIndex(), Equals, uint64(6))
+}
+
+func (suite *MetaSuite) TestMigrate(c *C) {
+	chain := newTestChain(c, 3)
+	chain.grow(c, 6)
+
+	dir := c.MkDir()
+	service := openTestService(c, dir, &MerkleService{})
+	for _, b := range chain.blocks {
+		putV0Block(c, service, b)
+	}
+	err := service.Commit()
+	c.Assert(err, IsNil)
+	err = service.Close()
+	c.Assert(err, IsNil)
+
+	meta := &store.LevelService{Path: path.Join(dir, "meta")}
+	err = meta.Init(nil)
+	c.Assert(err, IsNil)
+	for _, key := range [][]byte{metaVersionKey, metaChainKey, metaGenesisKey} {
+		err = meta.RemoveData(key)
+		c.Assert(err, IsNil)
+	}
+	err = meta.Close()
+	c.Assert(err, IsNil)
+
+	service = openTestService(c, dir, &MerkleService{})
+	defer service.Close()
+	m := service.GetMetadata()
+	c.Assert(m.Version, Equals, SchemaVersion)
+	c.Assert([]byte(m.GenesisHash), DeepEquals, []byte(chain.hash(c, chain.blocks[0])))
+
+	head, err := service.GetHead()
+	c.Assert(err, IsNil)
+	c.Assert(head.GetIndex(), Equals, uint64(6))
+	report, err := service.Verify()
+	c.Assert(err, IsNil)
+	c.Assert(report.Errors, IsNil)
+
+	address, err := chain.accounts[0].GetAddress()
+	c.Assert(err, IsNil)
+	s, err := service.GetStateAt(address, 2)
+	c.Assert(err, IsNil)
+	c.Assert(s.GetBlockIndex(), Equals, uint64(1))
+	txs, _, err := service.ListTransactions(address, DirectionSent, 0, 0)
+	c.Assert(err, IsNil)
+	c.Assert(len(txs), Equals, 2)
+	diffs, _, err := service.ListStateDiffs(address, 0, 0)
+	c.Assert(err, IsNil)
+	c.Assert(len(diffs), Equals, 5)
+	payments, err := service.QueryPayments(PaymentQuery{Device: "device0", From: 0, To: 1000})
+	c.Assert(err, IsNil)
+	c.Assert(len(payments), Equals, 2)
+}
@@ -0,0 +1,337 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/tokentransfer/chain/block"
+
+	libblock "github.com/tokentransfer/interfaces/block"
+	libcore "github.com/tokentransfer/interfaces/core"
+	libcrypto "github.com/tokentransfer/interfaces/crypto"
+)
+
+// PaymentBucket is the width of the Timestamp buckets of the payment
+// indexes, in seconds.
+const PaymentBucket = int64(3600)
+
+// The payments are indexed by device, tag and name. For every value,
+// "payment@field@value@B" holds the number of payments with a Timestamp in
+// bucket B and "payment@field@value@B:N" the Timestamp and the hash of the
+// Nth of them, in block order, "payment@field@value" holds the number of
+// buckets used and "payment@field@value:N" the Nth of them.
+
+const (
+	paymentDevice = "device"
+	paymentTag    = "tag"
+	paymentName   = "name"
+)
+
+func getPaymentKey(field string, value string) string {
+	return fmt.Sprintf("payment@%s@%s", field, value)
+}
+
+func getBucketKey(field string, value string, bucket int64) string {
+	return fmt.Sprintf("%s@%d", getPaymentKey(field, value), bucket)
+}
+
+func getBucket(timestamp int64) int64 {
+	bucket := timestamp / PaymentBucket
+	if timestamp < 0 && timestamp%PaymentBucket != 0 {
+		bucket--
+	}
+	return bucket
+}
+
+type paymentEntry struct {
+	field string
+	value string
+}
+
+func getPaymentEntries(p *block.Payment) []paymentEntry {
+	entries := make([]paymentEntry, 0)
+	if len(p.Device) > 0 {
+		entries = append(entries, paymentEntry{paymentDevice, p.Device})
+	}
+	seen := make(map[string]bool)
+	for _, tag := range p.Tags {
+		if len(tag) > 0 && !seen[tag] {
+			seen[tag] = true
+			entries = append(entries, paymentEntry{paymentTag, tag})
+		}
+	}
+	if len(p.Name) > 0 {
+		entries = append(entries, paymentEntry{paymentName, p.Name})
+	}
+	return entries
+}
+
+func (service *MerkleService) putPayment(p *block.Payment, h libcore.Hash) error {
+	bucket := getBucket(p.Timestamp)
+	for _, e := range getPaymentEntries(p) {
+		key := getBucketKey(e.field, e.value, bucket)
+		count, err := service.getCount(key)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			listKey := getPaymentKey(e.field, e.value)
+			buckets, err := service.getCount(listKey)
+			if err != nil {
+				return err
+			}
+			err = service.im.PutData([]byte(getIndexKey(listKey, buckets)), putUint64(uint64(bucket)))
+			if err != nil {
+				return err
+			}
+			err = service.im.PutData([]byte(listKey), putUint64(buckets+1))
+			if err != nil {
+				return err
+			}
+		}
+
+		value := append(putUint64(uint64(p.Timestamp)), h...)
+		err = service.im.PutData([]byte(getIndexKey(key, count)), value)
+		if err != nil {
+			return err
+		}
+		err = service.im.PutData([]byte(key), putUint64(count+1))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removePayment undoes putPayment, the payments must be removed in the
+// reverse order of their put.
+func (service *MerkleService) removePayment(p *block.Payment) error {
+	bucket := getBucket(p.Timestamp)
+	for _, e := range getPaymentEntries(p) {
+		key := getBucketKey(e.field, e.value, bucket)
+		count, err := service.getCount(key)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			continue
+		}
+		err = service.removeIndex(getIndexKey(key, count-1))
+		if err != nil {
+			return err
+		}
+		err = service.im.PutData([]byte(key), putUint64(count-1))
+		if err != nil {
+			return err
+		}
+		if count > 1 {
+			continue
+		}
+
+		listKey := getPaymentKey(e.field, e.value)
+		buckets, err := service.getCount(listKey)
+		if err != nil {
+			return err
+		}
+		if buckets == 0 {
+			continue
+		}
+		err = service.removeIndex(getIndexKey(listKey, buckets-1))
+		if err != nil {
+			return err
+		}
+		err = service.im.PutData([]byte(listKey), putUint64(buckets-1))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (service *MerkleService) putPayments(b libblock.Block) error {
+	cs := service.CryptoService
+	for _, txWithData := range b.GetTransactions() {
+		p, ok := txWithData.GetTransaction().(*block.Payment)
+		if !ok {
+			continue
+		}
+		h, _, err := cs.Raw(txWithData, libcrypto.RawBinary)
+		if err != nil {
+			return err
+		}
+		err = service.putPayment(p, h)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (service *MerkleService) removePayments(b libblock.Block) error {
+	transactions := b.GetTransactions()
+	for i := len(transactions) - 1; i >= 0; i-- {
+		p, ok := transactions[i].GetTransaction().(*block.Payment)
+		if !ok {
+			continue
+		}
+		err := service.removePayment(p)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PaymentQuery selects the payments with a Timestamp between From and To
+// included, at least one of Device, Tag and Name must be set, the payments
+// must match all of them.
+type PaymentQuery struct {
+	Device string
+	Tag    string
+	Name   string
+	From   int64
+	To     int64
+	Limit  int
+}
+
+func (q *PaymentQuery) match(p *block.Payment) bool {
+	if p.Timestamp < q.From || p.Timestamp > q.To {
+		return false
+	}
+	if len(q.Device) > 0 && p.Device != q.Device {
+		return false
+	}
+	if len(q.Name) > 0 && p.Name != q.Name {
+		return false
+	}
+	if len(q.Tag) > 0 {
+		for _, tag := range p.Tags {
+			if tag == q.Tag {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// QueryPayments returns at most q.Limit payments matching q ordered by
+// Timestamp, the payments of the same Timestamp in block order.
+func (service *MerkleService) QueryPayments(q PaymentQuery) ([]libblock.TransactionWithData, error) {
+	var field, value string
+	switch {
+	case len(q.Device) > 0:
+		field, value = paymentDevice, q.Device
+	case len(q.Tag) > 0:
+		field, value = paymentTag, q.Tag
+	case len(q.Name) > 0:
+		field, value = paymentName, q.Name
+	default:
+		return nil, errors.New("error payment query")
+	}
+	if q.To < q.From {
+		return nil, errors.New("error payment query range")
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultListLimit
+	}
+
+	listKey := getPaymentKey(field, value)
+	count, err := service.getCount(listKey)
+	if err != nil {
+		return nil, err
+	}
+	buckets := make([]int64, 0)
+	for i := uint64(0); i < count; i++ {
+		data, err := service.im.GetData([]byte(getIndexKey(listKey, i)))
+		if err != nil {
+			return nil, err
+		}
+		b, ok := getUint64(data)
+		if !ok {
+			return nil, errors.New("error payment index")
+		}
+		bucket := int64(b)
+		if bucket >= getBucket(q.From) && bucket <= getBucket(q.To) {
+			buckets = append(buckets, bucket)
+		}
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i] < buckets[j]
+	})
+
+	list := make([]libblock.TransactionWithData, 0)
+	for _, bucket := range buckets {
+		key := getBucketKey(field, value, bucket)
+		count, err := service.getCount(key)
+		if err != nil {
+			return nil, err
+		}
+		found := make([]libblock.TransactionWithData, 0)
+		for i := uint64(0); i < count; i++ {
+			data, err := service.im.GetData([]byte(getIndexKey(key, i)))
+			if err != nil {
+				return nil, err
+			}
+			if len(data) <= 8 {
+				return nil, errors.New("error payment index")
+			}
+			timestamp, _ := getUint64(data[:8])
+			if int64(timestamp) < q.From || int64(timestamp) > q.To {
+				continue
+			}
+			txWithData, err := service.GetTransaction(libcore.Hash(data[8:]))
+			if err != nil {
+				return nil, err
+			}
+			p, ok := txWithData.GetTransaction().(*block.Payment)
+			if !ok || !q.match(p) {
+				continue
+			}
+			found = append(found, txWithData)
+		}
+		sort.SliceStable(found, func(i, j int) bool {
+			return found[i].GetTransaction().(*block.Payment).Timestamp < found[j].GetTransaction().(*block.Payment).Timestamp
+		})
+		list = append(list, found...)
+		if len(list) >= q.Limit {
+			return list[:q.Limit], nil
+		}
+	}
+	return list, nil
+}
+
+func init() {
+	err := RegisterMigration(4, "index payments", func(service *MerkleService) error {
+		for i := uint64(0); ; i = service.nextIndex(i) {
+			h, err := service.getBlockHash(i)
+			if err != nil {
+				return err
+			}
+			if h == nil {
+				return nil
+			}
+			if service.isHeader(i) {
+				continue
+			}
+			b, err := service.GetBlockByHash(h)
+			if err != nil {
+				return err
+			}
+			err = service.putPayments(b)
+			if err != nil {
+				return err
+			}
+			if (i+1)%migrateBatch == 0 {
+				err = service.Commit()
+				if err != nil {
+					return err
+				}
+			}
+		}
+	})
+	if err != nil {
+		panic(err)
+	}
+}
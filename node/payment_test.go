@@ -0,0 +1,61 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/tokentransfer/chain/block"
+
+	. "github.com/tokentransfer/check"
+)
+
+type PaymentSuite struct{}
+
+func Test_Payment(t *testing.T) {
+	s := Suite(&PaymentSuite{})
+	TestingRun(t, s)
+}
+
+func (suite *PaymentSuite) TestQueryPayments(c *C) {
+	chain := newTestChain(c, 3)
+	chain.grow(c, 66)
+	service := newTestService(c)
+	defer service.Close()
+	chain.putBlocks(c, service, 0)
+
+	// the blocks have a Timestamp of 60 times their index, the payments of
+	// device0 are in the blocks 1, 4, ... 64
+	list, err := service.QueryPayments(PaymentQuery{Device: "device0", From: 0, To: 10000})
+	c.Assert(err, IsNil)
+	c.Assert(len(list), Equals, 22)
+	for i := 1; i < len(list); i++ {
+		prev := list[i-1].GetTransaction().(*block.Payment)
+		p := list[i].GetTransaction().(*block.Payment)
+		c.Assert(prev.Timestamp < p.Timestamp, Equals, true)
+	}
+
+	// a range over two buckets
+	list, err = service.QueryPayments(PaymentQuery{Device: "device0", From: 3000, To: 3700})
+	c.Assert(err, IsNil)
+	checkTransactions(c, chain, list, 52, 55, 58, 61)
+
+	list, err = service.QueryPayments(PaymentQuery{Tag: "test", From: 0, To: 10000, Limit: 5})
+	c.Assert(err, IsNil)
+	checkTransactions(c, chain, list, 1, 2, 3, 4, 5)
+	list, err = service.QueryPayments(PaymentQuery{Device: "device1", Tag: "test", From: 120, To: 300})
+	c.Assert(err, IsNil)
+	checkTransactions(c, chain, list, 2, 5)
+	list, err = service.QueryPayments(PaymentQuery{Device: "device1", Tag: "other", From: 0, To: 10000})
+	c.Assert(err, IsNil)
+	c.Assert(len(list), Equals, 0)
+
+	_, err = service.QueryPayments(PaymentQuery{From: 0, To: 10000})
+	c.Assert(err, NotNil)
+	_, err = service.QueryPayments(PaymentQuery{Device: "device0", From: 10, To: 0})
+	c.Assert(err, NotNil)
+
+	err = service.Rollback(60)
+	c.Assert(err, IsNil)
+	list, err = service.QueryPayments(PaymentQuery{Device: "device0", From: 3000, To: 3700})
+	c.Assert(err, IsNil)
+	checkTransactions(c, chain, list, 52, 55, 58)
+}
@@ -0,0 +1,232 @@
+package node
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+
+	"github.com/tokentransfer/go-MerklePatriciaTree/mpt"
+
+	"github.com/tokentransfer/chain/core"
+	"github.com/tokentransfer/chain/core/pb"
+	"github.com/tokentransfer/chain/crypto"
+	"github.com/tokentransfer/chain/store"
+
+	libcore "github.com/tokentransfer/interfaces/core"
+	libstore "github.com/tokentransfer/interfaces/store"
+)
+
+// recordService is a read only view of a KvService which records every entry
+// read through it, the entries read by a trie to resolve a key are the nodes
+// on the path from the root to that key.
+type recordService struct {
+	libstore.KvService
+
+	keys   [][]byte
+	values [][]byte
+	seen   map[string]bool
+	locker *sync.Mutex
+}
+
+func newRecordService(ss libstore.KvService) *recordService {
+	return &recordService{
+		KvService: ss,
+		seen:      make(map[string]bool),
+		locker:    &sync.Mutex{},
+	}
+}
+
+func (service *recordService) record(key []byte, value []byte) {
+	service.locker.Lock()
+	defer service.locker.Unlock()
+
+	if value == nil || service.seen[string(key)] {
+		return
+	}
+	service.seen[string(key)] = true
+	service.keys = append(service.keys, append([]byte(nil), key...))
+	service.values = append(service.values, append([]byte(nil), value...))
+}
+
+func (service *recordService) Init(c libcore.Config) error {
+	return nil
+}
+
+func (service *recordService) Close() error {
+	return nil
+}
+
+func (service *recordService) GetData(key []byte) ([]byte, error) {
+	value, err := service.KvService.GetData(key)
+	if err != nil {
+		return nil, err
+	}
+	service.record(key, value)
+	return value, nil
+}
+
+func (service *recordService) GetDatas(keys [][]byte) ([][]byte, error) {
+	l := len(keys)
+	values := make([][]byte, l)
+	for i := 0; i < l; i++ {
+		value, err := service.GetData(keys[i])
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+func (service *recordService) HasData(key []byte) bool {
+	value, err := service.GetData(key)
+	if err != nil {
+		return false
+	}
+	return len(value) > 0
+}
+
+func (service *recordService) PutData(key []byte, value []byte) error {
+	return errors.New("read only store")
+}
+
+func (service *recordService) PutDatas(keys [][]byte, values [][]byte) error {
+	return errors.New("read only store")
+}
+
+func (service *recordService) RemoveData(key []byte) error {
+	return errors.New("read only store")
+}
+
+func (service *recordService) Flush() error {
+	return nil
+}
+
+// Proof is the inclusion proof of Key with Value in the trie of Root, Data
+// is the serialized proof checked by VerifyProof.
+type Proof struct {
+	Root  libcore.Hash
+	Key   []byte
+	Value []byte
+	Data  []byte
+}
+
+// Prove reads key from a fresh trie opened on the committed data and returns
+// the entries it had to read as the proof, uncommitted changes are ignored.
+func (t *MerkleTree) Prove(key []byte) (*Proof, error) {
+	t.locker.RLock()
+	defer t.locker.RUnlock()
+
+	rs := newRecordService(t.ss)
+	mt := mpt.New(t.cs, rs)
+	value, err := mt.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, errors.New("no data for proof")
+	}
+	root := mt.RootHash()
+
+	data, err := core.Marshal(&pb.Proof{
+		Keys:   rs.keys,
+		Values: rs.values,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Proof{
+		Root:  libcore.Hash(root),
+		Key:   key,
+		Value: value,
+		Data:  data,
+	}, nil
+}
+
+// VerifyProof checks that key is bound to value in the trie of root. Every
+// entry of the proof must be addressed by its hash, except the one pointing
+// to the root whose value must be the root, so a proof can not forge the
+// nodes below the root. An error is returned when the proof is malformed or
+// misses a node on the path of key.
+func VerifyProof(root libcore.Hash, key []byte, value []byte, proof []byte) (bool, error) {
+	if len(root) == 0 || len(proof) == 0 {
+		return false, errors.New("error proof")
+	}
+	meta, msg, err := core.Unmarshal(proof)
+	if err != nil {
+		return false, err
+	}
+	if meta != core.CORE_PROOF {
+		return false, errors.New("error proof data")
+	}
+	p := msg.(*pb.Proof)
+	l := len(p.Keys)
+	if l != len(p.Values) {
+		return false, errors.New("error proof length")
+	}
+
+	cs := &crypto.CryptoService{}
+	ms := &store.MemoryService{}
+	err = ms.Init(nil)
+	if err != nil {
+		return false, err
+	}
+	pointers := 0
+	for i := 0; i < l; i++ {
+		h, err := cs.Hash(p.Values[i])
+		if err != nil {
+			return false, err
+		}
+		if !bytes.Equal(h, p.Keys[i]) {
+			if !bytes.Equal(p.Values[i], root) {
+				return false, nil
+			}
+			pointers++
+			if pointers > 1 {
+				return false, nil
+			}
+		}
+		err = ms.PutData(p.Keys[i], p.Values[i])
+		if err != nil {
+			return false, err
+		}
+	}
+
+	mt := mpt.New(cs, ms)
+	if !bytes.Equal(mt.RootHash(), root) {
+		return false, nil
+	}
+	data, err := mt.Get(key)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(data, value), nil
+}
+
+// ProveTransaction returns the proof of a transaction against the committed
+// transaction root, the key is the hash of the transaction with its receipt.
+func (service *MerkleService) ProveTransaction(txHash libcore.Hash) (*Proof, error) {
+	txKey := getHashKey("transaction", txHash)
+	h, err := service.im.GetData([]byte(txKey))
+	if err != nil {
+		return nil, err
+	}
+	if len(h) == 0 {
+		return nil, errors.New("no transaction")
+	}
+	return service.tm.Prove(h)
+}
+
+// ProveState returns the proof of the latest state of key against the
+// committed state root, the key is the hash of the state.
+func (service *MerkleService) ProveState(key string) (*Proof, error) {
+	newKey := getNameKey("state", key)
+	h, err := service.im.GetData([]byte(newKey))
+	if err != nil {
+		return nil, err
+	}
+	if len(h) == 0 {
+		return nil, errors.New("no state")
+	}
+	return service.sm.Prove(h)
+}
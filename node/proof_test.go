@@ -0,0 +1,105 @@
+package node
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tokentransfer/chain/core"
+	"github.com/tokentransfer/chain/core/pb"
+
+	. "github.com/tokentransfer/check"
+	libcrypto "github.com/tokentransfer/interfaces/crypto"
+)
+
+type ProofSuite struct{}
+
+func Test_Proof(t *testing.T) {
+	s := Suite(&ProofSuite{})
+	TestingRun(t, s)
+}
+
+// tamperProof returns the proof data with the entries changed by f.
+func tamperProof(c *C, data []byte, f func(p *pb.Proof)) []byte {
+	_, msg, err := core.Unmarshal(data)
+	c.Assert(err, IsNil)
+	p := msg.(*pb.Proof)
+	f(p)
+	tampered, err := core.Marshal(p)
+	c.Assert(err, IsNil)
+	return tampered
+}
+
+func (suite *ProofSuite) TestProve(c *C) {
+	chain := newTestChain(c, 3)
+	chain.grow(c, 8)
+	service := newTestService(c)
+	defer service.Close()
+	chain.putBlocks(c, service, 0)
+
+	txWithData := chain.blocks[4].GetTransactions()[0]
+	txHash, _, err := chain.cs.Raw(txWithData.GetTransaction(), libcrypto.RawBinary)
+	c.Assert(err, IsNil)
+	proof, err := service.ProveTransaction(txHash)
+	c.Assert(err, IsNil)
+	c.Assert([]byte(proof.Root), DeepEquals, []byte(service.GetTransactionRoot()))
+	_, raw, err := chain.cs.Raw(txWithData, libcrypto.RawBinary)
+	c.Assert(err, IsNil)
+	c.Assert(proof.Value, DeepEquals, raw)
+	ok, err := VerifyProof(proof.Root, proof.Key, proof.Value, proof.Data)
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, true)
+
+	address, err := chain.accounts[2].GetAddress()
+	c.Assert(err, IsNil)
+	proof, err = service.ProveState(address)
+	c.Assert(err, IsNil)
+	c.Assert([]byte(proof.Root), DeepEquals, []byte(chain.head().StateHash))
+	ok, err = VerifyProof(proof.Root, proof.Key, proof.Value, proof.Data)
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, true)
+
+	value := append([]byte(nil), proof.Value...)
+	value[len(value)-1]++
+	ok, err = VerifyProof(proof.Root, proof.Key, value, proof.Data)
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, false)
+
+	other := chain.blocks[3].StateHash
+	ok, err = VerifyProof(other, proof.Key, proof.Value, proof.Data)
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, false)
+
+	// a node changed is no longer addressed by its hash
+	tampered := tamperProof(c, proof.Data, func(p *pb.Proof) {
+		for i := 0; i < len(p.Values); i++ {
+			if bytes.Contains(p.Values[i], proof.Value) {
+				p.Values[i] = bytes.Replace(p.Values[i], proof.Value, value, 1)
+			}
+		}
+	})
+	ok, err = VerifyProof(proof.Root, proof.Key, value, tampered)
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, false)
+
+	// a root entry which only contains the root
+	tampered = tamperProof(c, proof.Data, func(p *pb.Proof) {
+		for i := 0; i < len(p.Values); i++ {
+			if bytes.Equal(p.Values[i], proof.Root) {
+				p.Values[i] = append(append([]byte{0}, proof.Root...), 0)
+			}
+		}
+	})
+	ok, err = VerifyProof(proof.Root, proof.Key, proof.Value, tampered)
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, false)
+
+	// a node missing on the path
+	tampered = tamperProof(c, proof.Data, func(p *pb.Proof) {
+		l := len(p.Keys)
+		p.Keys = p.Keys[:l-1]
+		p.Values = p.Values[:l-1]
+	})
+	ok, err = VerifyProof(proof.Root, proof.Key, proof.Value, tampered)
+	c.Assert(err, NotNil)
+	c.Assert(ok, Equals, false)
+}
@@ -0,0 +1,391 @@
+package node
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/tokentransfer/go-MerklePatriciaTree/mpt"
+
+	libcrypto "github.com/tokentransfer/interfaces/crypto"
+	libstore "github.com/tokentransfer/interfaces/store"
+)
+
+type StorageMode int
+
+const (
+	// ArchiveMode keeps every version of every trie node.
+	ArchiveMode StorageMode = iota
+	// PrunedMode removes the trie nodes which are not reachable from the
+	// roots of the last Retention blocks and were written before them, the
+	// Retention must not be 0.
+	PrunedMode
+)
+
+var (
+	pruneHeadKey    = []byte("head")
+	pruneJournalKey = []byte("journal")
+)
+
+// The prune database holds, for every tree, the block index of the last write
+// of the keys put in the tree as "key@tree@<key>" and of the nodes of the
+// tree as "node@tree@<node key>", the entries older than the retained blocks
+// are removed by each prune. "base@tree" holds the root entries of the
+// oldest root kept by the last prune: the nodes it reaches on the paths of
+// the keys written since are the older nodes which may no longer be
+// reachable.
+
+func getPruneKey(kind string, tree string, key []byte) []byte {
+	prefix := []byte(fmt.Sprintf("%s@%s@", kind, tree))
+	return append(prefix, key...)
+}
+
+// journalService records the block index at which each node is written.
+type journalService struct {
+	libstore.KvService
+
+	tree    string
+	service *MerkleService
+}
+
+func (js *journalService) PutData(key []byte, value []byte) error {
+	err := js.KvService.PutData(key, value)
+	if err != nil {
+		return err
+	}
+	return js.service.prunedb.PutData(getPruneKey("node", js.tree, key), putUint64(js.service.height))
+}
+
+func (js *journalService) PutDatas(keys [][]byte, values [][]byte) error {
+	err := js.KvService.PutDatas(keys, values)
+	if err != nil {
+		return err
+	}
+	l := len(keys)
+	nodeKeys := make([][]byte, l)
+	heights := make([][]byte, l)
+	for i := 0; i < l; i++ {
+		nodeKeys[i] = getPruneKey("node", js.tree, keys[i])
+		heights[i] = putUint64(js.service.height)
+	}
+	return js.service.prunedb.PutDatas(nodeKeys, heights)
+}
+
+func (service *MerkleService) journalKey(tree string) func(key []byte) error {
+	return func(key []byte) error {
+		return service.prunedb.PutData(getPruneKey("key", tree, key), putUint64(service.height))
+	}
+}
+
+// initJournal starts the journal of a new data directory, pruning is refused
+// on a data directory which was not journaled from the genesis block.
+func (service *MerkleService) initJournal() error {
+	if service.prunedb.HasData(pruneJournalKey) {
+		return nil
+	}
+	if service.getGenesisHash() != nil {
+		return nil
+	}
+	return service.prunedb.PutData(pruneJournalKey, []byte{1})
+}
+
+func (service *MerkleService) trees() map[string]*MerkleTree {
+	return map[string]*MerkleTree{
+		"index":       service.im,
+		"block":       service.bm,
+		"transaction": service.tm,
+		"state":       service.sm,
+	}
+}
+
+// Prune removes the nodes of the trees which are not reachable from the
+// committed roots or from the roots saved after the last Retention blocks,
+// it must not run while a block is being put. Only the keys written since
+// the last prune are resolved: the nodes read from the retained roots are
+// marked, as "mark@tree@<node key>", the ones read from the base root of the
+// last prune and the ones written since are the candidates, as
+// "cand@tree@<node key>", so the work and the memory used follow the blocks
+// pruned and not the trees.
+func (service *MerkleService) Prune() error {
+	if service.Mode != PrunedMode {
+		return errors.New("pruning is disabled in archive mode")
+	}
+	if !service.prunedb.HasData(pruneJournalKey) {
+		return errors.New("data directory was not journaled from the genesis block")
+	}
+	data, err := service.prunedb.GetData(pruneHeadKey)
+	if err != nil {
+		return err
+	}
+	head, ok := getUint64(data)
+	if !ok {
+		return nil
+	}
+	if head < service.Retention {
+		return nil
+	}
+	limit := head - service.Retention
+
+	for name, t := range service.trees() {
+		err := service.pruneTree(name, t, limit, head)
+		if err != nil {
+			return err
+		}
+	}
+	service.pruned = head
+	return nil
+}
+
+// markService is a read only view of a KvService which marks every entry
+// read through it and the nodes referenced by the nodes read: a put which
+// splits a node rewrites its other child, which is on the path of no key
+// written. When entries is set, the entries read which are not nodes, the
+// root entries, are recorded in it.
+type markService struct {
+	libstore.KvService
+
+	cs      libcrypto.CryptoService
+	entries map[string][]byte
+	mark    func(key []byte) error
+}
+
+func (ms *markService) GetData(key []byte) ([]byte, error) {
+	value, err := ms.KvService.GetData(key)
+	if err != nil || value == nil {
+		return value, err
+	}
+	node, err := isNode(ms.cs, key, value)
+	if err != nil {
+		return nil, err
+	}
+	if node {
+		err = ms.markChildren(value, len(key))
+		if err != nil {
+			return nil, err
+		}
+	} else if ms.entries != nil {
+		ms.entries[string(key)] = append([]byte(nil), value...)
+	}
+	err = ms.mark(key)
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// markChildren marks the nodes whose key appears in value, the encoding of
+// a node holds the keys of its children.
+func (ms *markService) markChildren(value []byte, size int) error {
+	l := len(value) - size
+	for i := 0; i <= l; i++ {
+		key := value[i : i+size]
+		data, err := ms.KvService.GetData(key)
+		if err != nil {
+			return err
+		}
+		if len(data) == 0 {
+			continue
+		}
+		node, err := isNode(ms.cs, key, data)
+		if err != nil {
+			return err
+		}
+		if node {
+			err = ms.mark(append([]byte(nil), key...))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (ms *markService) PutData(key []byte, value []byte) error {
+	return errors.New("read only store")
+}
+
+func (ms *markService) PutDatas(keys [][]byte, values [][]byte) error {
+	return errors.New("read only store")
+}
+
+func (ms *markService) RemoveData(key []byte) error {
+	return errors.New("read only store")
+}
+
+// listPrune calls each with the keys of kind of tree in the prune database,
+// without their prefix, and their value.
+func (service *MerkleService) listPrune(kind string, tree string, each func(key []byte, value []byte) error) error {
+	prefix := getPruneKey(kind, tree, nil)
+	return service.prunedb.ListData(func(key []byte, value []byte) error {
+		if !bytes.HasPrefix(key, prefix) {
+			return nil
+		}
+		return each(append([]byte(nil), key[len(prefix):]...), value)
+	})
+}
+
+// markTrie reads from mt every key written in tree since the last prune.
+func (service *MerkleService) markTrie(tree string, mt *mpt.Trie) error {
+	return service.listPrune("key", tree, func(key []byte, value []byte) error {
+		_, err := mt.Get(key)
+		return err
+	})
+}
+
+func (service *MerkleService) pruneTree(name string, t *MerkleTree, limit uint64, head uint64) error {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+
+	// the candidates, the nodes of the base root on the paths of the keys
+	// written since the last prune
+	base, err := service.prunedb.GetData(getPruneKey("base", name, nil))
+	if err != nil {
+		return err
+	}
+	if len(base) > 0 {
+		entries, err := decodeRoots(base)
+		if err != nil {
+			return err
+		}
+		cs := &markService{
+			KvService: t.ss,
+			cs:        t.cs,
+			mark: func(key []byte) error {
+				return service.prunedb.PutData(getPruneKey("cand", name, key), []byte{1})
+			},
+		}
+		view := &rootView{KvService: cs, cs: t.cs, entries: entries}
+		err = service.markTrie(name, mpt.New(t.cs, view))
+		if err != nil {
+			return err
+		}
+	}
+
+	// mark, the nodes read to resolve the keys from the current root and
+	// from the roots saved after the retained blocks are the reachable ones
+	ms := &markService{
+		KvService: t.ss,
+		cs:        t.cs,
+		entries:   make(map[string][]byte),
+		mark: func(key []byte) error {
+			return service.prunedb.PutData(getPruneKey("mark", name, key), []byte{1})
+		},
+	}
+	err = service.markTrie(name, mpt.New(t.cs, ms))
+	if err != nil {
+		return err
+	}
+	oldest := ms.entries
+	ms.entries = nil
+	_, ok := service.roots[name]
+	if ok {
+		oldest, err = service.findRoots(name, limit)
+		if err != nil {
+			return err
+		}
+	}
+	var last []byte
+	for index := limit; ok && index <= head; index++ {
+		entries := oldest
+		if index > limit {
+			data, err := service.rootdb.GetData(getRootEntriesKey(name, index))
+			if err != nil {
+				return err
+			}
+			if len(data) == 0 || bytes.Equal(data, last) {
+				continue
+			}
+			last = data
+			entries, err = service.getRoots(name, index)
+			if err != nil {
+				return err
+			}
+		}
+		view := &rootView{KvService: ms, cs: t.cs, entries: entries}
+		err = service.markTrie(name, mpt.New(t.cs, view))
+		if err != nil {
+			return err
+		}
+	}
+
+	// sweep the candidates, then the nodes written since the last prune
+	// which were written before the retained blocks
+	isMarked := func(key []byte) bool {
+		return service.prunedb.HasData(getPruneKey("mark", name, key))
+	}
+	err = service.listPrune("cand", name, func(key []byte, value []byte) error {
+		err := service.prunedb.RemoveData(getPruneKey("cand", name, key))
+		if err != nil {
+			return err
+		}
+		if isMarked(key) || service.prunedb.HasData(getPruneKey("node", name, key)) {
+			return nil
+		}
+		return t.ss.RemoveData(key)
+	})
+	if err != nil {
+		return err
+	}
+	err = service.listPrune("node", name, func(key []byte, value []byte) error {
+		height, ok := getUint64(value)
+		if ok && height >= limit {
+			return nil
+		}
+		if !isMarked(key) {
+			err := t.ss.RemoveData(key)
+			if err != nil {
+				return err
+			}
+		}
+		return service.prunedb.RemoveData(getPruneKey("node", name, key))
+	})
+	if err != nil {
+		return err
+	}
+	err = service.listPrune("mark", name, func(key []byte, value []byte) error {
+		return service.prunedb.RemoveData(getPruneKey("mark", name, key))
+	})
+	if err != nil {
+		return err
+	}
+
+	// the keys written before the retained blocks are in the new base
+	err = service.listPrune("key", name, func(key []byte, value []byte) error {
+		height, ok := getUint64(value)
+		if ok && height >= limit {
+			return nil
+		}
+		return service.prunedb.RemoveData(getPruneKey("key", name, key))
+	})
+	if err != nil {
+		return err
+	}
+	data, err := encodeRoots(oldest)
+	if err != nil {
+		return err
+	}
+	return service.prunedb.PutData(getPruneKey("base", name, nil), data)
+}
+
+// afterCommit records the head committed and prunes the trees every
+// Retention blocks.
+func (service *MerkleService) afterCommit() error {
+	if service.Mode != PrunedMode {
+		return nil
+	}
+	head, ok, err := service.getHead()
+	if err != nil || !ok {
+		return err
+	}
+	err = service.prunedb.PutData(pruneHeadKey, putUint64(head))
+	if err != nil {
+		return err
+	}
+	if head < service.pruned+service.Retention {
+		return nil
+	}
+	if !service.prunedb.HasData(pruneJournalKey) {
+		return nil
+	}
+	return service.Prune()
+}
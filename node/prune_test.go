@@ -0,0 +1,147 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/tokentransfer/chain/block"
+
+	. "github.com/tokentransfer/check"
+)
+
+type PruneSuite struct{}
+
+func Test_Prune(t *testing.T) {
+	s := Suite(&PruneSuite{})
+	TestingRun(t, s)
+}
+
+func countEntries(c *C, t *MerkleTree) int {
+	count := 0
+	err := t.ss.ListData(func(key []byte, value []byte) error {
+		count++
+		return nil
+	})
+	c.Assert(err, IsNil)
+	return count
+}
+
+func (suite *PruneSuite) TestPrune(c *C) {
+	chain := newTestChain(c, 3)
+	chain.grow(c, 12)
+
+	archive := newTestService(c)
+	defer archive.Close()
+	chain.putBlocks(c, archive, 0)
+
+	dir := c.MkDir()
+	service := openTestService(c, dir, &MerkleService{Mode: PrunedMode, Retention: 3})
+	chain.putBlocks(c, service, 0)
+	err := service.Prune()
+	c.Assert(err, IsNil)
+	c.Assert(countEntries(c, service.im) < countEntries(c, archive.im), Equals, true)
+
+	// the states of the retained blocks, and the latest ones
+	checkStates := func(service *MerkleService) {
+		for i := uint64(12 - 3); i <= 12; i++ {
+			at := chain.fork(c, i)
+			for j := 0; j < len(chain.accounts); j++ {
+				address, err := chain.accounts[j].GetAddress()
+				c.Assert(err, IsNil)
+				expected, err := at.getState(c, j)
+				c.Assert(err, IsNil)
+				s, err := service.GetStateAt(address, i)
+				c.Assert(err, IsNil)
+				c.Assert(s.(*block.AccountState).Amount, Equals, expected.Amount)
+				if i == 12 {
+					s, err = service.GetStateByKey(address)
+					c.Assert(err, IsNil)
+					c.Assert(s.(*block.AccountState).Amount, Equals, expected.Amount)
+				}
+			}
+		}
+		report, err := service.Verify()
+		c.Assert(err, IsNil)
+		c.Assert(report.Errors, IsNil)
+	}
+	checkStates(service)
+
+	// the marks are removed and the head is the committed one after a restart
+	count := 0
+	err = service.prunedb.ListData(func(key []byte, value []byte) error {
+		if string(key[:5]) == "mark@" {
+			count++
+		}
+		return nil
+	})
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, 0)
+	err = service.Close()
+	c.Assert(err, IsNil)
+
+	service = openTestService(c, dir, &MerkleService{Mode: PrunedMode, Retention: 3})
+	defer service.Close()
+	c.Assert(service.height, Equals, uint64(12))
+	err = service.Commit()
+	c.Assert(err, IsNil)
+	data, err := service.prunedb.GetData(pruneHeadKey)
+	c.Assert(err, IsNil)
+	head, _ := getUint64(data)
+	c.Assert(head, Equals, uint64(12))
+	err = service.Prune()
+	c.Assert(err, IsNil)
+	checkStates(service)
+
+	err = (&MerkleService{Mode: PrunedMode}).Init(&testConfig{dir: c.MkDir()})
+	c.Assert(err, NotNil)
+}
+
+func (suite *PruneSuite) TestJournal(c *C) {
+	chain := newTestChain(c, 3)
+	chain.grow(c, 12)
+
+	service := openTestService(c, c.MkDir(), &MerkleService{Mode: PrunedMode, Retention: 3})
+	defer service.Close()
+	chain.putBlocks(c, service, 0)
+	err := service.Prune()
+	c.Assert(err, IsNil)
+
+	// the keys and the nodes written before the retained blocks are no
+	// longer journaled, the base roots are kept for the next prune
+	keys := 0
+	err = service.prunedb.ListData(func(key []byte, value []byte) error {
+		kind := string(key[:5])
+		if kind[:4] == "key@" || kind == "node@" {
+			height, ok := getUint64(value)
+			c.Assert(ok, Equals, true)
+			c.Assert(height >= 12-3, Equals, true)
+		}
+		if kind[:4] == "key@" {
+			keys++
+		}
+		c.Assert(kind == "cand@" || kind == "mark@", Equals, false)
+		return nil
+	})
+	c.Assert(err, IsNil)
+	c.Assert(keys > 0, Equals, true)
+	c.Assert(service.prunedb.HasData(getPruneKey("base", "index", nil)), Equals, true)
+
+	chain.grow(c, 6)
+	chain.putBlocks(c, service, 13)
+	err = service.Prune()
+	c.Assert(err, IsNil)
+	for i := uint64(18 - 3); i <= 18; i++ {
+		at := chain.fork(c, i)
+		for j := 0; j < len(chain.accounts); j++ {
+			address, err := chain.accounts[j].GetAddress()
+			c.Assert(err, IsNil)
+			expected, err := at.getState(c, j)
+			c.Assert(err, IsNil)
+			s, err := service.GetStateAt(address, i)
+			c.Assert(err, IsNil)
+			c.Assert(s.(*block.AccountState).Amount, Equals, expected.Amount)
+		}
+	}
+	report, err := service.Verify()
+	c.Assert(err, IsNil)
+	c.Assert(report.Errors, IsNil)
+}
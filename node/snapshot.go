@@ -0,0 +1,473 @@
+package node
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/tokentransfer/go-MerklePatriciaTree/mpt"
+
+	"github.com/tokentransfer/chain/block"
+	"github.com/tokentransfer/chain/core"
+	"github.com/tokentransfer/chain/core/pb"
+
+	libblock "github.com/tokentransfer/interfaces/block"
+	libcore "github.com/tokentransfer/interfaces/core"
+	libcrypto "github.com/tokentransfer/interfaces/crypto"
+	libstore "github.com/tokentransfer/interfaces/store"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	SnapshotVersion  = uint32(2)
+	DefaultChunkSize = 1024
+)
+
+// A snapshot is a header holding the block it was taken at, the genesis
+// block and the root entries of the state trie after the block, followed by
+// chunks of trie nodes and states. The nodes are the ones of the state trie
+// at the block, the trie keeps every version of every state so they are
+// needed to rebuild a trie of root StateHash which the next blocks can
+// change. The states are the state at the block, the last version of every
+// key, they are indexed.
+
+func hashChunk(cs libcrypto.CryptoService, chunk *pb.SnapshotChunk) (libcore.Hash, error) {
+	var buf bytes.Buffer
+	lists := [][][]byte{chunk.States, chunk.NodeKeys, chunk.Nodes}
+	for _, list := range lists {
+		err := core.WriteBytes(&buf, putUint64(uint64(len(list))))
+		if err != nil {
+			return nil, err
+		}
+		for _, data := range list {
+			err := core.WriteBytes(&buf, data)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return cs.Hash(buf.Bytes())
+}
+
+func writeMessage(w io.Writer, msg proto.Message) error {
+	data, err := core.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return core.WriteBytes(w, data)
+}
+
+// nodeService is a read only view of a KvService which passes every entry
+// addressed by its hash read through it to emit, once.
+type nodeService struct {
+	libstore.KvService
+
+	cs   libcrypto.CryptoService
+	seen map[string]bool
+	emit func(key []byte, value []byte) error
+}
+
+func (ns *nodeService) GetData(key []byte) ([]byte, error) {
+	value, err := ns.KvService.GetData(key)
+	if err != nil || len(value) == 0 || ns.seen[string(key)] {
+		return value, err
+	}
+	node, err := isNode(ns.cs, key, value)
+	if err != nil {
+		return nil, err
+	}
+	if !node {
+		return value, nil
+	}
+	ns.seen[string(key)] = true
+	err = ns.emit(key, value)
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// nextIndex returns the index of the block stored after block i, the blocks
+// between the genesis block and the snapshot block of a data directory
+// imported from a snapshot are missing.
+func (service *MerkleService) nextIndex(i uint64) uint64 {
+	if i == 0 && service.meta.SnapshotHeight > 0 {
+		return service.meta.SnapshotHeight
+	}
+	return i + 1
+}
+
+// isHeader returns true when block i is stored without its transactions and
+// states, the genesis and the snapshot blocks of a data directory imported
+// from a snapshot.
+func (service *MerkleService) isHeader(i uint64) bool {
+	return service.meta.SnapshotHeight > 0 && (i == 0 || i == service.meta.SnapshotHeight)
+}
+
+// ExportSnapshot writes the snapshot of the state at the end of block
+// blockIndex, chunkSize is the number of nodes and states in a chunk. The
+// state keys are read from the blocks up to blockIndex, a data directory
+// imported from a snapshot does not have them.
+func (service *MerkleService) ExportSnapshot(blockIndex uint64, chunkSize int, w io.Writer) error {
+	cs := service.CryptoService
+
+	if service.meta.SnapshotHeight > 0 {
+		return fmt.Errorf("data directory imported from the snapshot of block %d can not export a snapshot", service.meta.SnapshotHeight)
+	}
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	h, err := service.getBlockHash(blockIndex)
+	if err != nil {
+		return err
+	}
+	if h == nil {
+		return fmt.Errorf("no block %d", blockIndex)
+	}
+	data, err := service.bm.GetData(h)
+	if err != nil {
+		return err
+	}
+	genesis, err := service.bm.GetData(service.getGenesisHash())
+	if err != nil {
+		return err
+	}
+	entries, err := service.findRoots("state", blockIndex)
+	if err != nil {
+		return err
+	}
+	header := &pb.SnapshotHeader{
+		Version:   SnapshotVersion,
+		Block:     data,
+		ChunkSize: uint32(chunkSize),
+		Genesis:   genesis,
+	}
+	for key, value := range entries {
+		header.RootKeys = append(header.RootKeys, []byte(key))
+		header.RootValues = append(header.RootValues, value)
+	}
+	err = writeMessage(w, header)
+	if err != nil {
+		return err
+	}
+
+	chunk := &pb.SnapshotChunk{}
+	flush := func(last bool) error {
+		chunk.Last = last
+		hash, err := hashChunk(cs, chunk)
+		if err != nil {
+			return err
+		}
+		chunk.Hash = hash
+		err = writeMessage(w, chunk)
+		if err != nil {
+			return err
+		}
+		chunk = &pb.SnapshotChunk{Index: chunk.Index + 1}
+		return nil
+	}
+	full := func() bool {
+		return len(chunk.States)+len(chunk.Nodes) >= chunkSize
+	}
+
+	ns := &nodeService{
+		KvService: service.roots["state"].KvService,
+		cs:        cs,
+		seen:      make(map[string]bool),
+		emit: func(key []byte, value []byte) error {
+			chunk.NodeKeys = append(chunk.NodeKeys, append([]byte(nil), key...))
+			chunk.Nodes = append(chunk.Nodes, append([]byte(nil), value...))
+			if full() {
+				return flush(false)
+			}
+			return nil
+		},
+	}
+	mt := mpt.New(cs, &rootView{KvService: ns, cs: cs, entries: entries})
+	keys := make([]string, 0)
+	seen := make(map[string]bool)
+	for i := uint64(0); i <= blockIndex; i++ {
+		b, err := service.GetBlockByIndex(i)
+		if err != nil {
+			return err
+		}
+		for _, s := range b.GetStates() {
+			sh, raw, err := cs.Raw(s, libcrypto.RawBinary)
+			if err != nil {
+				return err
+			}
+			value, err := mt.Get(sh)
+			if err != nil {
+				return err
+			}
+			if !bytes.Equal(value, raw) {
+				return fmt.Errorf("error state %s of block %d", sh.String(), i)
+			}
+			key := s.GetStateKey()
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	for _, key := range keys {
+		s, err := service.GetStateAt(key, blockIndex)
+		if err != nil {
+			return err
+		}
+		data, err := s.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		chunk.States = append(chunk.States, data)
+		if full() {
+			err = flush(false)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return flush(true)
+}
+
+func readMessage(r io.Reader, meta byte) (proto.Message, error) {
+	data, err := core.ReadBytes(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("error snapshot data")
+	}
+	m, msg, err := core.Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	if m != meta {
+		return nil, fmt.Errorf("error snapshot data %s", core.GetInfo(data))
+	}
+	return msg, nil
+}
+
+// ImportSnapshot rebuilds the state trie and the state indexes of an empty
+// data directory from a snapshot and stores the genesis and header blocks.
+// The header block must have the hash expected, taken from a trusted
+// source, the nodes must be addressed by their hash and rebuild the trie of
+// root StateHash of the header block, every state must be in the trie. The
+// nodes are written as they are read, they are not reachable until the
+// root entries are written, the rest is committed once everything is
+// checked. The nodes of a failed import are left in the data directory.
+// The index of the header block is recorded as the SnapshotHeight of the
+// metadata.
+func (service *MerkleService) ImportSnapshot(r io.Reader, expected libcore.Hash) (libblock.Block, error) {
+	cs := service.CryptoService
+
+	if len(expected) == 0 {
+		return nil, errors.New("no trusted snapshot block hash")
+	}
+	if service.getGenesisHash() != nil {
+		return nil, errors.New("snapshot must be imported in an empty data directory")
+	}
+	err := service.Cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := readMessage(r, core.CORE_SNAPSHOT_HEADER)
+	if err != nil {
+		return nil, err
+	}
+	header := msg.(*pb.SnapshotHeader)
+	if header.Version != SnapshotVersion {
+		return nil, fmt.Errorf("error snapshot version %d", header.Version)
+	}
+	b := &block.Block{}
+	err = b.UnmarshalBinary(header.Block)
+	if err != nil {
+		return nil, err
+	}
+	bh, _, err := cs.Raw(b, libcrypto.RawBinary)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(bh, expected) {
+		return nil, fmt.Errorf("snapshot has block %s, not %s", bh.String(), expected.String())
+	}
+	genesis := &block.Block{}
+	err = genesis.UnmarshalBinary(header.Genesis)
+	if err != nil {
+		return nil, err
+	}
+	if genesis.GetIndex() != 0 {
+		return nil, fmt.Errorf("error snapshot genesis block %d", genesis.GetIndex())
+	}
+	gh, _, err := cs.Raw(genesis, libcrypto.RawBinary)
+	if err != nil {
+		return nil, err
+	}
+	if len(service.GenesisHash) > 0 && !bytes.Equal(gh, service.GenesisHash) {
+		return nil, fmt.Errorf("snapshot has genesis %s, not %s", gh.String(), service.GenesisHash.String())
+	}
+	if b.GetIndex() == 0 && !bytes.Equal(header.Block, header.Genesis) {
+		return nil, errors.New("error snapshot genesis block")
+	}
+	if len(header.RootKeys) == 0 || len(header.RootKeys) != len(header.RootValues) {
+		return nil, errors.New("error snapshot root")
+	}
+	entries := make(map[string][]byte)
+	for i := 0; i < len(header.RootKeys); i++ {
+		if !bytes.Equal(header.RootValues[i], b.GetStateHash()) {
+			return nil, errors.New("error snapshot root")
+		}
+		entries[string(header.RootKeys[i])] = header.RootValues[i]
+	}
+	rs := service.roots["state"]
+
+	hashes := make([]libcore.Hash, 0)
+	values := make([][]byte, 0)
+	err = func() error {
+		for index := uint32(0); ; index++ {
+			msg, err := readMessage(r, core.CORE_SNAPSHOT_CHUNK)
+			if err != nil {
+				return err
+			}
+			chunk := msg.(*pb.SnapshotChunk)
+			if chunk.Index != index {
+				return fmt.Errorf("error snapshot chunk %d, expected %d", chunk.Index, index)
+			}
+			if len(chunk.States)+len(chunk.Nodes) > int(header.ChunkSize) || len(chunk.Nodes) != len(chunk.NodeKeys) {
+				return fmt.Errorf("error snapshot chunk %d size", index)
+			}
+			hash, err := hashChunk(cs, chunk)
+			if err != nil {
+				return err
+			}
+			if !bytes.Equal(hash, chunk.Hash) {
+				return fmt.Errorf("error snapshot chunk %d hash", index)
+			}
+
+			for i := 0; i < len(chunk.Nodes); i++ {
+				h, err := cs.Hash(chunk.Nodes[i])
+				if err != nil {
+					return err
+				}
+				if !bytes.Equal(h, chunk.NodeKeys[i]) {
+					return fmt.Errorf("error snapshot node %x", chunk.NodeKeys[i])
+				}
+				err = rs.KvService.PutData(chunk.NodeKeys[i], chunk.Nodes[i])
+				if err != nil {
+					return err
+				}
+			}
+			for _, data := range chunk.States {
+				s, err := block.ReadState(data)
+				if err != nil {
+					return err
+				}
+				if s.GetBlockIndex() > b.GetIndex() {
+					return fmt.Errorf("error snapshot state of block %d", s.GetBlockIndex())
+				}
+				h, raw, err := cs.Raw(s, libcrypto.RawBinary)
+				if err != nil {
+					return err
+				}
+				err = service.indexState(s, h)
+				if err != nil {
+					return err
+				}
+				hashes = append(hashes, h)
+				values = append(values, raw)
+			}
+
+			if chunk.Last {
+				return nil
+			}
+		}
+	}()
+	if err != nil {
+		service.Cancel()
+		return nil, err
+	}
+
+	mt := mpt.New(cs, &rootView{KvService: rs.KvService, cs: cs, entries: entries})
+	root := libcore.Hash(mt.RootHash())
+	if !bytes.Equal(root, b.GetStateHash()) {
+		service.Cancel()
+		return nil, fmt.Errorf("error snapshot state root %s, expected %s", root.String(), b.GetStateHash().String())
+	}
+	for i, h := range hashes {
+		value, err := mt.Get(h)
+		if err != nil {
+			service.Cancel()
+			return nil, err
+		}
+		if !bytes.Equal(value, values[i]) {
+			service.Cancel()
+			return nil, fmt.Errorf("error snapshot state %s", h.String())
+		}
+	}
+
+	for key, value := range entries {
+		err := rs.PutData([]byte(key), value)
+		if err != nil {
+			service.Cancel()
+			return nil, err
+		}
+	}
+	service.sm.reload()
+	if b.GetIndex() > 0 {
+		err = service.putBlockHeader(genesis, header.Genesis)
+		if err != nil {
+			service.Cancel()
+			return nil, err
+		}
+	}
+	err = service.putBlockHeader(b, header.Block)
+	if err != nil {
+		service.Cancel()
+		return nil, err
+	}
+	// the metadata is written first, an import stopped before the commit
+	// leaves the data directory empty and can be run again
+	service.meta.SnapshotHeight = b.GetIndex()
+	err = writeMetadata(service.metadb, service.meta)
+	if err != nil {
+		service.Cancel()
+		return nil, err
+	}
+	err = service.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// putBlockHeader stores a block without its transactions and states.
+func (service *MerkleService) putBlockHeader(b libblock.Block, data []byte) error {
+	h, _, err := service.CryptoService.Raw(b, libcrypto.RawBinary)
+	if err != nil {
+		return err
+	}
+	service.height = b.GetIndex()
+	err = service.bm.PutData(h, data)
+	if err != nil {
+		return err
+	}
+	err = service.im.PutData([]byte(getBlockKey(b.GetIndex())), h)
+	if err != nil {
+		return err
+	}
+	err = service.addBlockHash(b.GetIndex(), h)
+	if err != nil {
+		return err
+	}
+	err = service.im.PutData(headKey, putUint64(b.GetIndex()))
+	if err != nil {
+		return err
+	}
+	if b.GetIndex() == 0 && len(service.meta.GenesisHash) == 0 {
+		service.genesis = h
+	}
+	return service.im.PutData([]byte(getRootKey(b.GetIndex())), b.GetStateHash())
+}
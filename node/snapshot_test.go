@@ -0,0 +1,220 @@
+package node
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tokentransfer/chain/block"
+	"github.com/tokentransfer/chain/core"
+	"github.com/tokentransfer/chain/core/pb"
+	"github.com/tokentransfer/chain/crypto"
+
+	. "github.com/tokentransfer/check"
+)
+
+type SnapshotSuite struct{}
+
+func Test_Snapshot(t *testing.T) {
+	s := Suite(&SnapshotSuite{})
+	TestingRun(t, s)
+}
+
+// tamperSnapshot returns the snapshot data with the header changed by
+// header and every chunk changed by chunk, the chunk hashes are updated.
+func tamperSnapshot(c *C, data []byte, header func(h *pb.SnapshotHeader), chunk func(k *pb.SnapshotChunk)) []byte {
+	cs := &crypto.CryptoService{}
+	r := bytes.NewReader(data)
+	var buf bytes.Buffer
+
+	msg, err := readMessage(r, core.CORE_SNAPSHOT_HEADER)
+	c.Assert(err, IsNil)
+	h := msg.(*pb.SnapshotHeader)
+	if header != nil {
+		header(h)
+	}
+	err = writeMessage(&buf, h)
+	c.Assert(err, IsNil)
+	for {
+		msg, err := readMessage(r, core.CORE_SNAPSHOT_CHUNK)
+		c.Assert(err, IsNil)
+		k := msg.(*pb.SnapshotChunk)
+		if chunk != nil {
+			chunk(k)
+			k.Hash, err = hashChunk(cs, k)
+			c.Assert(err, IsNil)
+		}
+		err = writeMessage(&buf, k)
+		c.Assert(err, IsNil)
+		if k.Last {
+			break
+		}
+	}
+	return buf.Bytes()
+}
+
+func (suite *SnapshotSuite) TestRoundTrip(c *C) {
+	chain := newTestChain(c, 3)
+	chain.grow(c, 8)
+	service := newTestService(c)
+	defer service.Close()
+	chain.putBlocks(c, service, 0)
+
+	for _, index := range []uint64{8, 4, 0} {
+		var buf bytes.Buffer
+		err := service.ExportSnapshot(index, 4, &buf)
+		c.Assert(err, IsNil)
+
+		imported := newTestService(c)
+		b, err := imported.ImportSnapshot(&buf, chain.hash(c, chain.blocks[index]))
+		c.Assert(err, IsNil)
+		c.Assert(b.GetIndex(), Equals, index)
+		c.Assert(imported.GetMetadata().SnapshotHeight, Equals, index)
+		c.Assert([]byte(imported.GetStateRoot()), DeepEquals, []byte(chain.blocks[index].StateHash))
+		c.Assert([]byte(imported.GetMetadata().GenesisHash), DeepEquals, []byte(chain.hash(c, chain.blocks[0])))
+		genesis, err := imported.GetBlockByIndex(0)
+		c.Assert(err, IsNil)
+		c.Assert([]byte(chain.hash(c, genesis)), DeepEquals, []byte(chain.hash(c, chain.blocks[0])))
+		head, err := imported.GetHead()
+		c.Assert(err, IsNil)
+		c.Assert(head.GetIndex(), Equals, index)
+
+		at := chain.fork(c, index)
+		for j := 0; j < len(chain.accounts); j++ {
+			address, err := chain.accounts[j].GetAddress()
+			c.Assert(err, IsNil)
+			expected, err := at.getState(c, j)
+			c.Assert(err, IsNil)
+			s, err := imported.GetStateByKey(address)
+			c.Assert(err, IsNil)
+			c.Assert(s.(*block.AccountState).Sequence, Equals, expected.Sequence)
+			c.Assert(s.(*block.AccountState).Amount, Equals, expected.Amount)
+		}
+
+		// the next blocks are put over the imported trie
+		at.grow(c, 2)
+		at.putBlocks(c, imported, index+1)
+		c.Assert([]byte(imported.GetStateRoot()), DeepEquals, []byte(at.head().StateHash))
+
+		// the walks skip the missing blocks
+		report, err := imported.Verify()
+		c.Assert(err, IsNil)
+		c.Assert(report.Errors, IsNil)
+		if index > 0 {
+			c.Assert(report.Blocks, Equals, uint64(4))
+			step, ok := getMigration(2)
+			c.Assert(ok, Equals, true)
+			err = step.Migrate(imported)
+			c.Assert(err, IsNil)
+			head, err := imported.GetHead()
+			c.Assert(err, IsNil)
+			c.Assert(head.GetIndex(), Equals, index+2)
+
+			err = imported.ExportSnapshot(index, 4, &buf)
+			c.Assert(err, NotNil)
+			err = imported.RebuildIndex(nil)
+			c.Assert(err, NotNil)
+		}
+		err = imported.Close()
+		c.Assert(err, IsNil)
+	}
+}
+
+func (suite *SnapshotSuite) TestTrustedHash(c *C) {
+	chain := newTestChain(c, 3)
+	chain.grow(c, 4)
+	service := newTestService(c)
+	defer service.Close()
+	chain.putBlocks(c, service, 0)
+
+	var buf bytes.Buffer
+	err := service.ExportSnapshot(4, 4, &buf)
+	c.Assert(err, IsNil)
+	data := buf.Bytes()
+
+	for _, expected := range [][]byte{nil, chain.hash(c, chain.blocks[3])} {
+		imported := newTestService(c)
+		_, err = imported.ImportSnapshot(bytes.NewReader(data), expected)
+		c.Assert(err, NotNil)
+		_, err = imported.GetHead()
+		c.Assert(err, NotNil)
+		err = imported.Close()
+		c.Assert(err, IsNil)
+	}
+
+	// a snapshot of another block with its own trie is refused too
+	var other bytes.Buffer
+	err = service.ExportSnapshot(3, 4, &other)
+	c.Assert(err, IsNil)
+	imported := newTestService(c)
+	defer imported.Close()
+	_, err = imported.ImportSnapshot(&other, chain.hash(c, chain.blocks[4]))
+	c.Assert(err, NotNil)
+}
+
+func (suite *SnapshotSuite) TestTamper(c *C) {
+	chain := newTestChain(c, 3)
+	chain.grow(c, 6)
+	service := newTestService(c)
+	defer service.Close()
+	chain.putBlocks(c, service, 0)
+
+	var buf bytes.Buffer
+	err := service.ExportSnapshot(6, 8, &buf)
+	c.Assert(err, IsNil)
+	data := buf.Bytes()
+
+	tampered := [][]byte{
+		// a node changed
+		tamperSnapshot(c, data, nil, func(k *pb.SnapshotChunk) {
+			if len(k.Nodes) > 0 {
+				k.Nodes[0] = append([]byte(nil), k.Nodes[0]...)
+				k.Nodes[0][len(k.Nodes[0])-1]++
+			}
+		}),
+		// a node missing
+		tamperSnapshot(c, data, nil, func(k *pb.SnapshotChunk) {
+			if k.Index == 0 && len(k.Nodes) > 0 {
+				k.Nodes = k.Nodes[1:]
+				k.NodeKeys = k.NodeKeys[1:]
+			}
+		}),
+		// a state changed
+		tamperSnapshot(c, data, nil, func(k *pb.SnapshotChunk) {
+			if len(k.States) > 0 {
+				s := chain.accountState(0, 3)
+				s.Amount = 1
+				state, err := s.MarshalBinary()
+				c.Assert(err, IsNil)
+				k.States[0] = state
+			}
+		}),
+		// the root entries of another block
+		tamperSnapshot(c, data, func(h *pb.SnapshotHeader) {
+			for i := 0; i < len(h.RootValues); i++ {
+				h.RootValues[i] = chain.blocks[5].StateHash
+			}
+		}, nil),
+		// a chunk changed without its hash
+		func() []byte {
+			t := append([]byte(nil), data...)
+			t[len(t)-2]++
+			return t
+		}(),
+	}
+	for _, t := range tampered {
+		imported := newTestService(c)
+		_, err := imported.ImportSnapshot(bytes.NewReader(t), chain.hash(c, chain.blocks[6]))
+		c.Assert(err, NotNil)
+		_, err = imported.GetHead()
+		c.Assert(err, NotNil)
+		c.Assert(imported.GetMetadata().GenesisHash, IsNil)
+		err = imported.Close()
+		c.Assert(err, IsNil)
+	}
+
+	other := newTestChain(c, 2)
+	imported := openTestService(c, c.MkDir(), &MerkleService{GenesisHash: other.hash(c, other.blocks[0])})
+	defer imported.Close()
+	_, err = imported.ImportSnapshot(bytes.NewReader(data), chain.hash(c, chain.blocks[6]))
+	c.Assert(err, NotNil)
+}
@@ -0,0 +1,431 @@
+package node
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/tokentransfer/chain/block"
+	"github.com/tokentransfer/chain/core"
+
+	libblock "github.com/tokentransfer/interfaces/block"
+	libcore "github.com/tokentransfer/interfaces/core"
+	libcrypto "github.com/tokentransfer/interfaces/crypto"
+)
+
+// VerifyReport is the result of Verify, Errors lists the inconsistencies
+// found, an empty list means the databases are consistent.
+type VerifyReport struct {
+	Blocks       uint64
+	Transactions uint64
+	States       uint64
+	Errors       []string
+}
+
+func (r *VerifyReport) addError(format string, args ...interface{}) {
+	r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+}
+
+func (r *VerifyReport) OK() bool {
+	return len(r.Errors) == 0
+}
+
+// roundTrip checks that data decodes and encodes back to the same bytes.
+func roundTrip(data []byte) error {
+	if len(data) == 0 {
+		return errors.New("no data")
+	}
+	_, msg, err := core.Unmarshal(data)
+	if err != nil {
+		return err
+	}
+	encoded, err := core.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(encoded, data) {
+		return fmt.Errorf("%s does not round trip", core.GetInfo(data))
+	}
+	return nil
+}
+
+func (service *MerkleService) checkIndex(r *VerifyReport, key string, h libcore.Hash) error {
+	data, err := service.im.GetData([]byte(key))
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(data, h) {
+		r.addError("index %s is %x, expected %s", key, data, h.String())
+	}
+	return nil
+}
+
+func (service *MerkleService) checkObject(r *VerifyReport, t *MerkleTree, name string, h libcore.Hash, raw []byte) error {
+	data, err := t.GetData(h)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		r.addError("%s %s is missing", name, h.String())
+		return nil
+	}
+	if !bytes.Equal(data, raw) {
+		r.addError("%s %s does not match its data", name, h.String())
+	}
+	err = roundTrip(data)
+	if err != nil {
+		r.addError("%s %s: %s", name, h.String(), err)
+	}
+	return nil
+}
+
+// Verify walks the canonical chain from the genesis block, it checks the
+// ParentHash links, that the blocks, transactions and states indexed are
+// stored and decode, and the block@N, transaction@hash,
+// transaction@address:sequence, state@key:sequence and state@key entries of
+// the index, and the fees of the blocks when GasSchedule or FeeMarket is set.
+// The state entries are checked against the last version written, a state
+// keeps its sequence when it changes without a transaction of its own.
+// In a data directory imported from a snapshot, the walk skips the missing
+// blocks and only the hashes of the genesis and snapshot blocks are checked.
+// An error is returned only when a database can not be read.
+func (service *MerkleService) Verify() (*VerifyReport, error) {
+	cs := service.CryptoService
+	r := &VerifyReport{}
+	latest := make(map[string]libcore.Hash)
+	keys := make([]string, 0)
+
+	var parent libcore.Hash
+	var prev *block.Block
+	last := uint64(0)
+	for i := uint64(0); ; i = service.nextIndex(i) {
+		h, err := service.getBlockHash(i)
+		if err != nil {
+			return nil, err
+		}
+		if h == nil {
+			break
+		}
+		r.Blocks++
+		last = i
+
+		data, err := service.bm.GetData(h)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) == 0 {
+			r.addError("block %d %s is missing", i, h.String())
+			parent = h
+			prev = nil
+			continue
+		}
+		err = roundTrip(data)
+		if err != nil {
+			r.addError("block %d: %s", i, err)
+			parent = h
+			prev = nil
+			continue
+		}
+		b := &block.Block{}
+		err = b.UnmarshalBinary(data)
+		if err != nil {
+			r.addError("block %d: %s", i, err)
+			parent = h
+			prev = nil
+			continue
+		}
+		bh, _, err := cs.Raw(b, libcrypto.RawBinary)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(bh, h) {
+			r.addError("block %d has hash %s, indexed as %s", i, bh.String(), h.String())
+		}
+		if b.GetIndex() != i {
+			r.addError("block %d has index %d", i, b.GetIndex())
+		}
+		if i > 0 && parent != nil && !service.isHeader(i) && !bytes.Equal(b.GetParentHash(), parent) {
+			r.addError("block %d has parent %s, expected %s", i, b.GetParentHash().String(), parent.String())
+		}
+		parent = h
+		if service.isHeader(i) {
+			prev = b
+			continue
+		}
+
+		for _, txWithData := range b.GetTransactions() {
+			r.Transactions++
+			err := service.verifyTransaction(r, txWithData)
+			if err != nil {
+				return nil, err
+			}
+			checkValidity(r, b, txWithData)
+		}
+		checkLogsBloom(r, b)
+		err = service.checkFees(r, prev, b)
+		if err != nil {
+			return nil, err
+		}
+		prev = b
+		for _, s := range b.GetStates() {
+			r.States++
+			sh, raw, err := cs.Raw(s, libcrypto.RawBinary)
+			if err != nil {
+				return nil, err
+			}
+			err = service.checkObject(r, service.sm, "state", sh, raw)
+			if err != nil {
+				return nil, err
+			}
+			key := s.GetStateKey()
+			indexKey := getNameKey("state", getIndexKey(key, s.GetIndex()))
+			_, ok := latest[indexKey]
+			if !ok {
+				keys = append(keys, indexKey)
+			}
+			latest[indexKey] = sh
+			newKey := getNameKey("state", key)
+			_, ok = latest[newKey]
+			if !ok {
+				keys = append(keys, newKey)
+			}
+			latest[newKey] = sh
+		}
+	}
+
+	for _, key := range keys {
+		err := service.checkIndex(r, key, latest[key])
+		if err != nil {
+			return nil, err
+		}
+	}
+	head, ok, err := service.getHead()
+	if err != nil {
+		return nil, err
+	}
+	if r.Blocks > 0 && (!ok || head != last) {
+		r.addError("head is %d, expected %d", head, last)
+	}
+	return r, nil
+}
+
+func (service *MerkleService) verifyTransaction(r *VerifyReport, txWithData libblock.TransactionWithData) error {
+	cs := service.CryptoService
+
+	h, raw, err := cs.Raw(txWithData, libcrypto.RawBinary)
+	if err != nil {
+		return err
+	}
+	err = service.checkObject(r, service.tm, "transaction", h, raw)
+	if err != nil {
+		return err
+	}
+	tx := txWithData.GetTransaction()
+	txHash, _, err := cs.Raw(tx, libcrypto.RawBinary)
+	if err != nil {
+		return err
+	}
+	err = service.checkIndex(r, getHashKey("transaction", txHash), h)
+	if err != nil {
+		return err
+	}
+	address, err := tx.GetAccount().GetAddress()
+	if err != nil {
+		return err
+	}
+	return service.checkIndex(r, getNameKey("transaction", getIndexKey(address, tx.GetIndex())), h)
+}
+
+type validityChecker interface {
+	CheckValidity(blockIndex uint64, timestamp int64) libblock.TransactionResult
+}
+
+// checkValidity reports a transaction of b executed successfully outside of
+// its validity window.
+func checkValidity(r *VerifyReport, b *block.Block, txWithData libblock.TransactionWithData) {
+	tx, ok := txWithData.GetTransaction().(validityChecker)
+	if !ok {
+		return
+	}
+	receipt := txWithData.GetReceipt()
+	if receipt == nil || receipt.GetTransactionResult() != block.ResultSuccess {
+		return
+	}
+	result := tx.CheckValidity(b.GetIndex(), b.Timestamp)
+	if result != block.ResultSuccess {
+		r.addError("transaction %s of block %d is outside of its validity window, result %d", txWithData.GetTransaction().GetHash().String(), b.GetIndex(), result)
+	}
+}
+
+// checkFees reports a block whose GasUsed is not the gas of its transactions
+// or whose BaseFee does not follow its parent.
+func (service *MerkleService) checkFees(r *VerifyReport, parent *block.Block, b *block.Block) error {
+	if service.GasSchedule != nil {
+		gas := int64(0)
+		for _, txWithData := range b.GetTransactions() {
+			g, err := service.GasSchedule.GetGas(txWithData.GetTransaction())
+			if err != nil {
+				return err
+			}
+			gas += g
+		}
+		if gas != b.GasUsed {
+			r.addError("block %d has gas used %d, expected %d", b.GetIndex(), b.GasUsed, gas)
+		}
+	}
+	if service.FeeMarket != nil {
+		limit := service.FeeMarket.GetGasLimit()
+		if limit > 0 && b.GasUsed > limit {
+			r.addError("block %d has gas used %d, over the limit %d", b.GetIndex(), b.GasUsed, limit)
+		}
+	}
+	if service.FeeMarket != nil && (b.GetIndex() == 0 || parent != nil) {
+		baseFee := service.FeeMarket.NextBaseFee(parent)
+		if baseFee != b.BaseFee {
+			r.addError("block %d has base fee %d, expected %d", b.GetIndex(), b.BaseFee, baseFee)
+		}
+	}
+	return nil
+}
+
+// freshService opens an empty trie over the store of another one, the
+// nodes are written to the store and the root entries are kept aside, the
+// trie replaces the other one when they are written.
+type freshService struct {
+	rootView
+}
+
+func (fs *freshService) PutData(key []byte, value []byte) error {
+	return fs.PutDatas([][]byte{key}, [][]byte{value})
+}
+
+func (fs *freshService) PutDatas(keys [][]byte, values [][]byte) error {
+	nodeKeys := make([][]byte, 0, len(keys))
+	nodeValues := make([][]byte, 0, len(values))
+	l := len(keys)
+	for i := 0; i < l; i++ {
+		node, err := isNode(fs.cs, keys[i], values[i])
+		if err != nil {
+			return err
+		}
+		if !node {
+			fs.entries[string(keys[i])] = append([]byte(nil), values[i]...)
+			continue
+		}
+		nodeKeys = append(nodeKeys, keys[i])
+		nodeValues = append(nodeValues, values[i])
+	}
+	if len(nodeKeys) == 0 {
+		return nil
+	}
+	return fs.KvService.PutDatas(nodeKeys, nodeValues)
+}
+
+// RebuildIndex rebuilds the index database from the block database, the
+// chain is followed by ParentHash from the block of hash head, or from the
+// head of the index when head is nil, down to the genesis block, the states
+// of the blocks must be in the state trie. The index is built in a new trie
+// which replaces the current one at the end, the state roots of the blocks
+// are kept and the side blocks are dropped from the index, the other
+// databases and the saved roots are unchanged. The blocks before the
+// snapshot of a data directory imported from a snapshot are missing, its
+// index can not be rebuilt.
+func (service *MerkleService) RebuildIndex(head libcore.Hash) error {
+	if service.meta.SnapshotHeight > 0 {
+		return fmt.Errorf("data directory imported from the snapshot of block %d can not rebuild its index", service.meta.SnapshotHeight)
+	}
+	err := service.Cancel()
+	if err != nil {
+		return err
+	}
+	if head == nil {
+		b, err := service.GetHead()
+		if err != nil {
+			return err
+		}
+		head, _, err = service.CryptoService.Raw(b, libcrypto.RawBinary)
+		if err != nil {
+			return err
+		}
+	}
+
+	hashes := make([]libcore.Hash, 0)
+	roots := make([]libcore.Hash, 0)
+	h := head
+	for {
+		b, err := service.GetBlockByHash(h)
+		if err != nil {
+			return fmt.Errorf("block %s: %s", h.String(), err)
+		}
+		root := b.GetStateHash()
+		canonical, err := service.getBlockHash(b.GetIndex())
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(canonical, h) {
+			data, err := service.im.GetData([]byte(getRootKey(b.GetIndex())))
+			if err != nil {
+				return err
+			}
+			if len(data) > 0 {
+				root = data
+			}
+		}
+		hashes = append(hashes, h)
+		roots = append(roots, root)
+		if b.GetIndex() == 0 {
+			break
+		}
+		h = b.GetParentHash()
+	}
+
+	rs := service.roots["index"]
+	fs := &freshService{rootView{KvService: rs.KvService, cs: service.CryptoService, entries: make(map[string][]byte)}}
+	old := service.im
+	service.im = NewMerkleTree(service.CryptoService, fs)
+	service.im.journal = old.journal
+	err = func() error {
+		for i := len(hashes) - 1; i >= 0; i-- {
+			b, err := service.GetBlockByHash(hashes[i])
+			if err != nil {
+				return err
+			}
+			service.height = b.GetIndex()
+			err = service.indexBlock(b, hashes[i], roots[i])
+			if err != nil {
+				return err
+			}
+			if i%migrateBatch == 0 {
+				err = service.im.Commit()
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}()
+	service.im = old
+	if err != nil {
+		service.im.reload()
+		return err
+	}
+
+	keys := make([][]byte, 0, len(fs.entries))
+	values := make([][]byte, 0, len(fs.entries))
+	for key, value := range fs.entries {
+		keys = append(keys, []byte(key))
+		values = append(values, value)
+	}
+	err = rs.KvService.PutDatas(keys, values)
+	if err != nil {
+		return err
+	}
+	service.purgeCaches()
+	service.im.reload()
+	index, _, err := service.getHead()
+	if err != nil {
+		return err
+	}
+	service.height = index
+	return nil
+}
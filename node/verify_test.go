@@ -0,0 +1,80 @@
+package node
+
+import (
+	"testing"
+
+	. "github.com/tokentransfer/check"
+)
+
+type VerifySuite struct{}
+
+func Test_Verify(t *testing.T) {
+	s := Suite(&VerifySuite{})
+	TestingRun(t, s)
+}
+
+func (suite *VerifySuite) TestRebuildIndex(c *C) {
+	chain := newTestChain(c, 3)
+	chain.grow(c, 8)
+	dir := c.MkDir()
+	service := openTestService(c, dir, &MerkleService{})
+	chain.putBlocks(c, service, 0)
+	branch := chain.fork(c, 5)
+	branch.grow(c, 2)
+	err := service.PutBlock(branch.blocks[6])
+	c.Assert(err, IsNil)
+	err = service.Commit()
+	c.Assert(err, IsNil)
+
+	saved := make(map[string][]byte)
+	err = service.rootdb.ListData(func(key []byte, value []byte) error {
+		saved[string(key)] = append([]byte(nil), value...)
+		return nil
+	})
+	c.Assert(err, IsNil)
+
+	// an index entry lost, the saved roots are kept
+	address, err := chain.accounts[1].GetAddress()
+	c.Assert(err, IsNil)
+	err = service.removeIndex(getNameKey("state", address))
+	c.Assert(err, IsNil)
+	err = service.im.Commit()
+	c.Assert(err, IsNil)
+	report, err := service.Verify()
+	c.Assert(err, IsNil)
+	c.Assert(report.Errors, NotNil)
+
+	err = service.RebuildIndex(nil)
+	c.Assert(err, IsNil)
+	checkChain(c, service, chain, 8)
+	blocks, err := service.GetBlocksByIndex(6)
+	c.Assert(err, IsNil)
+	c.Assert(len(blocks), Equals, 1)
+	err = service.rootdb.ListData(func(key []byte, value []byte) error {
+		c.Assert(value, DeepEquals, saved[string(key)])
+		return nil
+	})
+	c.Assert(err, IsNil)
+
+	err = service.Close()
+	c.Assert(err, IsNil)
+	service = openTestService(c, dir, &MerkleService{})
+	defer service.Close()
+	checkChain(c, service, chain, 8)
+
+	// a branch whose states are not in the state trie, the index is kept
+	err = service.RebuildIndex(chain.hash(c, branch.blocks[6]))
+	c.Assert(err, NotNil)
+	checkChain(c, service, chain, 8)
+
+	err = service.RebuildIndex(chain.hash(c, chain.blocks[6]))
+	c.Assert(err, IsNil)
+	b, err := service.GetHead()
+	c.Assert(err, IsNil)
+	c.Assert(b.GetIndex(), Equals, uint64(6))
+	for i := uint64(0); i <= 6; i++ {
+		root, err := service.GetStateRootAt(i)
+		c.Assert(err, IsNil)
+		c.Assert([]byte(root), DeepEquals, []byte(chain.blocks[i].StateHash))
+	}
+}
@@ -0,0 +1,243 @@
+package p2p
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/tokentransfer/chain/core"
+	"github.com/tokentransfer/chain/core/pb"
+	"github.com/tokentransfer/chain/store"
+
+	libcore "github.com/tokentransfer/interfaces/core"
+)
+
+// ProtocolVersion is the version announced in the handshake, the peers of
+// another version are refused.
+const ProtocolVersion = uint32(1)
+
+// MaxHelloSize is the length of the largest hello read in the handshake.
+const MaxHelloSize = 4 << 10
+
+// Peer is a connection to another node, its messages are records holding
+// core data, see core.WriteRecord.
+type Peer struct {
+	NodeID        string
+	Address       string
+	ListenAddress string
+	Outbound      bool
+
+	// IP is the host of Address, see Server.BanIP.
+	IP string
+
+	conn   net.Conn
+	server *Server
+	out    chan []byte
+	known  *store.Cache
+
+	locker *sync.Mutex
+	head   uint64
+	score  int
+
+	closed    chan struct{}
+	closeOnce *sync.Once
+}
+
+func getIP(address string) string {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+	return host
+}
+
+func newPeer(s *Server, conn net.Conn, outbound bool) *Peer {
+	address := conn.RemoteAddr().String()
+	return &Peer{
+		Address:  address,
+		Outbound: outbound,
+		IP:       getIP(address),
+
+		conn:   conn,
+		server: s,
+		out:    make(chan []byte, s.QueueSize),
+		known:  store.NewCache(s.KnownSize),
+
+		locker: &sync.Mutex{},
+
+		closed:    make(chan struct{}),
+		closeOnce: &sync.Once{},
+	}
+}
+
+func (p *Peer) String() string {
+	return fmt.Sprintf("%s@%s", p.NodeID, p.Address)
+}
+
+// GetHead returns the highest block index announced by the peer.
+func (p *Peer) GetHead() uint64 {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	return p.head
+}
+
+func (p *Peer) setHead(index uint64) {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	if index > p.head {
+		p.head = index
+	}
+}
+
+func (p *Peer) GetScore() int {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	return p.score
+}
+
+func (p *Peer) addScore(penalty int) int {
+	p.locker.Lock()
+	defer p.locker.Unlock()
+
+	p.score += penalty
+	return p.score
+}
+
+func (p *Peer) hello() ([]byte, error) {
+	s := p.server
+	head := uint64(0)
+	if s.Head != nil {
+		head = s.Head()
+	}
+	return core.Marshal(&pb.Hello{
+		Version:       ProtocolVersion,
+		ChainID:       s.ChainID,
+		GenesisHash:   []byte(s.GenesisHash),
+		HeadIndex:     head,
+		NodeID:        []byte(s.NodeID),
+		ListenAddress: s.listenAddress(),
+	})
+}
+
+// handshake exchanges the hellos and checks the hello of the peer.
+func (p *Peer) handshake() error {
+	s := p.server
+
+	data, err := p.hello()
+	if err != nil {
+		return err
+	}
+	p.conn.SetDeadline(time.Now().Add(s.HandshakeTimeout))
+	defer p.conn.SetDeadline(time.Time{})
+
+	err = core.WriteRecord(p.conn, data)
+	if err != nil {
+		return err
+	}
+	data, err = core.ReadLimitedRecord(p.conn, MaxHelloSize)
+	if err != nil {
+		return err
+	}
+	meta, msg, err := core.Unmarshal(data)
+	if err != nil {
+		return err
+	}
+	if meta != core.CORE_HELLO {
+		return errors.New("error hello")
+	}
+	hello := msg.(*pb.Hello)
+	if hello.Version != ProtocolVersion {
+		return fmt.Errorf("error protocol version %d", hello.Version)
+	}
+	if hello.ChainID != s.ChainID {
+		return fmt.Errorf("error chain id %d, expected %d", hello.ChainID, s.ChainID)
+	}
+	if len(s.GenesisHash) > 0 && len(hello.GenesisHash) > 0 && !bytes.Equal(hello.GenesisHash, s.GenesisHash) {
+		return fmt.Errorf("error genesis %s", libcore.Hash(hello.GenesisHash).String())
+	}
+	if len(hello.NodeID) == 0 {
+		return errors.New("error node id")
+	}
+	if bytes.Equal(hello.NodeID, s.NodeID) {
+		return errors.New("connected to self")
+	}
+	p.NodeID = hex.EncodeToString(hello.NodeID)
+	p.ListenAddress = hello.ListenAddress
+	p.head = hello.HeadIndex
+	return nil
+}
+
+// Send queues core data for the peer, it fails when the queue is full or
+// when data is longer than MaxMessageSize.
+func (p *Peer) Send(data []byte) error {
+	if len(data) > p.server.MaxMessageSize {
+		return fmt.Errorf("error message size %d", len(data))
+	}
+	select {
+	case <-p.closed:
+		return errors.New("peer is closed")
+	default:
+	}
+	select {
+	case p.out <- data:
+		return nil
+	default:
+		return errors.New("peer queue is full")
+	}
+}
+
+func (p *Peer) isKnown(key string) bool {
+	_, ok := p.known.Get(key)
+	return ok
+}
+
+func (p *Peer) markKnown(key string) {
+	p.known.Add(key, true)
+}
+
+func (p *Peer) writeLoop() {
+	s := p.server
+	for {
+		select {
+		case <-p.closed:
+			return
+		case data := <-p.out:
+			p.conn.SetWriteDeadline(time.Now().Add(s.WriteTimeout))
+			err := core.WriteRecord(p.conn, data)
+			if err != nil {
+				p.Close()
+				return
+			}
+		}
+	}
+}
+
+func (p *Peer) readLoop() {
+	s := p.server
+	defer s.removePeer(p)
+	for {
+		data, err := core.ReadLimitedRecord(p.conn, s.MaxMessageSize)
+		if err != nil {
+			p.Close()
+			return
+		}
+		err = s.handle(p, data)
+		if err != nil {
+			s.Penalize(p, getPenalty(err), err)
+		}
+	}
+}
+
+func (p *Peer) Close() {
+	p.closeOnce.Do(func() {
+		close(p.closed)
+		p.conn.Close()
+	})
+}
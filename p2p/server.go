@@ -0,0 +1,543 @@
+package p2p
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tokentransfer/chain/block"
+	"github.com/tokentransfer/chain/core"
+	"github.com/tokentransfer/chain/crypto"
+	"github.com/tokentransfer/chain/store"
+
+	libblock "github.com/tokentransfer/interfaces/block"
+	libcore "github.com/tokentransfer/interfaces/core"
+	libcrypto "github.com/tokentransfer/interfaces/crypto"
+)
+
+// The penalties added to the score of a peer, a peer sending data which does
+// not decode is banned at once, a peer sending objects refused by the
+// handlers after BanScore/PenaltyInvalid of them.
+const (
+	PenaltyMalformed = 100
+	PenaltyInvalid   = 25
+)
+
+type malformedError struct {
+	err error
+}
+
+func (e *malformedError) Error() string {
+	return e.err.Error()
+}
+
+func getPenalty(err error) int {
+	switch err.(type) {
+	case *malformedError:
+		return PenaltyMalformed
+	case *timeoutError:
+		return PenaltyTimeout
+	case *sendError, *forkError:
+		return 0
+	}
+	return PenaltyInvalid
+}
+
+// Handler handles a message of a peer, the peer is penalized when it returns
+// an error.
+type Handler func(p *Peer, data []byte) error
+
+// Server connects the node to its peers over TCP and gossips the new
+// transactions and blocks. The messages are core data, a transaction or a
+// block is relayed to the other peers once its handler accepted it.
+type Server struct {
+	// ListenAddress is the address of the listener, the server only dials
+	// when it is empty. Peers are the static and bootstrap peers, they are
+	// dialed again every RedialInterval while not connected.
+	ListenAddress  string
+	Peers          []string
+	MaxPeers       int
+	RedialInterval time.Duration
+
+	// ChainID, GenesisHash and Head are announced in the handshake, the
+	// peers of another chain are refused.
+	ChainID     uint32
+	GenesisHash libcore.Hash
+	Head        func() uint64
+
+	// NodeID identifies the node, a random id is used when it is empty.
+	NodeID libcore.Bytes
+
+	// OnTransaction and OnBlock check and keep the transactions and the
+	// blocks received, the peer is penalized when they return an error.
+	OnTransaction func(p *Peer, tx libblock.Transaction) error
+	OnBlock       func(p *Peer, b libblock.Block) error
+
+	// A peer reaching BanScore is disconnected and refused for BanDuration.
+	// BanIP also refuses the inbound connections of its IP before the
+	// handshake, it is off by default as the nodes behind a NAT share an IP.
+	BanScore    int
+	BanDuration time.Duration
+	BanIP       bool
+
+	// MaxMessageSize is the length of the largest message read from or sent
+	// to a peer, the hello of the handshake is limited to MaxHelloSize.
+	MaxMessageSize int
+
+	HandshakeTimeout time.Duration
+	WriteTimeout     time.Duration
+	QueueSize        int
+	KnownSize        int
+
+	CryptoService *crypto.CryptoService
+
+	listener net.Listener
+	handlers map[byte]Handler
+	seen     *store.Cache
+
+	locker *sync.Mutex
+	peers  map[string]*Peer
+	bans   map[string]time.Time
+
+	closed chan struct{}
+	wg     *sync.WaitGroup
+}
+
+func (s *Server) Init(c libcore.Config) error {
+	if s.CryptoService == nil {
+		return errors.New("no crypto service")
+	}
+	if len(s.NodeID) == 0 {
+		id := make([]byte, 16)
+		_, err := rand.Read(id)
+		if err != nil {
+			return err
+		}
+		s.NodeID = libcore.Bytes(id)
+	}
+	if s.MaxPeers <= 0 {
+		s.MaxPeers = 25
+	}
+	if s.RedialInterval <= 0 {
+		s.RedialInterval = 5 * time.Second
+	}
+	if s.BanScore <= 0 {
+		s.BanScore = 100
+	}
+	if s.BanDuration <= 0 {
+		s.BanDuration = time.Hour
+	}
+	if s.MaxMessageSize <= 0 {
+		s.MaxMessageSize = 16 << 20
+	}
+	if s.HandshakeTimeout <= 0 {
+		s.HandshakeTimeout = 5 * time.Second
+	}
+	if s.WriteTimeout <= 0 {
+		s.WriteTimeout = 10 * time.Second
+	}
+	if s.QueueSize <= 0 {
+		s.QueueSize = 256
+	}
+	if s.KnownSize <= 0 {
+		s.KnownSize = 4096
+	}
+
+	s.handlers = make(map[byte]Handler)
+	s.seen = store.NewCache(s.KnownSize)
+	s.locker = &sync.Mutex{}
+	s.peers = make(map[string]*Peer)
+	s.bans = make(map[string]time.Time)
+	s.closed = make(chan struct{})
+	s.wg = &sync.WaitGroup{}
+	return nil
+}
+
+// Handle sets the handler of the messages of tag, it is called before Start.
+func (s *Server) Handle(tag byte, h Handler) {
+	s.handlers[tag] = h
+}
+
+func (s *Server) Start() error {
+	if len(s.ListenAddress) > 0 {
+		l, err := net.Listen("tcp", s.ListenAddress)
+		if err != nil {
+			return err
+		}
+		s.listener = l
+		s.wg.Add(1)
+		go s.acceptLoop()
+	}
+	s.wg.Add(1)
+	go s.dialLoop()
+	return nil
+}
+
+func (s *Server) Close() error {
+	select {
+	case <-s.closed:
+		return nil
+	default:
+	}
+	close(s.closed)
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	for _, p := range s.GetPeers() {
+		p.Close()
+	}
+	s.wg.Wait()
+	return nil
+}
+
+// Addr returns the address of the listener.
+func (s *Server) Addr() string {
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+func (s *Server) listenAddress() string {
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+// GetPeers returns the connected peers ordered by node id.
+func (s *Server) GetPeers() []*Peer {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	list := make([]*Peer, 0, len(s.peers))
+	for _, p := range s.peers {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].NodeID < list[j].NodeID
+	})
+	return list
+}
+
+func (s *Server) isConnected(address string) bool {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	for _, p := range s.peers {
+		if p.Address == address || p.ListenAddress == address {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBanned returns true when the node of id, the address or the IP is
+// banned.
+func (s *Server) IsBanned(id string) bool {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	return s.isBanned(id)
+}
+
+func (s *Server) isBanned(id string) bool {
+	until, ok := s.bans[id]
+	if !ok {
+		return false
+	}
+	if time.Now().After(until) {
+		delete(s.bans, id)
+		return false
+	}
+	return true
+}
+
+// Ban disconnects the peer and refuses its node and its listen address for
+// BanDuration, and the inbound connections of its IP with BanIP.
+func (s *Server) Ban(p *Peer) {
+	s.locker.Lock()
+	until := time.Now().Add(s.BanDuration)
+	s.bans[p.NodeID] = until
+	if len(p.ListenAddress) > 0 {
+		s.bans[p.ListenAddress] = until
+	}
+	if s.BanIP && len(p.IP) > 0 {
+		s.bans[p.IP] = until
+	}
+	current, ok := s.peers[p.NodeID]
+	if ok && current == p {
+		delete(s.peers, p.NodeID)
+	}
+	s.locker.Unlock()
+
+	p.Close()
+}
+
+// Penalize adds penalty to the score of p and bans it when it reaches
+// BanScore.
+func (s *Server) Penalize(p *Peer, penalty int, reason error) {
+	if penalty <= 0 {
+		return
+	}
+	score := p.addScore(penalty)
+	log.Printf("peer %s: %s, score %d", p.String(), reason, score)
+	if score >= s.BanScore {
+		s.Ban(p)
+	}
+}
+
+func (s *Server) acceptLoop() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			select {
+			case <-s.closed:
+				return
+			default:
+			}
+			log.Println(err)
+			continue
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.setupPeer(conn, false)
+		}()
+	}
+}
+
+func (s *Server) dialLoop() {
+	defer s.wg.Done()
+	t := time.NewTicker(s.RedialInterval)
+	defer t.Stop()
+	for {
+		s.dialPeers()
+		select {
+		case <-s.closed:
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (s *Server) dialPeers() {
+	for _, address := range s.Peers {
+		if s.isConnected(address) || s.IsBanned(address) {
+			continue
+		}
+		err := s.Connect(address)
+		if err != nil {
+			log.Printf("dial %s: %s", address, err)
+		}
+	}
+}
+
+// Connect dials address and adds the peer once the handshake is done.
+func (s *Server) Connect(address string) error {
+	conn, err := net.DialTimeout("tcp", address, s.HandshakeTimeout)
+	if err != nil {
+		return err
+	}
+	s.wg.Add(1)
+	defer s.wg.Done()
+	return s.setupPeer(conn, true)
+}
+
+// setupPeer refuses an inbound connection from a banned IP before the
+// handshake, see BanIP.
+func (s *Server) setupPeer(conn net.Conn, outbound bool) error {
+	p := newPeer(s, conn, outbound)
+	if !outbound && s.IsBanned(p.IP) {
+		conn.Close()
+		return fmt.Errorf("peer %s is banned", p.IP)
+	}
+	err := p.handshake()
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	err = s.addPeer(p)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	s.wg.Add(2)
+	go func() {
+		defer s.wg.Done()
+		p.writeLoop()
+	}()
+	go func() {
+		defer s.wg.Done()
+		p.readLoop()
+	}()
+	return nil
+}
+
+// keepNew decides which of two connections between the same nodes is kept,
+// both nodes keep the connection dialed by the node of the lower id.
+func (s *Server) keepNew(p *Peer) bool {
+	self := hex.EncodeToString(s.NodeID)
+	if p.Outbound {
+		return self < p.NodeID
+	}
+	return p.NodeID < self
+}
+
+func (s *Server) addPeer(p *Peer) error {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	select {
+	case <-s.closed:
+		return errors.New("server is closed")
+	default:
+	}
+	if s.isBanned(p.NodeID) {
+		return fmt.Errorf("peer %s is banned", p.NodeID)
+	}
+	old, ok := s.peers[p.NodeID]
+	if ok {
+		if !s.keepNew(p) {
+			return fmt.Errorf("peer %s is already connected", p.NodeID)
+		}
+		delete(s.peers, p.NodeID)
+		old.Close()
+	}
+	if len(s.peers) >= s.MaxPeers {
+		return errors.New("too many peers")
+	}
+	s.peers[p.NodeID] = p
+	return nil
+}
+
+func (s *Server) removePeer(p *Peer) {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	current, ok := s.peers[p.NodeID]
+	if ok && current == p {
+		delete(s.peers, p.NodeID)
+	}
+}
+
+func (s *Server) hashOf(h libcrypto.Hashable) (string, error) {
+	hash, _, err := s.CryptoService.Raw(h, libcrypto.RawBinary)
+	if err != nil {
+		return "", err
+	}
+	return hash.String(), nil
+}
+
+func (s *Server) handle(p *Peer, data []byte) error {
+	tag, ok := core.GetTag(data)
+	if !ok {
+		return &malformedError{errors.New("error message")}
+	}
+	if tag == core.CORE_BLOCK {
+		b := &block.Block{}
+		err := b.UnmarshalBinary(data)
+		if err != nil {
+			return &malformedError{err}
+		}
+		return s.handleBlock(p, b, data)
+	}
+	h, ok := s.handlers[tag]
+	if ok {
+		return h(p, data)
+	}
+	tx, err := block.ReadTransaction(data)
+	if err != nil {
+		return &malformedError{err}
+	}
+	return s.handleTransaction(p, tx, data)
+}
+
+func (s *Server) handleBlock(p *Peer, b libblock.Block, data []byte) error {
+	key, err := s.hashOf(b)
+	if err != nil {
+		return err
+	}
+	p.markKnown(key)
+	_, ok := s.seen.Get(key)
+	if ok {
+		p.setHead(b.GetIndex())
+		return nil
+	}
+	if s.OnBlock != nil {
+		err = s.OnBlock(p, b)
+		if err != nil {
+			return err
+		}
+	}
+	p.setHead(b.GetIndex())
+	s.seen.Add(key, true)
+	s.relay(p, key, data)
+	return nil
+}
+
+func (s *Server) handleTransaction(p *Peer, tx libblock.Transaction, data []byte) error {
+	key, err := s.hashOf(tx)
+	if err != nil {
+		return err
+	}
+	p.markKnown(key)
+	_, ok := s.seen.Get(key)
+	if ok {
+		return nil
+	}
+	if s.OnTransaction != nil {
+		err = s.OnTransaction(p, tx)
+		if err != nil {
+			return err
+		}
+	}
+	s.seen.Add(key, true)
+	s.relay(p, key, data)
+	return nil
+}
+
+// relay sends data to the peers other than from which do not know it.
+func (s *Server) relay(from *Peer, key string, data []byte) {
+	for _, p := range s.GetPeers() {
+		if p == from || p.isKnown(key) {
+			continue
+		}
+		p.markKnown(key)
+		err := p.Send(data)
+		if err != nil {
+			log.Printf("peer %s: %s", p.String(), err)
+		}
+	}
+}
+
+func (s *Server) broadcast(h libcrypto.Hashable) error {
+	key, err := s.hashOf(h)
+	if err != nil {
+		return err
+	}
+	data, err := h.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	s.seen.Add(key, true)
+	s.relay(nil, key, data)
+	return nil
+}
+
+// BroadcastTransaction sends a new transaction to the peers.
+func (s *Server) BroadcastTransaction(tx libblock.Transaction) error {
+	return s.broadcast(tx)
+}
+
+// BroadcastBlock sends a new block to the peers.
+func (s *Server) BroadcastBlock(b libblock.Block) error {
+	return s.broadcast(b)
+}
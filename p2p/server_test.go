@@ -0,0 +1,255 @@
+package p2p
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/tokentransfer/chain/account"
+	"github.com/tokentransfer/chain/block"
+	"github.com/tokentransfer/chain/core"
+	"github.com/tokentransfer/chain/crypto"
+
+	. "github.com/tokentransfer/check"
+	libblock "github.com/tokentransfer/interfaces/block"
+)
+
+type ServerSuite struct{}
+
+func Test_Server(t *testing.T) {
+	s := Suite(&ServerSuite{})
+	TestingRun(t, s)
+}
+
+type testNode struct {
+	*Server
+
+	txs    chan libblock.Transaction
+	blocks chan libblock.Block
+}
+
+func newTestNode(c *C, chainID uint32, peers ...string) *testNode {
+	n := &testNode{
+		txs:    make(chan libblock.Transaction, 10),
+		blocks: make(chan libblock.Block, 10),
+	}
+	n.Server = &Server{
+		ListenAddress:  "127.0.0.1:0",
+		Peers:          peers,
+		RedialInterval: 50 * time.Millisecond,
+		ChainID:        chainID,
+		CryptoService:  &crypto.CryptoService{},
+		OnTransaction: func(p *Peer, tx libblock.Transaction) error {
+			n.txs <- tx
+			return nil
+		},
+		OnBlock: func(p *Peer, b libblock.Block) error {
+			n.blocks <- b
+			return nil
+		},
+	}
+	err := n.Init(nil)
+	c.Assert(err, IsNil)
+	err = n.Start()
+	c.Assert(err, IsNil)
+	return n
+}
+
+func waitFor(c *C, f func() bool) {
+	for i := 0; i < 200; i++ {
+		if f() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	c.Fatal("timeout")
+}
+
+func newTestTransaction(c *C, sequence uint64) *block.Transaction {
+	a := account.NewAddress()
+	err := a.UnmarshalText([]byte("0x42f32B004Da1093d51AE40a58F38E33BA4f46397"))
+	c.Assert(err, IsNil)
+	return &block.Transaction{Account: a, Destination: a, Sequence: sequence}
+}
+
+func (suite *ServerSuite) TestGossip(c *C) {
+	b := newTestNode(c, 1)
+	defer b.Close()
+	a := newTestNode(c, 1, b.Addr())
+	defer a.Close()
+	d := newTestNode(c, 1, b.Addr())
+	defer d.Close()
+
+	waitFor(c, func() bool {
+		return len(b.GetPeers()) == 2 && len(a.GetPeers()) == 1 && len(d.GetPeers()) == 1
+	})
+
+	err := a.BroadcastTransaction(newTestTransaction(c, 7))
+	c.Assert(err, IsNil)
+	for _, n := range []*testNode{b, d} {
+		select {
+		case tx := <-n.txs:
+			c.Assert(tx.GetSequence(), Equals, uint64(7))
+		case <-time.After(2 * time.Second):
+			c.Fatal("transaction not received")
+		}
+	}
+
+	err = d.BroadcastBlock(&block.Block{BlockIndex: 3})
+	c.Assert(err, IsNil)
+	for _, n := range []*testNode{b, a} {
+		select {
+		case blk := <-n.blocks:
+			c.Assert(blk.GetIndex(), Equals, uint64(3))
+		case <-time.After(2 * time.Second):
+			c.Fatal("block not received")
+		}
+	}
+	c.Assert(a.GetPeers()[0].GetHead(), Equals, uint64(3))
+
+	select {
+	case <-a.txs:
+		c.Fatal("transaction relayed back")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func (suite *ServerSuite) TestHandshake(c *C) {
+	b := newTestNode(c, 1)
+	defer b.Close()
+	other := newTestNode(c, 2)
+	defer other.Close()
+
+	err := other.Connect(b.Addr())
+	c.Assert(err, NotNil)
+	c.Assert(len(b.GetPeers()), Equals, 0)
+
+	err = b.Connect(b.Addr())
+	c.Assert(err, NotNil)
+}
+
+func (suite *ServerSuite) TestBan(c *C) {
+	b := newTestNode(c, 1)
+	defer b.Close()
+	a := newTestNode(c, 1)
+	defer a.Close()
+
+	err := a.Connect(b.Addr())
+	c.Assert(err, IsNil)
+	waitFor(c, func() bool {
+		return len(b.GetPeers()) == 1
+	})
+
+	err = a.GetPeers()[0].Send([]byte{0xfe, 1, 2})
+	c.Assert(err, IsNil)
+	id := hex.EncodeToString(a.NodeID)
+	waitFor(c, func() bool {
+		return b.IsBanned(id) && len(b.GetPeers()) == 0
+	})
+
+	// another node of the same IP is still accepted
+	c.Assert(b.IsBanned("127.0.0.1"), Equals, false)
+	d := newTestNode(c, 1)
+	defer d.Close()
+	err = d.Connect(b.Addr())
+	c.Assert(err, IsNil)
+	// the banned node is refused once it sent its hello
+	err = a.Connect(b.Addr())
+	c.Assert(err, IsNil)
+	waitFor(c, func() bool {
+		return len(a.GetPeers()) == 0 && len(b.GetPeers()) == 1
+	})
+	c.Assert(b.GetPeers()[0].NodeID, Equals, hex.EncodeToString(d.NodeID))
+}
+
+func (suite *ServerSuite) TestBanIP(c *C) {
+	b := newTestNode(c, 1)
+	defer b.Close()
+	b.BanIP = true
+	a := newTestNode(c, 1)
+	defer a.Close()
+
+	err := a.Connect(b.Addr())
+	c.Assert(err, IsNil)
+	waitFor(c, func() bool {
+		return len(b.GetPeers()) == 1
+	})
+	b.Ban(b.GetPeers()[0])
+	c.Assert(b.IsBanned("127.0.0.1"), Equals, true)
+
+	// another node of the same IP is refused before the handshake
+	d := newTestNode(c, 1)
+	defer d.Close()
+	err = d.Connect(b.Addr())
+	c.Assert(err, NotNil)
+	c.Assert(len(b.GetPeers()), Equals, 0)
+}
+
+func (suite *ServerSuite) TestMessageSize(c *C) {
+	b := newTestNode(c, 1)
+	defer b.Close()
+
+	// a hello longer than MaxHelloSize is refused before it is read
+	conn, err := net.Dial("tcp", b.Addr())
+	c.Assert(err, IsNil)
+	defer conn.Close()
+	_, err = core.ReadRecord(conn)
+	c.Assert(err, IsNil)
+	err = binary.Write(conn, binary.LittleEndian, uint32(MaxHelloSize+1))
+	c.Assert(err, IsNil)
+	_, err = conn.Read(make([]byte, 1))
+	c.Assert(err, NotNil)
+
+	// and so is a message longer than MaxMessageSize
+	b.MaxMessageSize = 1 << 10
+	a := newTestNode(c, 1)
+	defer a.Close()
+	err = a.Connect(b.Addr())
+	c.Assert(err, IsNil)
+	waitFor(c, func() bool {
+		return len(b.GetPeers()) == 1
+	})
+	err = a.GetPeers()[0].Send(make([]byte, 2<<10))
+	c.Assert(err, IsNil)
+	waitFor(c, func() bool {
+		return len(b.GetPeers()) == 0
+	})
+	a.MaxMessageSize = 1 << 10
+	err = a.Connect(b.Addr())
+	c.Assert(err, IsNil)
+	waitFor(c, func() bool {
+		return len(a.GetPeers()) == 1
+	})
+	err = a.GetPeers()[0].Send(make([]byte, 2<<10))
+	c.Assert(err, NotNil)
+}
+
+func (suite *ServerSuite) TestRefusedBlock(c *C) {
+	b := newTestNode(c, 1)
+	defer b.Close()
+	b.OnBlock = func(p *Peer, blk libblock.Block) error {
+		if blk.GetIndex() > 5 {
+			return errors.New("error block")
+		}
+		return nil
+	}
+	a := newTestNode(c, 1)
+	defer a.Close()
+	err := a.Connect(b.Addr())
+	c.Assert(err, IsNil)
+	waitFor(c, func() bool {
+		return len(b.GetPeers()) == 1
+	})
+
+	err = a.BroadcastBlock(&block.Block{BlockIndex: 9})
+	c.Assert(err, IsNil)
+	err = a.BroadcastBlock(&block.Block{BlockIndex: 4})
+	c.Assert(err, IsNil)
+	waitFor(c, func() bool {
+		return b.GetPeers()[0].GetHead() == 4
+	})
+	c.Assert(b.GetPeers()[0].GetScore(), Equals, PenaltyInvalid)
+}
@@ -0,0 +1,672 @@
+package p2p
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tokentransfer/chain/block"
+	"github.com/tokentransfer/chain/core"
+	"github.com/tokentransfer/chain/core/pb"
+	"github.com/tokentransfer/chain/node"
+	"google.golang.org/protobuf/proto"
+
+	libblock "github.com/tokentransfer/interfaces/block"
+	libcore "github.com/tokentransfer/interfaces/core"
+	libcrypto "github.com/tokentransfer/interfaces/crypto"
+)
+
+// PenaltyTimeout is added to the score of a peer which does not answer a
+// request in time.
+const PenaltyTimeout = 10
+
+// Chain is the chain synchronized, node.MerkleService implements it. GetHead
+// returns node.ErrNoBlock when the chain is empty, PutBlock keeps aside a
+// block which extends a known block other than the head and ChooseBranch
+// makes the branch ending with the block of hash h canonical when the fork
+// choice of the chain prefers it.
+type Chain interface {
+	GetHead() (libblock.Block, error)
+	GetBlockByIndex(index uint64) (libblock.Block, error)
+	PutBlock(b libblock.Block) error
+	ChooseBranch(h libcore.Hash) (bool, error)
+	Commit() error
+	Cancel() error
+}
+
+// Engine decides which blocks can be put in the chain, consensus.PoA
+// implements it.
+type Engine interface {
+	VerifyBlock(b libblock.Block) error
+	Apply(b libblock.Block) error
+}
+
+// SyncManager catches up with the peers ahead of the chain. The headers of
+// the missing blocks are requested by index from the peer with the highest
+// head and checked to follow each other by ParentHash, then the blocks are
+// downloaded in batches from all the peers which have them and put in order
+// in the chain. When the first header does not follow the head, the peer is
+// on another branch: the headers are walked back to the last common block,
+// the blocks of the branch are put up to the head of the peer and the chain
+// is reorganized to it if the fork choice of the chain prefers it. It also
+// serves the requests of the other nodes.
+type SyncManager struct {
+	Server *Server
+	Chain  Chain
+
+	// Engine, when set, verifies each block before it is put, the peer which
+	// sent the headers of a rejected block is penalized, and applies each
+	// block once it is committed in the canonical chain.
+	Engine Engine
+
+	// HeaderBatch headers are requested at once, in batches of BlockBatch
+	// blocks downloaded by Parallel requests at most.
+	HeaderBatch int
+	BlockBatch  int
+	Parallel    int
+
+	// Timeout is the time a peer has to answer a request, Interval the time
+	// between two synchronizations.
+	Timeout  time.Duration
+	Interval time.Duration
+
+	locker    *sync.Mutex
+	requestID uint64
+	pending   map[uint64]*request
+	syncing   *sync.Mutex
+
+	closed chan struct{}
+	wg     *sync.WaitGroup
+}
+
+type request struct {
+	peer     *Peer
+	response chan proto.Message
+}
+
+type header struct {
+	hash       libcore.Hash
+	parentHash libcore.Hash
+}
+
+// Init registers the handlers of the synchronization messages and announces
+// the head of the chain when the server has no Head, it is called before the
+// Start of the server.
+func (m *SyncManager) Init(c libcore.Config) error {
+	if m.Server == nil || m.Chain == nil {
+		return errors.New("no server or chain")
+	}
+	if m.HeaderBatch <= 0 {
+		m.HeaderBatch = 512
+	}
+	if m.BlockBatch <= 0 {
+		m.BlockBatch = 32
+	}
+	if m.Parallel <= 0 {
+		m.Parallel = 4
+	}
+	if m.Timeout <= 0 {
+		m.Timeout = 10 * time.Second
+	}
+	if m.Interval <= 0 {
+		m.Interval = 5 * time.Second
+	}
+	m.locker = &sync.Mutex{}
+	m.pending = make(map[uint64]*request)
+	m.syncing = &sync.Mutex{}
+	m.closed = make(chan struct{})
+	m.wg = &sync.WaitGroup{}
+
+	s := m.Server
+	if s.Head == nil {
+		s.Head = func() uint64 {
+			head, _, _, _ := m.getHead()
+			return head
+		}
+	}
+	s.Handle(core.CORE_GET_HEADERS, m.handleGetHeaders)
+	s.Handle(core.CORE_GET_BLOCKS, m.handleGetBlocks)
+	s.Handle(core.CORE_HEADERS, m.handleResponse)
+	s.Handle(core.CORE_BLOCKS, m.handleResponse)
+	return nil
+}
+
+// Start synchronizes the chain every Interval.
+func (m *SyncManager) Start() error {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		t := time.NewTicker(m.Interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-m.closed:
+				return
+			case <-t.C:
+			}
+			err := m.Sync()
+			if err != nil {
+				log.Println("sync:", err)
+			}
+		}
+	}()
+	return nil
+}
+
+func (m *SyncManager) Close() error {
+	select {
+	case <-m.closed:
+		return nil
+	default:
+	}
+	close(m.closed)
+	m.wg.Wait()
+	return nil
+}
+
+func (m *SyncManager) getHead() (uint64, libcore.Hash, bool, error) {
+	b, err := m.Chain.GetHead()
+	if err == node.ErrNoBlock {
+		return 0, nil, false, nil
+	}
+	if err != nil {
+		return 0, nil, false, err
+	}
+	h, _, err := m.Server.CryptoService.Raw(b, libcrypto.RawBinary)
+	if err != nil {
+		return 0, nil, false, err
+	}
+	return b.GetIndex(), h, true, nil
+}
+
+func (m *SyncManager) bestPeer(from uint64) *Peer {
+	var best *Peer
+	for _, p := range m.Server.GetPeers() {
+		if p.GetHead() >= from && (best == nil || p.GetHead() > best.GetHead()) {
+			best = p
+		}
+	}
+	return best
+}
+
+// headerCount returns the number of headers requested from p from index
+// from.
+func (m *SyncManager) headerCount(p *Peer, from uint64) uint32 {
+	count := p.GetHead() - from + 1
+	if count > uint64(m.HeaderBatch) {
+		count = uint64(m.HeaderBatch)
+	}
+	return uint32(count)
+}
+
+// Sync puts the blocks of the peers ahead of the chain until it reaches the
+// highest head of the peers.
+func (m *SyncManager) Sync() error {
+	m.syncing.Lock()
+	defer m.syncing.Unlock()
+
+	for {
+		head, headHash, ok, err := m.getHead()
+		if err != nil {
+			return err
+		}
+		from := uint64(0)
+		if ok {
+			from = head + 1
+		}
+		p := m.bestPeer(from)
+		if p == nil {
+			return nil
+		}
+		headers, err := m.getHeaders(p, from, m.headerCount(p, from), headHash)
+		_, fork := err.(*forkError)
+		if fork {
+			err = m.syncBranch(p, head)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			m.Server.Penalize(p, getPenalty(err), err)
+			return err
+		}
+		blocks, err := m.download(from, headers)
+		if err != nil {
+			return err
+		}
+		err = m.putBlocks(p, blocks, true)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// syncBranch puts the branch of p which forks from the chain before head,
+// up to the head of p, and reorganizes the chain to it when the fork choice
+// of the chain prefers it.
+func (m *SyncManager) syncBranch(p *Peer, head uint64) error {
+	ancestor, parent, err := m.findAncestor(p, head)
+	if err != nil {
+		m.Server.Penalize(p, getPenalty(err), err)
+		return err
+	}
+	log.Printf("peer %s: branch from block %d", p.String(), ancestor)
+	from := ancestor + 1
+	last := p.GetHead()
+	for from <= last {
+		headers, err := m.getHeaders(p, from, m.headerCount(p, from), parent)
+		if err != nil {
+			m.Server.Penalize(p, getPenalty(err), err)
+			return err
+		}
+		blocks, err := m.download(from, headers)
+		if err != nil {
+			return err
+		}
+		err = m.putBlocks(p, blocks, false)
+		if err != nil {
+			return err
+		}
+		from += uint64(len(headers))
+		parent = headers[len(headers)-1].hash
+	}
+	chosen, err := m.Chain.ChooseBranch(parent)
+	if err != nil {
+		return err
+	}
+	if !chosen {
+		return fmt.Errorf("branch of peer %s from block %d is not chosen", p.String(), ancestor)
+	}
+	return m.apply(ancestor+1, from-1)
+}
+
+// findAncestor returns the index and the hash of the last block of the chain
+// up to head which is also in the chain of p.
+func (m *SyncManager) findAncestor(p *Peer, head uint64) (uint64, libcore.Hash, error) {
+	end := head
+	for {
+		start := uint64(0)
+		if end >= uint64(m.HeaderBatch) {
+			start = end - uint64(m.HeaderBatch) + 1
+		}
+		headers, err := m.getHeaders(p, start, uint32(end-start+1), nil)
+		if err != nil {
+			return 0, nil, err
+		}
+		for i := len(headers) - 1; i >= 0; i-- {
+			b, err := m.Chain.GetBlockByIndex(start + uint64(i))
+			if err != nil {
+				return 0, nil, err
+			}
+			h, _, err := m.Server.CryptoService.Raw(b, libcrypto.RawBinary)
+			if err != nil {
+				return 0, nil, err
+			}
+			if bytes.Equal(h, headers[i].hash) {
+				return start + uint64(i), h, nil
+			}
+		}
+		if start == 0 {
+			return 0, nil, &malformedError{errors.New("error genesis block")}
+		}
+		end = start - 1
+	}
+}
+
+// putBlocks verifies, puts and commits the blocks one by one, the peer p
+// which sent their headers is penalized for a block rejected by the Engine.
+// The blocks are applied to the Engine once committed when apply is true.
+func (m *SyncManager) putBlocks(p *Peer, blocks []libblock.Block, apply bool) error {
+	for i := 0; i < len(blocks); i++ {
+		if m.Engine != nil {
+			err := m.Engine.VerifyBlock(blocks[i])
+			if err != nil {
+				err = &malformedError{err}
+				m.Server.Penalize(p, getPenalty(err), err)
+				return err
+			}
+		}
+		err := m.Chain.PutBlock(blocks[i])
+		if err != nil {
+			m.Chain.Cancel()
+			return fmt.Errorf("block %d: %s", blocks[i].GetIndex(), err)
+		}
+		err = m.Chain.Commit()
+		if err != nil {
+			return err
+		}
+		if apply && m.Engine != nil {
+			err = m.Engine.Apply(blocks[i])
+			if err != nil {
+				return fmt.Errorf("block %d: %s", blocks[i].GetIndex(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// apply applies the blocks from..to of the chain to the Engine.
+func (m *SyncManager) apply(from uint64, to uint64) error {
+	if m.Engine == nil {
+		return nil
+	}
+	for i := from; i <= to; i++ {
+		b, err := m.Chain.GetBlockByIndex(i)
+		if err != nil {
+			return err
+		}
+		err = m.Engine.Apply(b)
+		if err != nil {
+			return fmt.Errorf("block %d: %s", i, err)
+		}
+	}
+	return nil
+}
+
+func (m *SyncManager) send(p *Peer, msg proto.Message, id uint64) (proto.Message, error) {
+	r := &request{peer: p, response: make(chan proto.Message, 1)}
+	m.locker.Lock()
+	m.pending[id] = r
+	m.locker.Unlock()
+	defer func() {
+		m.locker.Lock()
+		delete(m.pending, id)
+		m.locker.Unlock()
+	}()
+
+	data, err := core.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	err = p.Send(data)
+	if err != nil {
+		return nil, &sendError{err}
+	}
+	t := time.NewTimer(m.Timeout)
+	defer t.Stop()
+	select {
+	case response := <-r.response:
+		return response, nil
+	case <-t.C:
+		return nil, &timeoutError{p}
+	case <-m.closed:
+		return nil, errors.New("sync is closed")
+	}
+}
+
+// sendError is a request which could not be sent, the peer is not
+// penalized.
+type sendError struct {
+	err error
+}
+
+func (e *sendError) Error() string {
+	return e.err.Error()
+}
+
+// forkError is a first header which does not follow the head of the chain,
+// the peer is on another branch.
+type forkError struct {
+	index uint64
+}
+
+func (e *forkError) Error() string {
+	return fmt.Sprintf("header %d does not follow the head", e.index)
+}
+
+type timeoutError struct {
+	peer *Peer
+}
+
+func (e *timeoutError) Error() string {
+	return fmt.Sprintf("peer %s timed out", e.peer.String())
+}
+
+func (m *SyncManager) nextID() uint64 {
+	m.locker.Lock()
+	defer m.locker.Unlock()
+
+	m.requestID++
+	return m.requestID
+}
+
+// getHeaders requests count headers from index from and checks that they
+// follow each other, a *forkError is returned when the first one does not
+// follow parent.
+func (m *SyncManager) getHeaders(p *Peer, from uint64, count uint32, parent libcore.Hash) ([]header, error) {
+	id := m.nextID()
+	msg, err := m.send(p, &pb.GetHeaders{RequestID: id, From: from, Count: count}, id)
+	if err != nil {
+		return nil, err
+	}
+	response, ok := msg.(*pb.Headers)
+	if !ok || response.From != from {
+		return nil, &malformedError{errors.New("error headers response")}
+	}
+	l := len(response.Headers)
+	if l == 0 || l > int(count) {
+		return nil, fmt.Errorf("%d headers from %d, expected %d", l, from, count)
+	}
+	headers := make([]header, l)
+	for i := 0; i < l; i++ {
+		h := header{
+			hash:       libcore.Hash(response.Headers[i].Hash),
+			parentHash: libcore.Hash(response.Headers[i].ParentHash),
+		}
+		if i == 0 && parent != nil && !bytes.Equal(h.parentHash, parent) {
+			return nil, &forkError{from}
+		}
+		if i > 0 && !bytes.Equal(h.parentHash, parent) {
+			return nil, &malformedError{fmt.Errorf("header %d does not follow its parent", from+uint64(i))}
+		}
+		headers[i] = h
+		parent = h.hash
+	}
+	return headers, nil
+}
+
+// download gets the blocks of headers from the peers, in batches of
+// BlockBatch blocks and at most Parallel requests at once. The batches are
+// spread over the peers ordered by score, a batch failing is requested again
+// from the next peer.
+func (m *SyncManager) download(from uint64, headers []header) ([]libblock.Block, error) {
+	peers := m.Server.GetPeers()
+	sort.SliceStable(peers, func(i, j int) bool {
+		return peers[i].GetScore() < peers[j].GetScore()
+	})
+	blocks := make([]libblock.Block, len(headers))
+	batches := make(chan int, (len(headers)+m.BlockBatch-1)/m.BlockBatch)
+	for i := 0; i < len(headers); i += m.BlockBatch {
+		batches <- i
+	}
+	close(batches)
+
+	var errs []error
+	errsLocker := &sync.Mutex{}
+	wg := &sync.WaitGroup{}
+	for w := 0; w < m.Parallel; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for start := range batches {
+				end := start + m.BlockBatch
+				if end > len(headers) {
+					end = len(headers)
+				}
+				err := m.downloadBatch(peers, from, headers, blocks, start, end)
+				if err != nil {
+					errsLocker.Lock()
+					errs = append(errs, err)
+					errsLocker.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return blocks, nil
+}
+
+// downloadBatch requests the blocks start..end from the peers which have
+// them, starting with the peer of the rank of the batch.
+func (m *SyncManager) downloadBatch(peers []*Peer, from uint64, headers []header, blocks []libblock.Block, start int, end int) error {
+	last := from + uint64(end-1)
+	candidates := make([]*Peer, 0, len(peers))
+	for _, p := range peers {
+		if p.GetHead() >= last {
+			candidates = append(candidates, p)
+		}
+	}
+	l := len(candidates)
+	first := start / m.BlockBatch
+	for i := 0; i < l; i++ {
+		p := candidates[(first+i)%l]
+		if m.Server.IsBanned(p.NodeID) {
+			continue
+		}
+		err := m.getBlocks(p, from, headers, blocks, start, end)
+		if err == nil {
+			return nil
+		}
+		m.Server.Penalize(p, getPenalty(err), err)
+	}
+	return fmt.Errorf("no peer for blocks %d-%d", from+uint64(start), last)
+}
+
+func (m *SyncManager) getBlocks(p *Peer, from uint64, headers []header, blocks []libblock.Block, start int, end int) error {
+	id := m.nextID()
+	first := from + uint64(start)
+	msg, err := m.send(p, &pb.GetBlocks{RequestID: id, From: first, Count: uint32(end - start)}, id)
+	if err != nil {
+		return err
+	}
+	response, ok := msg.(*pb.Blocks)
+	if !ok || response.From != first {
+		return &malformedError{errors.New("error blocks response")}
+	}
+	if len(response.Blocks) != end-start {
+		return fmt.Errorf("%d blocks from %d, expected %d", len(response.Blocks), first, end-start)
+	}
+	for i := start; i < end; i++ {
+		b := &block.Block{}
+		err := b.UnmarshalBinary(response.Blocks[i-start])
+		if err != nil {
+			return &malformedError{err}
+		}
+		h, _, err := m.Server.CryptoService.Raw(b, libcrypto.RawBinary)
+		if err != nil {
+			return err
+		}
+		if b.GetIndex() != from+uint64(i) || !bytes.Equal(h, headers[i].hash) {
+			return &malformedError{fmt.Errorf("block %d does not match its header", from+uint64(i))}
+		}
+		blocks[i] = b
+	}
+	return nil
+}
+
+func (m *SyncManager) handleResponse(p *Peer, data []byte) error {
+	_, msg, err := core.Unmarshal(data)
+	if err != nil {
+		return &malformedError{err}
+	}
+	var id uint64
+	switch response := msg.(type) {
+	case *pb.Headers:
+		id = response.RequestID
+	case *pb.Blocks:
+		id = response.RequestID
+	}
+	m.locker.Lock()
+	r, ok := m.pending[id]
+	m.locker.Unlock()
+	// a late answer is dropped
+	if !ok || r.peer != p {
+		return nil
+	}
+	select {
+	case r.response <- msg:
+	default:
+	}
+	return nil
+}
+
+func batchSize(count uint32, max int) int {
+	if count == 0 || int(count) > max {
+		return max
+	}
+	return int(count)
+}
+
+// reply sends a response, the requester is not penalized when its queue is
+// full, it times out.
+func (m *SyncManager) reply(p *Peer, data []byte) {
+	err := p.Send(data)
+	if err != nil {
+		log.Printf("peer %s: %s", p.String(), err)
+	}
+}
+
+func (m *SyncManager) handleGetHeaders(p *Peer, data []byte) error {
+	_, msg, err := core.Unmarshal(data)
+	if err != nil {
+		return &malformedError{err}
+	}
+	request := msg.(*pb.GetHeaders)
+	response := &pb.Headers{RequestID: request.RequestID, From: request.From}
+	l := batchSize(request.Count, m.HeaderBatch)
+	for i := 0; i < l; i++ {
+		b, err := m.Chain.GetBlockByIndex(request.From + uint64(i))
+		if err != nil {
+			break
+		}
+		h, _, err := m.Server.CryptoService.Raw(b, libcrypto.RawBinary)
+		if err != nil {
+			return err
+		}
+		response.Headers = append(response.Headers, &pb.Header{
+			Hash:       []byte(h),
+			ParentHash: []byte(b.GetParentHash()),
+		})
+	}
+	data, err = core.Marshal(response)
+	if err != nil {
+		return err
+	}
+	m.reply(p, data)
+	return nil
+}
+
+func (m *SyncManager) handleGetBlocks(p *Peer, data []byte) error {
+	_, msg, err := core.Unmarshal(data)
+	if err != nil {
+		return &malformedError{err}
+	}
+	request := msg.(*pb.GetBlocks)
+	response := &pb.Blocks{RequestID: request.RequestID, From: request.From}
+	l := batchSize(request.Count, m.BlockBatch)
+	for i := 0; i < l; i++ {
+		b, err := m.Chain.GetBlockByIndex(request.From + uint64(i))
+		if err != nil {
+			break
+		}
+		data, err := b.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		response.Blocks = append(response.Blocks, data)
+	}
+	data, err = core.Marshal(response)
+	if err != nil {
+		return err
+	}
+	m.reply(p, data)
+	return nil
+}
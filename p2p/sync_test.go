@@ -0,0 +1,386 @@
+package p2p
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tokentransfer/chain/block"
+	"github.com/tokentransfer/chain/core"
+	"github.com/tokentransfer/chain/crypto"
+	"github.com/tokentransfer/chain/node"
+
+	. "github.com/tokentransfer/check"
+	libblock "github.com/tokentransfer/interfaces/block"
+	libcore "github.com/tokentransfer/interfaces/core"
+	libcrypto "github.com/tokentransfer/interfaces/crypto"
+)
+
+type SyncSuite struct{}
+
+func Test_Sync(t *testing.T) {
+	s := Suite(&SyncSuite{})
+	TestingRun(t, s)
+}
+
+type testChain struct {
+	locker  *sync.Mutex
+	blocks  []libblock.Block
+	pending []libblock.Block
+}
+
+func newTestChain(c *C, n int) *testChain {
+	cs := &crypto.CryptoService{}
+	chain := &testChain{locker: &sync.Mutex{}}
+	var parent []byte
+	for i := 0; i < n; i++ {
+		b := &block.Block{BlockIndex: uint64(i), ParentHash: parent, Timestamp: int64(i)}
+		h, _, err := cs.Raw(b, libcrypto.RawBinary)
+		c.Assert(err, IsNil)
+		chain.blocks = append(chain.blocks, b)
+		parent = h
+	}
+	return chain
+}
+
+func (chain *testChain) GetHead() (libblock.Block, error) {
+	chain.locker.Lock()
+	defer chain.locker.Unlock()
+
+	if len(chain.blocks) == 0 {
+		return nil, node.ErrNoBlock
+	}
+	return chain.blocks[len(chain.blocks)-1], nil
+}
+
+func (chain *testChain) GetBlockByIndex(index uint64) (libblock.Block, error) {
+	chain.locker.Lock()
+	defer chain.locker.Unlock()
+
+	if index >= uint64(len(chain.blocks)) {
+		return nil, fmt.Errorf("no block %d", index)
+	}
+	return chain.blocks[index], nil
+}
+
+func (chain *testChain) PutBlock(b libblock.Block) error {
+	chain.locker.Lock()
+	defer chain.locker.Unlock()
+
+	if b.GetIndex() != uint64(len(chain.blocks)+len(chain.pending)) {
+		return fmt.Errorf("block %d out of order", b.GetIndex())
+	}
+	chain.pending = append(chain.pending, b)
+	return nil
+}
+
+// ChooseBranch only accepts the head, the chain has no branch.
+func (chain *testChain) ChooseBranch(h libcore.Hash) (bool, error) {
+	chain.locker.Lock()
+	defer chain.locker.Unlock()
+
+	cs := &crypto.CryptoService{}
+	head, _, err := cs.Raw(chain.blocks[len(chain.blocks)-1], libcrypto.RawBinary)
+	if err != nil {
+		return false, err
+	}
+	if !bytes.Equal(head, h) {
+		return false, errors.New("error branch")
+	}
+	return true, nil
+}
+
+func (chain *testChain) Commit() error {
+	chain.locker.Lock()
+	defer chain.locker.Unlock()
+
+	chain.blocks = append(chain.blocks, chain.pending...)
+	chain.pending = nil
+	return nil
+}
+
+func (chain *testChain) Cancel() error {
+	chain.locker.Lock()
+	defer chain.locker.Unlock()
+
+	chain.pending = nil
+	return nil
+}
+
+func (chain *testChain) head() uint64 {
+	chain.locker.Lock()
+	defer chain.locker.Unlock()
+
+	return uint64(len(chain.blocks) - 1)
+}
+
+// badChain serves blocks which do not match their headers.
+type badChain struct {
+	*testChain
+}
+
+func (chain *badChain) GetBlockByIndex(index uint64) (libblock.Block, error) {
+	b, err := chain.testChain.GetBlockByIndex(index)
+	if err != nil {
+		return nil, err
+	}
+	bad := *b.(*block.Block)
+	bad.Timestamp++
+	return &bad, nil
+}
+
+func newSyncNode(c *C, chain Chain, peers ...string) (*Server, *SyncManager) {
+	s := &Server{
+		ListenAddress:  "127.0.0.1:0",
+		Peers:          peers,
+		RedialInterval: 50 * time.Millisecond,
+		ChainID:        1,
+		CryptoService:  &crypto.CryptoService{},
+	}
+	err := s.Init(nil)
+	c.Assert(err, IsNil)
+	m := &SyncManager{
+		Server:      s,
+		Chain:       chain,
+		HeaderBatch: 40,
+		BlockBatch:  8,
+		Timeout:     time.Second,
+	}
+	err = m.Init(nil)
+	c.Assert(err, IsNil)
+	err = s.Start()
+	c.Assert(err, IsNil)
+	return s, m
+}
+
+func (suite *SyncSuite) TestSync(c *C) {
+	source := newTestChain(c, 100)
+	s1, m1 := newSyncNode(c, source)
+	defer s1.Close()
+	s2, m2 := newSyncNode(c, newTestChain(c, 100))
+	defer s2.Close()
+
+	// the batches of blocks are spread over the peers
+	served := make([]int, 2)
+	servedLocker := &sync.Mutex{}
+	countBatches := func(i int, m *SyncManager) Handler {
+		return func(p *Peer, data []byte) error {
+			servedLocker.Lock()
+			served[i]++
+			servedLocker.Unlock()
+			return m.handleGetBlocks(p, data)
+		}
+	}
+	s1.Handle(core.CORE_GET_BLOCKS, countBatches(0, m1))
+	s2.Handle(core.CORE_GET_BLOCKS, countBatches(1, m2))
+
+	chain := newTestChain(c, 1)
+	s, m := newSyncNode(c, chain, s1.Addr(), s2.Addr())
+	defer s.Close()
+	waitFor(c, func() bool {
+		return len(s.GetPeers()) == 2
+	})
+
+	err := m.Sync()
+	c.Assert(err, IsNil)
+	c.Assert(chain.head(), Equals, uint64(99))
+	cs := &crypto.CryptoService{}
+	for i := 0; i < 100; i++ {
+		h, _, err := cs.Raw(chain.blocks[i], libcrypto.RawBinary)
+		c.Assert(err, IsNil)
+		c.Assert(h, DeepEquals, source.blocks[i].GetHash())
+	}
+	// 40, 40 and 19 blocks in batches of 8
+	c.Assert(served[0]+served[1], Equals, 13)
+	c.Assert(served[0] >= 5 && served[1] >= 5, Equals, true)
+	m1.Close()
+	m2.Close()
+	m.Close()
+}
+
+func (suite *SyncSuite) TestBadPeer(c *C) {
+	s1, _ := newSyncNode(c, newTestChain(c, 50))
+	defer s1.Close()
+	s2, _ := newSyncNode(c, &badChain{newTestChain(c, 50)})
+	defer s2.Close()
+
+	chain := newTestChain(c, 1)
+	s, m := newSyncNode(c, chain)
+	defer s.Close()
+
+	err := s.Connect(s2.Addr())
+	c.Assert(err, IsNil)
+	err = m.Sync()
+	c.Assert(err, NotNil)
+	c.Assert(chain.head(), Equals, uint64(0))
+	c.Assert(s.IsBanned(s2.Addr()), Equals, true)
+	c.Assert(len(s.GetPeers()), Equals, 0)
+
+	err = s.Connect(s1.Addr())
+	c.Assert(err, IsNil)
+	err = m.Sync()
+	c.Assert(err, IsNil)
+	c.Assert(chain.head(), Equals, uint64(49))
+}
+
+type testConfig struct {
+	libcore.Config
+
+	dir string
+}
+
+func (c *testConfig) GetDataDir() string {
+	return c.dir
+}
+
+// extendBlocks returns blocks followed by n blocks, timestamp tells apart
+// the blocks of two branches.
+func extendBlocks(c *C, blocks []libblock.Block, n int, timestamp int64) []libblock.Block {
+	cs := &crypto.CryptoService{}
+	list := append([]libblock.Block{}, blocks...)
+	for i := 0; i < n; i++ {
+		b := &block.Block{BlockIndex: uint64(len(list)), Timestamp: timestamp + int64(len(list))}
+		if len(list) > 0 {
+			h, _, err := cs.Raw(list[len(list)-1], libcrypto.RawBinary)
+			c.Assert(err, IsNil)
+			b.ParentHash = h
+		}
+		list = append(list, b)
+	}
+	return list
+}
+
+func newMerkleChain(c *C, blocks []libblock.Block) *node.MerkleService {
+	service := &node.MerkleService{CryptoService: &crypto.CryptoService{}}
+	err := service.Init(&testConfig{dir: c.MkDir()})
+	c.Assert(err, IsNil)
+	for _, b := range blocks {
+		err = service.PutBlock(b)
+		c.Assert(err, IsNil)
+		err = service.Commit()
+		c.Assert(err, IsNil)
+	}
+	return service
+}
+
+// testEngine checks that the blocks are applied once committed in the
+// canonical chain.
+type testEngine struct {
+	chain   Chain
+	applied []uint64
+}
+
+func (e *testEngine) VerifyBlock(b libblock.Block) error {
+	return nil
+}
+
+func (e *testEngine) Apply(b libblock.Block) error {
+	cs := &crypto.CryptoService{}
+	h, _, err := cs.Raw(b, libcrypto.RawBinary)
+	if err != nil {
+		return err
+	}
+	canonical, err := e.chain.GetBlockByIndex(b.GetIndex())
+	if err != nil {
+		return err
+	}
+	ch, _, err := cs.Raw(canonical, libcrypto.RawBinary)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(h, ch) {
+		return fmt.Errorf("block %d is not committed", b.GetIndex())
+	}
+	e.applied = append(e.applied, b.GetIndex())
+	return nil
+}
+
+func (suite *SyncSuite) TestSyncMerkle(c *C) {
+	common := extendBlocks(c, nil, 4, 0)
+	blocks := extendBlocks(c, common, 6, 0)
+	source := newMerkleChain(c, blocks)
+	defer source.Close()
+	s1, _ := newSyncNode(c, source)
+	defer s1.Close()
+
+	sync := func(chain *node.MerkleService) *testEngine {
+		s, m := newSyncNode(c, chain, s1.Addr())
+		defer s.Close()
+		e := &testEngine{chain: chain}
+		m.Engine = e
+		waitFor(c, func() bool {
+			peers := s.GetPeers()
+			return len(peers) == 1 && peers[0].GetHead() == 9
+		})
+		err := m.Sync()
+		c.Assert(err, IsNil)
+		c.Assert(s.GetPeers()[0].GetScore(), Equals, 0)
+
+		cs := &crypto.CryptoService{}
+		for i := 0; i < 10; i++ {
+			b, err := chain.GetBlockByIndex(uint64(i))
+			c.Assert(err, IsNil)
+			h, _, err := cs.Raw(b, libcrypto.RawBinary)
+			c.Assert(err, IsNil)
+			expected, _, err := cs.Raw(blocks[i], libcrypto.RawBinary)
+			c.Assert(err, IsNil)
+			c.Assert(h, DeepEquals, expected)
+		}
+		return e
+	}
+
+	// an empty chain
+	empty := newMerkleChain(c, nil)
+	defer empty.Close()
+	e := sync(empty)
+	c.Assert(e.applied, DeepEquals, []uint64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9})
+
+	// a chain of another branch from block 4
+	local := newMerkleChain(c, extendBlocks(c, common, 2, 1000))
+	defer local.Close()
+	e = sync(local)
+	c.Assert(e.applied, DeepEquals, []uint64{4, 5, 6, 7, 8, 9})
+	list, err := local.GetBlocksByIndex(4)
+	c.Assert(err, IsNil)
+	c.Assert(len(list), Equals, 2)
+}
+
+func (suite *SyncSuite) TestForkChoice(c *C) {
+	common := extendBlocks(c, nil, 4, 0)
+	source := newMerkleChain(c, extendBlocks(c, common, 6, 0))
+	defer source.Close()
+	s1, _ := newSyncNode(c, source)
+	defer s1.Close()
+
+	// the longer branch of the peer is refused by the fork choice
+	local := newMerkleChain(c, extendBlocks(c, common, 2, 1000))
+	defer local.Close()
+	local.ForkChoice = func(head libblock.Block, candidate libblock.Block) bool {
+		return false
+	}
+	head, err := local.GetHead()
+	c.Assert(err, IsNil)
+	s, m := newSyncNode(c, local, s1.Addr())
+	defer s.Close()
+	waitFor(c, func() bool {
+		peers := s.GetPeers()
+		return len(peers) == 1 && peers[0].GetHead() == 9
+	})
+	err = m.Sync()
+	c.Assert(err, NotNil)
+	c.Assert(s.GetPeers()[0].GetScore(), Equals, 0)
+	current, err := local.GetHead()
+	c.Assert(err, IsNil)
+	c.Assert(current.GetHash(), DeepEquals, head.GetHash())
+
+	// and taken once the fork choice prefers it
+	local.ForkChoice = node.LongestChain
+	err = m.Sync()
+	c.Assert(err, IsNil)
+	current, err = local.GetHead()
+	c.Assert(err, IsNil)
+	c.Assert(current.GetIndex(), Equals, uint64(9))
+}
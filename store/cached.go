@@ -0,0 +1,115 @@
+package store
+
+import (
+	"errors"
+
+	"github.com/tokentransfer/interfaces/core"
+	libstore "github.com/tokentransfer/interfaces/store"
+)
+
+// CachedService wraps a KvService and keeps the last Size values read or
+// written in memory. The values returned are copies, the caller may change
+// them.
+type CachedService struct {
+	Service libstore.KvService
+	Size    int
+
+	cache *Cache
+}
+
+func (service *CachedService) Close() error {
+	return service.Service.Close()
+}
+
+func (service *CachedService) Init(c core.Config) error {
+	if service.Size <= 0 {
+		return errors.New("error cache size")
+	}
+	service.cache = NewCache(service.Size)
+	return service.Service.Init(c)
+}
+
+func (service *CachedService) Start() error {
+	return service.Service.Start()
+}
+
+func (service *CachedService) Stats() CacheStats {
+	return service.cache.Stats()
+}
+
+func (service *CachedService) PutData(key []byte, value []byte) error {
+	err := service.Service.PutData(key, value)
+	if err != nil {
+		service.cache.Remove(string(key))
+		return err
+	}
+	service.cache.Add(string(key), append([]byte(nil), value...))
+	return nil
+}
+
+func (service *CachedService) PutDatas(keys [][]byte, values [][]byte) error {
+	lk := len(keys)
+	lv := len(values)
+	if lk != lv {
+		return errors.New("length error")
+	}
+	err := service.Service.PutDatas(keys, values)
+	for i := 0; i < lk; i++ {
+		if err != nil {
+			service.cache.Remove(string(keys[i]))
+		} else {
+			service.cache.Add(string(keys[i]), append([]byte(nil), values[i]...))
+		}
+	}
+	return err
+}
+
+func (service *CachedService) Flush() error {
+	service.cache.Purge()
+	return service.Service.Flush()
+}
+
+func (service *CachedService) GetData(key []byte) ([]byte, error) {
+	value, ok := service.cache.Get(string(key))
+	if ok {
+		return append([]byte(nil), value.([]byte)...), nil
+	}
+	data, err := service.Service.GetData(key)
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		service.cache.Add(string(key), append([]byte(nil), data...))
+	}
+	return data, nil
+}
+
+func (service *CachedService) GetDatas(keys [][]byte) ([][]byte, error) {
+	l := len(keys)
+	bytes := make([][]byte, l)
+	for i := 0; i < l; i++ {
+		value, err := service.GetData(keys[i])
+		if err != nil {
+			return nil, err
+		}
+		bytes[i] = value
+	}
+	return bytes, nil
+}
+
+func (service *CachedService) HasData(key []byte) bool {
+	value, ok := service.cache.Get(string(key))
+	if ok {
+		return len(value.([]byte)) > 0
+	}
+	return service.Service.HasData(key)
+}
+
+func (service *CachedService) RemoveData(key []byte) error {
+	service.cache.Remove(string(key))
+	return service.Service.RemoveData(key)
+}
+
+func (service *CachedService) ListData(each func(key []byte, value []byte) error) error {
+	return service.Service.ListData(each)
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"testing"
+
+	. "github.com/tokentransfer/check"
+)
+
+type CachedSuite struct{}
+
+func Test_Cached(t *testing.T) {
+	s := Suite(&CachedSuite{})
+	TestingRun(t, s)
+}
+
+func (suite *CachedSuite) TestEviction(c *C) {
+	cache := NewCache(2)
+	cache.Add("a", 1)
+	cache.Add("b", 2)
+	_, ok := cache.Get("a")
+	c.Assert(ok, Equals, true)
+
+	cache.Add("c", 3)
+	_, ok = cache.Get("b")
+	c.Assert(ok, Equals, false)
+	v, ok := cache.Get("a")
+	c.Assert(ok, Equals, true)
+	c.Assert(v, Equals, 1)
+
+	stats := cache.Stats()
+	c.Assert(stats.Hits, Equals, uint64(2))
+	c.Assert(stats.Misses, Equals, uint64(1))
+	c.Assert(stats.Size, Equals, 2)
+}
+
+func (suite *CachedSuite) TestService(c *C) {
+	mem := &MemoryService{}
+	service := &CachedService{Service: mem, Size: 16}
+	err := service.Init(nil)
+	c.Assert(err, IsNil)
+
+	err = mem.PutData([]byte("key"), []byte("value"))
+	c.Assert(err, IsNil)
+	value, err := service.GetData([]byte("key"))
+	c.Assert(err, IsNil)
+	c.Assert(string(value), Equals, "value")
+
+	err = mem.RemoveData([]byte("key"))
+	c.Assert(err, IsNil)
+	value, err = service.GetData([]byte("key"))
+	c.Assert(err, IsNil)
+	c.Assert(string(value), Equals, "value")
+
+	err = service.RemoveData([]byte("key"))
+	c.Assert(err, IsNil)
+	value, err = service.GetData([]byte("key"))
+	c.Assert(err, IsNil)
+	c.Assert(value, IsNil)
+
+	stats := service.Stats()
+	c.Assert(stats.Hits, Equals, uint64(1))
+	c.Assert(stats.Misses, Equals, uint64(2))
+}
+
+func (suite *CachedSuite) TestCopies(c *C) {
+	service := &CachedService{Service: &MemoryService{}, Size: 16}
+	err := service.Init(nil)
+	c.Assert(err, IsNil)
+
+	err = service.PutData([]byte("key"), []byte("value"))
+	c.Assert(err, IsNil)
+	value, err := service.GetData([]byte("key"))
+	c.Assert(err, IsNil)
+	value[0] = 'V'
+	value, err = service.GetData([]byte("key"))
+	c.Assert(err, IsNil)
+	c.Assert(string(value), Equals, "value")
+}
@@ -0,0 +1,466 @@
+package store
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/tokentransfer/interfaces/core"
+	libstore "github.com/tokentransfer/interfaces/store"
+)
+
+const (
+	nonceSize    = 12
+	maxKeys      = 256
+	migrateBatch = 1024
+)
+
+type sealer struct {
+	aead cipher.AEAD
+	mac  []byte
+}
+
+func newSealer(key []byte) (*sealer, error) {
+	l := len(key)
+	if l != 16 && l != 24 && l != 32 {
+		return nil, fmt.Errorf("error key size %d", l)
+	}
+	encKey := deriveKey(key, "value", l)
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCMWithNonceSize(block, nonceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &sealer{
+		aead: aead,
+		mac:  deriveKey(key, "siv", sha256.Size),
+	}, nil
+}
+
+func deriveKey(key []byte, label string, size int) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(label))
+	return h.Sum(nil)[:size]
+}
+
+// seal encrypts data as id || nonce || ciphertext, if deterministic is set the
+// nonce is derived from the plaintext so equal inputs give equal outputs.
+func (s *sealer) seal(id byte, data []byte, additional []byte, deterministic bool) ([]byte, error) {
+	nonce := make([]byte, nonceSize)
+	if deterministic {
+		h := hmac.New(sha256.New, s.mac)
+		h.Write(additional)
+		h.Write(data)
+		copy(nonce, h.Sum(nil))
+	} else {
+		_, err := io.ReadFull(rand.Reader, nonce)
+		if err != nil {
+			return nil, err
+		}
+	}
+	out := make([]byte, 0, 1+nonceSize+len(data)+s.aead.Overhead())
+	out = append(out, id)
+	out = append(out, nonce...)
+	return s.aead.Seal(out, nonce, data, additional), nil
+}
+
+func (s *sealer) open(data []byte, additional []byte) ([]byte, error) {
+	if len(data) < 1+nonceSize {
+		return nil, errors.New("error encrypted data")
+	}
+	nonce := data[1 : 1+nonceSize]
+	return s.aead.Open(nil, nonce, data[1+nonceSize:], additional)
+}
+
+// EncryptedService wraps a KvService and encrypts values with AES-GCM. Keys are
+// the key ring, the last one is used for new data and the older ones are only
+// used to read data written before a rotation, a retired key is set to nil to
+// keep the position of the others. If EncryptKeys is set, the keys are
+// encrypted too with a deterministic nonce so lookups still work.
+type EncryptedService struct {
+	Service     libstore.KvService
+	Keys        [][]byte
+	EncryptKeys bool
+
+	sealers []*sealer
+}
+
+func (service *EncryptedService) Close() error {
+	return service.Service.Close()
+}
+
+func (service *EncryptedService) Init(c core.Config) error {
+	l := len(service.Keys)
+	if l == 0 {
+		return errors.New("no key for encrypted store")
+	}
+	if l > maxKeys {
+		return errors.New("too many keys for encrypted store")
+	}
+	if service.Keys[l-1] == nil {
+		return errors.New("no current key for encrypted store")
+	}
+	sealers := make([]*sealer, l)
+	for i := 0; i < l; i++ {
+		if service.Keys[i] == nil {
+			continue
+		}
+		s, err := newSealer(service.Keys[i])
+		if err != nil {
+			return err
+		}
+		sealers[i] = s
+	}
+	service.sealers = sealers
+
+	return service.Service.Init(c)
+}
+
+func (service *EncryptedService) Start() error {
+	return service.Service.Start()
+}
+
+// Rotate adds a new key to the key ring, new data is written with it while
+// the existing data stays readable until ReEncrypt is called.
+func (service *EncryptedService) Rotate(key []byte) error {
+	if len(service.sealers) >= maxKeys {
+		return errors.New("too many keys for encrypted store")
+	}
+	s, err := newSealer(key)
+	if err != nil {
+		return err
+	}
+	service.Keys = append(service.Keys, key)
+	service.sealers = append(service.sealers, s)
+	return nil
+}
+
+// Retire removes the key at position i from the key ring, the data written
+// with it can no longer be read, see ReEncrypt. The current key can not be
+// retired.
+func (service *EncryptedService) Retire(i int) error {
+	l := len(service.sealers)
+	if i < 0 || i >= l {
+		return fmt.Errorf("no encryption key %d", i)
+	}
+	if i == l-1 {
+		return errors.New("error retiring the current key")
+	}
+	service.Keys[i] = nil
+	service.sealers[i] = nil
+	return nil
+}
+
+func (service *EncryptedService) current() (byte, *sealer) {
+	id := len(service.sealers) - 1
+	return byte(id), service.sealers[id]
+}
+
+func (service *EncryptedService) sealerOf(data []byte) (*sealer, error) {
+	if len(data) == 0 {
+		return nil, errors.New("error encrypted data")
+	}
+	id := int(data[0])
+	if id >= len(service.sealers) || service.sealers[id] == nil {
+		return nil, fmt.Errorf("unknown encryption key %d", id)
+	}
+	return service.sealers[id], nil
+}
+
+func (service *EncryptedService) encryptKey(id byte, s *sealer, key []byte) ([]byte, error) {
+	if !service.EncryptKeys {
+		return key, nil
+	}
+	return s.seal(id, key, nil, true)
+}
+
+func (service *EncryptedService) decryptKey(key []byte) ([]byte, error) {
+	if !service.EncryptKeys {
+		return key, nil
+	}
+	s, err := service.sealerOf(key)
+	if err != nil {
+		return nil, err
+	}
+	return s.open(key, nil)
+}
+
+func (service *EncryptedService) decryptValue(key []byte, value []byte) ([]byte, error) {
+	s, err := service.sealerOf(value)
+	if err != nil {
+		return nil, err
+	}
+	return s.open(value, key)
+}
+
+// lookup finds the stored key of key, trying the key ring from the newest key
+// to the oldest one.
+func (service *EncryptedService) lookup(key []byte) ([]byte, []byte, error) {
+	if !service.EncryptKeys {
+		value, err := service.Service.GetData(key)
+		if err != nil {
+			return nil, nil, err
+		}
+		return key, value, nil
+	}
+	for i := len(service.sealers) - 1; i >= 0; i-- {
+		if service.sealers[i] == nil {
+			continue
+		}
+		k, err := service.encryptKey(byte(i), service.sealers[i], key)
+		if err != nil {
+			return nil, nil, err
+		}
+		value, err := service.Service.GetData(k)
+		if err != nil {
+			return nil, nil, err
+		}
+		if value != nil {
+			return k, value, nil
+		}
+	}
+	return nil, nil, nil
+}
+
+func (service *EncryptedService) seal(key []byte, value []byte) ([]byte, []byte, error) {
+	id, s := service.current()
+	k, err := service.encryptKey(id, s, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	v, err := s.seal(id, value, key, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return k, v, nil
+}
+
+// removeStale removes the copies of key written with the older keys.
+func (service *EncryptedService) removeStale(key []byte) error {
+	if !service.EncryptKeys {
+		return nil
+	}
+	l := len(service.sealers) - 1
+	for i := 0; i < l; i++ {
+		if service.sealers[i] == nil {
+			continue
+		}
+		k, err := service.encryptKey(byte(i), service.sealers[i], key)
+		if err != nil {
+			return err
+		}
+		if service.Service.HasData(k) {
+			err = service.Service.RemoveData(k)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (service *EncryptedService) PutData(key []byte, value []byte) error {
+	k, v, err := service.seal(key, value)
+	if err != nil {
+		return err
+	}
+	err = service.Service.PutData(k, v)
+	if err != nil {
+		return err
+	}
+	return service.removeStale(key)
+}
+
+func (service *EncryptedService) PutDatas(keys [][]byte, values [][]byte) error {
+	lk := len(keys)
+	lv := len(values)
+	if lk != lv {
+		return errors.New("length error")
+	}
+	ks := make([][]byte, lk)
+	vs := make([][]byte, lv)
+	for i := 0; i < lk; i++ {
+		k, v, err := service.seal(keys[i], values[i])
+		if err != nil {
+			return err
+		}
+		ks[i] = k
+		vs[i] = v
+	}
+	err := service.Service.PutDatas(ks, vs)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < lk; i++ {
+		err := service.removeStale(keys[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (service *EncryptedService) Flush() error {
+	return service.Service.Flush()
+}
+
+func (service *EncryptedService) GetData(key []byte) ([]byte, error) {
+	_, value, err := service.lookup(key)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, nil
+	}
+	return service.decryptValue(key, value)
+}
+
+func (service *EncryptedService) GetDatas(keys [][]byte) ([][]byte, error) {
+	l := len(keys)
+	bytes := make([][]byte, l)
+	for i := 0; i < l; i++ {
+		value, err := service.GetData(keys[i])
+		if err != nil {
+			return nil, err
+		}
+		bytes[i] = value
+	}
+	return bytes, nil
+}
+
+func (service *EncryptedService) HasData(key []byte) bool {
+	value, err := service.GetData(key)
+	if err != nil {
+		return false
+	}
+	if len(value) == 0 {
+		return false
+	}
+	return true
+}
+
+func (service *EncryptedService) RemoveData(key []byte) error {
+	k, _, err := service.lookup(key)
+	if err != nil {
+		return err
+	}
+	if k == nil {
+		return nil
+	}
+	return service.Service.RemoveData(k)
+}
+
+func (service *EncryptedService) ListData(each func(key []byte, value []byte) error) error {
+	return service.Service.ListData(func(k []byte, v []byte) error {
+		key, err := service.decryptKey(k)
+		if err != nil {
+			return err
+		}
+		value, err := service.decryptValue(key, v)
+		if err != nil {
+			return err
+		}
+		return each(key, value)
+	})
+}
+
+// ReEncrypt rewrites in place every entry which is not encrypted with the
+// current key, by batches of migrateBatch entries: the new entries are
+// written before their stale copies are removed. After it returns the older
+// keys can be retired.
+func (service *EncryptedService) ReEncrypt() error {
+	id, _ := service.current()
+
+	var keys, values, stales [][]byte
+	err := service.Service.ListData(func(k []byte, v []byte) error {
+		if len(v) > 0 && v[0] == id && (!service.EncryptKeys || (len(k) > 0 && k[0] == id)) {
+			return nil
+		}
+		key, err := service.decryptKey(k)
+		if err != nil {
+			return err
+		}
+		value, err := service.decryptValue(key, v)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, append([]byte(nil), key...))
+		values = append(values, value)
+		stales = append(stales, append([]byte(nil), k...))
+		if len(keys) < migrateBatch {
+			return nil
+		}
+		err = service.reEncrypt(keys, values, stales)
+		keys, values, stales = nil, nil, nil
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return service.reEncrypt(keys, values, stales)
+}
+
+// reEncrypt writes keys and values with the current key, then removes the
+// stale stored keys which differ from the new ones.
+func (service *EncryptedService) reEncrypt(keys [][]byte, values [][]byte, stales [][]byte) error {
+	l := len(keys)
+	if l == 0 {
+		return nil
+	}
+	ks := make([][]byte, l)
+	vs := make([][]byte, l)
+	for i := 0; i < l; i++ {
+		k, v, err := service.seal(keys[i], values[i])
+		if err != nil {
+			return err
+		}
+		ks[i] = k
+		vs[i] = v
+	}
+	err := service.Service.PutDatas(ks, vs)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < l; i++ {
+		if bytes.Equal(ks[i], stales[i]) {
+			continue
+		}
+		err = service.Service.RemoveData(stales[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Migrate copies every entry from one service to another, it is used to
+// encrypt or decrypt an existing store or to move it to a new key ring.
+func Migrate(from libstore.KvService, to libstore.KvService) error {
+	var keys, values [][]byte
+	err := from.ListData(func(key []byte, value []byte) error {
+		keys = append(keys, append([]byte(nil), key...))
+		values = append(values, append([]byte(nil), value...))
+		if len(keys) < migrateBatch {
+			return nil
+		}
+		err := to.PutDatas(keys, values)
+		keys, values = nil, nil
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return to.PutDatas(keys, values)
+}
@@ -0,0 +1,139 @@
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	. "github.com/tokentransfer/check"
+)
+
+type EncryptedSuite struct{}
+
+func Test_Encrypted(t *testing.T) {
+	s := Suite(&EncryptedSuite{})
+	TestingRun(t, s)
+}
+
+func newEncrypted(c *C, encryptKeys bool) (*MemoryService, *EncryptedService) {
+	mem := &MemoryService{}
+	service := &EncryptedService{
+		Service:     mem,
+		Keys:        [][]byte{bytes.Repeat([]byte{1}, 32)},
+		EncryptKeys: encryptKeys,
+	}
+	err := service.Init(nil)
+	c.Assert(err, IsNil)
+	return mem, service
+}
+
+func (suite *EncryptedSuite) TestPutAndGet(c *C) {
+	mem, service := newEncrypted(c, true)
+
+	err := service.PutData([]byte("key"), []byte("value"))
+	c.Assert(err, IsNil)
+	c.Assert(mem.HasData([]byte("key")), Equals, false)
+
+	value, err := service.GetData([]byte("key"))
+	c.Assert(err, IsNil)
+	c.Assert(string(value), Equals, "value")
+	c.Assert(service.HasData([]byte("key")), Equals, true)
+
+	err = mem.ListData(func(key []byte, value []byte) error {
+		c.Assert(bytes.Contains(key, []byte("key")), Equals, false)
+		c.Assert(bytes.Contains(value, []byte("value")), Equals, false)
+		return nil
+	})
+	c.Assert(err, IsNil)
+
+	err = service.RemoveData([]byte("key"))
+	c.Assert(err, IsNil)
+	value, err = service.GetData([]byte("key"))
+	c.Assert(err, IsNil)
+	c.Assert(value, IsNil)
+}
+
+func (suite *EncryptedSuite) TestRotate(c *C) {
+	mem, service := newEncrypted(c, true)
+
+	err := service.PutDatas([][]byte{[]byte("a"), []byte("b")}, [][]byte{[]byte("1"), []byte("2")})
+	c.Assert(err, IsNil)
+
+	err = service.Rotate(bytes.Repeat([]byte{2}, 32))
+	c.Assert(err, IsNil)
+	value, err := service.GetData([]byte("a"))
+	c.Assert(err, IsNil)
+	c.Assert(string(value), Equals, "1")
+
+	err = service.PutData([]byte("a"), []byte("3"))
+	c.Assert(err, IsNil)
+	err = service.ReEncrypt()
+	c.Assert(err, IsNil)
+
+	count := 0
+	err = mem.ListData(func(key []byte, value []byte) error {
+		c.Assert(key[0], Equals, byte(1))
+		c.Assert(value[0], Equals, byte(1))
+		count++
+		return nil
+	})
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, 2)
+
+	err = service.Retire(1)
+	c.Assert(err, NotNil)
+	err = service.Retire(0)
+	c.Assert(err, IsNil)
+	values, err := service.GetDatas([][]byte{[]byte("a"), []byte("b")})
+	c.Assert(err, IsNil)
+	c.Assert(string(values[0]), Equals, "3")
+	c.Assert(string(values[1]), Equals, "2")
+}
+
+func (suite *EncryptedSuite) TestReEncryptBatches(c *C) {
+	mem, service := newEncrypted(c, true)
+	l := migrateBatch + 10
+	keys := make([][]byte, l)
+	values := make([][]byte, l)
+	for i := 0; i < l; i++ {
+		keys[i] = []byte(fmt.Sprintf("key%d", i))
+		values[i] = []byte(fmt.Sprintf("value%d", i))
+	}
+	err := service.PutDatas(keys, values)
+	c.Assert(err, IsNil)
+
+	err = service.Rotate(bytes.Repeat([]byte{2}, 32))
+	c.Assert(err, IsNil)
+	err = service.ReEncrypt()
+	c.Assert(err, IsNil)
+	err = service.Retire(0)
+	c.Assert(err, IsNil)
+
+	count := 0
+	err = mem.ListData(func(key []byte, value []byte) error {
+		c.Assert(key[0], Equals, byte(1))
+		count++
+		return nil
+	})
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, l)
+	read, err := service.GetDatas(keys)
+	c.Assert(err, IsNil)
+	c.Assert(read, DeepEquals, values)
+}
+
+func (suite *EncryptedSuite) TestMigrate(c *C) {
+	plain := &MemoryService{}
+	err := plain.Init(nil)
+	c.Assert(err, IsNil)
+	err = plain.PutData([]byte("key"), []byte("value"))
+	c.Assert(err, IsNil)
+
+	_, service := newEncrypted(c, false)
+	err = Migrate(plain, service)
+	c.Assert(err, IsNil)
+
+	value, err := service.GetData([]byte("key"))
+	c.Assert(err, IsNil)
+	c.Assert(string(value), Equals, "value")
+}
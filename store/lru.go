@@ -0,0 +1,107 @@
+package store
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+)
+
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+	Size   int
+}
+
+type entry struct {
+	key   string
+	value interface{}
+}
+
+// Cache is a thread safe LRU cache holding at most size entries.
+type Cache struct {
+	size   int
+	hits   uint64
+	misses uint64
+
+	items  map[string]*list.Element
+	order  *list.List
+	locker *sync.Mutex
+}
+
+func NewCache(size int) *Cache {
+	return &Cache{
+		size:   size,
+		items:  make(map[string]*list.Element),
+		order:  list.New(),
+		locker: &sync.Mutex{},
+	}
+}
+
+func (c *Cache) Get(key string) (interface{}, bool) {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		atomic.AddUint64(&c.misses, 1)
+		return nil, false
+	}
+	atomic.AddUint64(&c.hits, 1)
+	c.order.MoveToFront(e)
+	return e.Value.(*entry).value, true
+}
+
+func (c *Cache) Add(key string, value interface{}) {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	if c.size <= 0 {
+		return
+	}
+	e, ok := c.items[key]
+	if ok {
+		e.Value.(*entry).value = value
+		c.order.MoveToFront(e)
+		return
+	}
+	c.items[key] = c.order.PushFront(&entry{key: key, value: value})
+	for c.order.Len() > c.size {
+		last := c.order.Back()
+		c.order.Remove(last)
+		delete(c.items, last.Value.(*entry).key)
+	}
+}
+
+func (c *Cache) Remove(key string) {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	e, ok := c.items[key]
+	if ok {
+		c.order.Remove(e)
+		delete(c.items, key)
+	}
+}
+
+func (c *Cache) Purge() {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	c.items = make(map[string]*list.Element)
+	c.order.Init()
+}
+
+func (c *Cache) Len() int {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	return c.order.Len()
+}
+
+func (c *Cache) Stats() CacheStats {
+	return CacheStats{
+		Hits:   atomic.LoadUint64(&c.hits),
+		Misses: atomic.LoadUint64(&c.misses),
+		Size:   c.Len(),
+	}
+}
@@ -112,11 +112,15 @@ func (service *MemoryService) RemoveData(key []byte) error {
 func (service *MemoryService) ListData(each func(key []byte, value []byte) error) error {
 	db := service.db
 
+	var err error
 	db.Range(func(k, v interface{}) bool {
 		var key, value []byte
 
 		if k != nil {
-			key = k.([]byte)
+			key, err = hex.DecodeString(k.(string))
+			if err != nil {
+				return false
+			}
 		} else {
 			key = nil
 		}
@@ -127,11 +131,11 @@ func (service *MemoryService) ListData(each func(key []byte, value []byte) error
 			value = nil
 		}
 
-		err := each(key, value)
+		err = each(key, value)
 		if err != nil {
 			return false
 		}
 		return true
 	})
-	return nil
+	return err
 }